@@ -0,0 +1,283 @@
+package cloudconnexa
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// accessGraphWildcard is the entity ID of nodes standing for every entity of a type.
+const accessGraphWildcard = "*"
+
+// AccessGraphNode is a source or destination of access.
+type AccessGraphNode struct {
+	// ID is unique within the graph: the item type and entity ID joined by a colon.
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// EntityID is the user group, network or host ID, or "*" for a node standing for all of them.
+	EntityID string `json:"entityId"`
+	Name     string `json:"name"`
+}
+
+// AccessGraphEdge is access from one node to another granted by an access group.
+type AccessGraphEdge struct {
+	From            string `json:"from"`
+	To              string `json:"to"`
+	AccessGroupID   string `json:"accessGroupId"`
+	AccessGroupName string `json:"accessGroupName"`
+	// Services lists the names of the destination services covered, or is empty if all are.
+	Services []string `json:"services,omitempty"`
+}
+
+// AccessGraph is a directed graph of who can reach what.
+type AccessGraph struct {
+	Nodes []AccessGraphNode `json:"nodes"`
+	Edges []AccessGraphEdge `json:"edges"`
+}
+
+// AccessGraphOptions filters the graph built by BuildAccessGraph.
+type AccessGraphOptions struct {
+	// Subject keeps only edges from the entity with this ID or name, including
+	// edges from nodes that stand for all entities of its type.
+	Subject string
+	// Target keeps only edges to the entity with this ID or name, including
+	// edges to nodes that stand for all entities of its type.
+	Target string
+}
+
+// BuildAccessGraph turns the access groups of m into a graph whose nodes are the
+// covered user groups, networks and hosts and whose edges are labelled with the
+// access group granting them.
+func BuildAccessGraph(m *AccessModel, opts AccessGraphOptions) *AccessGraph {
+	subjects := m.matchEntities(opts.Subject)
+	targets := m.matchEntities(opts.Target)
+
+	nodes := map[string]AccessGraphNode{}
+	edges := map[[3]string]*AccessGraphEdge{}
+	allServices := map[[3]string]bool{}
+	var edgeOrder [][3]string
+
+	for _, group := range m.AccessGroups {
+		var from []AccessGraphNode
+		for _, item := range group.Source {
+			for _, entity := range m.graphEntities(item) {
+				if matchesGraphFilter(entity.node, subjects, opts.Subject) {
+					from = append(from, entity.node)
+				}
+			}
+		}
+		var to []accessGraphEntity
+		for _, item := range group.Destination {
+			for _, entity := range m.graphEntities(item) {
+				if matchesGraphFilter(entity.node, targets, opts.Target) {
+					to = append(to, entity)
+				}
+			}
+		}
+
+		for _, source := range from {
+			for _, destination := range to {
+				key := [3]string{source.ID, destination.node.ID, group.ID}
+				edge, ok := edges[key]
+				if !ok {
+					nodes[source.ID] = source
+					nodes[destination.node.ID] = destination.node
+					edge = &AccessGraphEdge{From: source.ID, To: destination.node.ID, AccessGroupID: group.ID, AccessGroupName: group.Name}
+					edges[key] = edge
+					edgeOrder = append(edgeOrder, key)
+				}
+				if destination.services == nil {
+					allServices[key] = true
+				}
+				for _, service := range destination.services {
+					edge.Services = appendUnique(edge.Services, service)
+				}
+			}
+		}
+	}
+
+	graph := &AccessGraph{Nodes: make([]AccessGraphNode, 0, len(nodes)), Edges: make([]AccessGraphEdge, 0, len(edgeOrder))}
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	for _, key := range edgeOrder {
+		edge := *edges[key]
+		if allServices[key] {
+			edge.Services = nil
+		}
+		sort.Strings(edge.Services)
+		graph.Edges = append(graph.Edges, edge)
+	}
+	return graph
+}
+
+// Graph loads the access model and builds its access graph.
+func (c *AccessGroupsService) Graph(opts AccessGraphOptions) (*AccessGraph, error) {
+	model, err := c.LoadModel()
+	if err != nil {
+		return nil, err
+	}
+	return BuildAccessGraph(model, opts), nil
+}
+
+type accessGraphEntity struct {
+	node AccessGraphNode
+	// services names the covered services, or is nil if all are covered.
+	services []string
+}
+
+// graphEntities returns the nodes covered by item.
+func (m *AccessModel) graphEntities(item AccessItem) []accessGraphEntity {
+	coverage := m.Coverage(item)
+	if coverage.All {
+		return []accessGraphEntity{{node: AccessGraphNode{
+			ID:       item.Type + ":" + accessGraphWildcard,
+			Type:     item.Type,
+			EntityID: accessGraphWildcard,
+			Name:     "All " + accessItemTypePlural(item.Type),
+		}}}
+	}
+
+	ids := make([]string, 0, len(coverage.Entities))
+	for id := range coverage.Entities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	entities := make([]accessGraphEntity, 0, len(ids))
+	for _, id := range ids {
+		entity := accessGraphEntity{node: AccessGraphNode{ID: item.Type + ":" + id, Type: item.Type, EntityID: id, Name: m.Name(item.Type, id)}}
+		for _, service := range coverage.Entities[id] {
+			entity.services = append(entity.services, m.Name(item.Type, service))
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+// matchEntities returns the graph node IDs of the entities whose ID or name is ref.
+func (m *AccessModel) matchEntities(ref string) map[string]bool {
+	matches := map[string]bool{}
+	if ref == "" {
+		return matches
+	}
+	for itemType, names := range m.idx().names {
+		for id, name := range names {
+			if id == ref || name == ref {
+				matches[itemType+":"+id] = true
+			}
+		}
+	}
+	return matches
+}
+
+func matchesGraphFilter(node AccessGraphNode, matches map[string]bool, ref string) bool {
+	if ref == "" || matches[node.ID] || node.EntityID == ref || node.Name == ref {
+		return true
+	}
+	if node.EntityID != accessGraphWildcard {
+		return false
+	}
+	for id := range matches {
+		if strings.HasPrefix(id, node.Type+":") {
+			return true
+		}
+	}
+	return false
+}
+
+func accessItemTypePlural(itemType string) string {
+	switch itemType {
+	case AccessItemTypeUserGroup:
+		return "user groups"
+	case AccessItemTypeNetwork:
+		return "networks"
+	case AccessItemTypeHost:
+		return "hosts"
+	default:
+		return strings.ToLower(itemType)
+	}
+}
+
+func (e AccessGraphEdge) label() string {
+	if len(e.Services) == 0 {
+		return e.AccessGroupName
+	}
+	return e.AccessGroupName + " (" + strings.Join(e.Services, ", ") + ")"
+}
+
+// Adjacency returns, for every node ID with outgoing edges, the sorted IDs of the nodes it can reach.
+func (g *AccessGraph) Adjacency() map[string][]string {
+	adjacency := map[string][]string{}
+	for _, edge := range g.Edges {
+		adjacency[edge.From] = appendUnique(adjacency[edge.From], edge.To)
+	}
+	for _, targets := range adjacency {
+		sort.Strings(targets)
+	}
+	return adjacency
+}
+
+// WriteJSON writes the graph as JSON with nodes, edges and an adjacency map.
+func (g *AccessGraph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		*AccessGraph
+		Adjacency map[string][]string `json:"adjacency"`
+	}{g, g.Adjacency()})
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.
+func (g *AccessGraph) WriteDOT(w io.Writer) error {
+	shapes := map[string]string{
+		AccessItemTypeUserGroup: "ellipse",
+		AccessItemTypeNetwork:   "box",
+		AccessItemTypeHost:      "component",
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph access {")
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	for _, node := range g.Nodes {
+		shape := shapes[node.Type]
+		if shape == "" {
+			shape = "ellipse"
+		}
+		fmt.Fprintf(bw, "  %s [label=%s, shape=%s];\n", dotQuote(node.ID), dotQuote(node.Name), shape)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(bw, "  %s -> %s [label=%s];\n", dotQuote(edge.From), dotQuote(edge.To), dotQuote(edge.label()))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart.
+func (g *AccessGraph) WriteMermaid(w io.Writer) error {
+	ids := make(map[string]string, len(g.Nodes))
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "flowchart LR")
+	for i, node := range g.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+		open, closing := "[", "]"
+		if node.Type == AccessItemTypeUserGroup {
+			open, closing = "([", "])"
+		}
+		fmt.Fprintf(bw, "  %s%s%s%s\n", ids[node.ID], open, mermaidQuote(node.Name), closing)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(bw, "  %s -->|%s| %s\n", ids[edge.From], mermaidQuote(edge.label()), ids[edge.To])
+	}
+	return bw.Flush()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s) + `"`
+}
@@ -0,0 +1,80 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildAccessGraph(t *testing.T) {
+	graph := BuildAccessGraph(newTestAccessModel(), AccessGraphOptions{})
+
+	if len(graph.Nodes) != 6 {
+		t.Errorf("Expected 6 nodes, got %+v", graph.Nodes)
+	}
+	if len(graph.Edges) != 3 {
+		t.Fatalf("Expected 3 edges, got %+v", graph.Edges)
+	}
+	edge := graph.Edges[0]
+	if edge.From != "USER_GROUP:ug-eng" || edge.To != "HOST:host-db" || edge.AccessGroupName != "Engineering to Postgres" || edge.Services[0] != "postgres" {
+		t.Errorf("Unexpected edge %+v", edge)
+	}
+	if graph.Edges[1].From != "USER_GROUP:*" || len(graph.Edges[1].Services) != 0 {
+		t.Errorf("Expected wildcard edge covering all services, got %+v", graph.Edges[1])
+	}
+}
+
+func TestBuildAccessGraph_Filters(t *testing.T) {
+	model := newTestAccessModel()
+
+	graph := BuildAccessGraph(model, AccessGraphOptions{Subject: "Sales"})
+	if len(graph.Edges) != 1 || graph.Edges[0].AccessGroupID != "ag-web" {
+		t.Errorf("Expected only the all-user-groups edge for Sales, got %+v", graph.Edges)
+	}
+
+	graph = BuildAccessGraph(model, AccessGraphOptions{Target: "db-01"})
+	var groups []string
+	for _, edge := range graph.Edges {
+		groups = append(groups, edge.AccessGroupID)
+	}
+	if strings.Join(groups, ",") != "ag-db,ag-office" {
+		t.Errorf("Expected edges reaching db-01, got %v", groups)
+	}
+}
+
+func TestAccessGraph_Writers(t *testing.T) {
+	graph := BuildAccessGraph(newTestAccessModel(), AccessGraphOptions{Subject: "Engineering"})
+
+	var dot bytes.Buffer
+	if err := graph.WriteDOT(&dot); err != nil {
+		t.Fatalf("WriteDOT failed: %v", err)
+	}
+	if !strings.Contains(dot.String(), `"USER_GROUP:ug-eng" -> "HOST:host-db" [label="Engineering to Postgres (postgres)"];`) {
+		t.Errorf("Unexpected DOT output:\n%s", dot.String())
+	}
+
+	var mermaid bytes.Buffer
+	if err := graph.WriteMermaid(&mermaid); err != nil {
+		t.Fatalf("WriteMermaid failed: %v", err)
+	}
+	if !strings.HasPrefix(mermaid.String(), "flowchart LR\n") || !strings.Contains(mermaid.String(), `-->|"Engineering to Postgres (postgres)"|`) {
+		t.Errorf("Unexpected Mermaid output:\n%s", mermaid.String())
+	}
+
+	var out bytes.Buffer
+	if err := graph.WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var decoded struct {
+		Nodes     []AccessGraphNode   `json:"nodes"`
+		Edges     []AccessGraphEdge   `json:"edges"`
+		Adjacency map[string][]string `json:"adjacency"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(decoded.Edges) != 2 || len(decoded.Adjacency["USER_GROUP:ug-eng"]) != 1 {
+		t.Errorf("Unexpected JSON model %+v", decoded)
+	}
+}
@@ -11,6 +11,11 @@ import (
 	"net/http"
 )
 
+var (
+	// ErrAccessGroupNotFound is returned when a access group cannot be found
+	ErrAccessGroupNotFound = errors.New("access group not found")
+)
+
 // AccessGroup represents a group of access rules that define network access permissions.
 // It contains source and destination rules that determine what resources can access each other.
 type AccessGroup struct {
@@ -122,17 +127,25 @@ func (c *AccessGroupsService) Get(id string) (*AccessGroup, error) {
 // name: The name of the access group to retrieve
 // Returns the access group and any error that occurred
 func (c *AccessGroupsService) GetByName(name string) (*AccessGroup, error) {
-	items, err := c.List()
+	items, err := findByKey(c.client, "access-groups", c.List, c.pages, nameKey[AccessGroup], name, 1)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, item := range items {
-		if item.Name == name {
-			return &item, nil
-		}
+	if len(items) == 0 {
+		return nil, ErrAccessGroupNotFound
 	}
-	return nil, errors.New("access group not found")
+	return &items[0], nil
+}
+
+// Query returns the access groups matching all preds, e.g. Query(NamePrefix[AccessGroup]("prod-")).
+func (c *AccessGroupsService) Query(preds ...Predicate[AccessGroup]) ([]AccessGroup, error) {
+	return queryCollection(c.client, "access-groups", c.List, c.pages, nameKey[AccessGroup], preds)
+}
+
+// pages adapts GetAccessGroupsByPage to a pageLister.
+func (c *AccessGroupsService) pages(page, size int) ([]AccessGroup, int, error) {
+	response, err := c.GetAccessGroupsByPage(page, size)
+	return response.Content, response.TotalPages, err
 }
 
 // Create creates a new access group in the CloudConnexa API.
@@ -0,0 +1,329 @@
+package cloudconnexa
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AccessLintRule identifies a check performed by LintAccessGroups.
+type AccessLintRule string
+
+const (
+	// AccessLintAllToAll flags groups granting every source access to every destination.
+	AccessLintAllToAll AccessLintRule = "all_to_all"
+	// AccessLintDanglingReference flags parents or children referencing IDs that do not exist.
+	AccessLintDanglingReference AccessLintRule = "dangling_reference"
+	// AccessLintEmptySource flags groups without sources.
+	AccessLintEmptySource AccessLintRule = "empty_source"
+	// AccessLintEmptyDestination flags groups without destinations.
+	AccessLintEmptyDestination AccessLintRule = "empty_destination"
+	// AccessLintDuplicate flags groups granting exactly the same access as an earlier group.
+	AccessLintDuplicate AccessLintRule = "duplicate"
+	// AccessLintShadowed flags groups whose access is entirely granted by another group.
+	AccessLintShadowed AccessLintRule = "shadowed"
+	// AccessLintSuspendedUserGroup flags groups referencing user groups whose members are all suspended.
+	AccessLintSuspendedUserGroup AccessLintRule = "suspended_user_group"
+)
+
+var accessLintSeverities = map[AccessLintRule]Severity{
+	AccessLintAllToAll:           SeverityCritical,
+	AccessLintDanglingReference:  SeverityHigh,
+	AccessLintEmptySource:        SeverityMedium,
+	AccessLintEmptyDestination:   SeverityMedium,
+	AccessLintDuplicate:          SeverityMedium,
+	AccessLintShadowed:           SeverityLow,
+	AccessLintSuspendedUserGroup: SeverityLow,
+}
+
+// AccessLintOptions configures LintAccessGroups.
+type AccessLintOptions struct {
+	// AccessGroups, if not nil, are linted instead of the access groups of the
+	// model, e.g. definitions managed as code that have not been applied yet.
+	AccessGroups []AccessGroup
+	// Users are used to detect suspended user groups. The rule is skipped if nil.
+	Users []User
+	// Disabled lists rules that are not checked.
+	Disabled []AccessLintRule
+}
+
+// AccessLintFinding is a problem found in an access group.
+type AccessLintFinding struct {
+	Rule            AccessLintRule `json:"rule"`
+	Severity        Severity       `json:"severity"`
+	AccessGroupID   string         `json:"accessGroupId"`
+	AccessGroupName string         `json:"accessGroupName"`
+	Message         string         `json:"message"`
+	// RelatedAccessGroupID is the group duplicating or shadowing this one.
+	RelatedAccessGroupID string `json:"relatedAccessGroupId,omitempty"`
+}
+
+// AccessLintReport is the result of linting access groups.
+type AccessLintReport struct {
+	CheckedAt    time.Time           `json:"checkedAt"`
+	AccessGroups int                 `json:"accessGroups"`
+	Findings     []AccessLintFinding `json:"findings"`
+}
+
+// HasFindings reports whether the report contains a finding of at least severity min.
+// It is intended for deciding the exit status of a CI job.
+func (r *AccessLintReport) HasFindings(minSeverity Severity) bool {
+	for _, f := range r.Findings {
+		if f.Severity.AtLeast(minSeverity) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint loads the access model and users and lints the live access groups.
+func (c *AccessGroupsService) Lint(opts AccessLintOptions) (*AccessLintReport, error) {
+	model, err := c.LoadModel()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Users == nil {
+		if opts.Users, err = c.client.Users.List(); err != nil {
+			return nil, err
+		}
+	}
+	return LintAccessGroups(model, opts), nil
+}
+
+// accessGroupCoverage is the merged coverage of a group's sources and destinations, by item type.
+type accessGroupCoverage struct {
+	source      map[string]AccessCoverage
+	destination map[string]AccessCoverage
+}
+
+// LintAccessGroups checks access groups for overly broad, dead or redundant
+// rules. References are resolved against the user groups, networks, hosts and
+// IP services of m.
+func LintAccessGroups(m *AccessModel, opts AccessLintOptions) *AccessLintReport {
+	groups := opts.AccessGroups
+	if groups == nil {
+		groups = m.AccessGroups
+	}
+	report := &AccessLintReport{CheckedAt: time.Now().UTC(), AccessGroups: len(groups), Findings: []AccessLintFinding{}}
+	add := func(rule AccessLintRule, group AccessGroup, related, format string, args ...any) {
+		if slices.Contains(opts.Disabled, rule) {
+			return
+		}
+		report.Findings = append(report.Findings, AccessLintFinding{
+			Rule:                 rule,
+			Severity:             accessLintSeverities[rule],
+			AccessGroupID:        group.ID,
+			AccessGroupName:      group.Name,
+			Message:              fmt.Sprintf(format, args...),
+			RelatedAccessGroupID: related,
+		})
+	}
+
+	var suspended map[string]bool
+	if opts.Users != nil {
+		suspended = suspendedUserGroups(opts.Users)
+	}
+
+	coverages := make([]accessGroupCoverage, len(groups))
+	for i, group := range groups {
+		coverages[i] = accessGroupCoverage{source: m.mergedCoverage(group.Source), destination: m.mergedCoverage(group.Destination)}
+
+		if len(group.Source) == 0 {
+			add(AccessLintEmptySource, group, "", "access group has no sources")
+		}
+		if len(group.Destination) == 0 {
+			add(AccessLintEmptyDestination, group, "", "access group has no destinations")
+		}
+		if hasAllCovered(group.Source) && hasAllCovered(group.Destination) {
+			add(AccessLintAllToAll, group, "", "access group grants all %s access to all %s",
+				accessItemTypesPlural(group.Source), accessItemTypesPlural(group.Destination))
+		}
+		for _, item := range append(slices.Clone(group.Source), group.Destination...) {
+			for _, problem := range m.danglingReferences(item) {
+				add(AccessLintDanglingReference, group, "", "%s", problem)
+			}
+		}
+		for _, id := range referencedUserGroups(group) {
+			if suspended[id] {
+				add(AccessLintSuspendedUserGroup, group, "", "user group %s only has suspended members", m.Name(AccessItemTypeUserGroup, id))
+			}
+		}
+	}
+
+	for i, group := range groups {
+		if len(group.Source) == 0 || len(group.Destination) == 0 {
+			continue
+		}
+		for j, other := range groups {
+			if i == j || len(other.Source) == 0 || len(other.Destination) == 0 || !coverages[j].covers(coverages[i]) {
+				continue
+			}
+			if coverages[i].covers(coverages[j]) {
+				if j < i {
+					add(AccessLintDuplicate, group, other.ID, "access group grants the same access as %q", other.Name)
+					break
+				}
+				continue
+			}
+			add(AccessLintShadowed, group, other.ID, "access group is fully shadowed by %q", other.Name)
+			break
+		}
+	}
+	return report
+}
+
+// mergedCoverage unions the coverage of items per item type.
+func (m *AccessModel) mergedCoverage(items []AccessItem) map[string]AccessCoverage {
+	merged := map[string]AccessCoverage{}
+	for _, item := range items {
+		coverage := m.Coverage(item)
+		current, ok := merged[item.Type]
+		if !ok {
+			current = AccessCoverage{Entities: map[string][]string{}}
+		}
+		if coverage.All {
+			current.All = true
+		}
+		for id, services := range coverage.Entities {
+			existing, seen := current.Entities[id]
+			switch {
+			case seen && existing == nil:
+			case services == nil:
+				current.Entities[id] = nil
+			default:
+				for _, s := range services {
+					existing = appendUnique(existing, s)
+				}
+				current.Entities[id] = existing
+			}
+		}
+		merged[item.Type] = current
+	}
+	return merged
+}
+
+// covers reports whether c grants at least the access granted by other.
+func (c accessGroupCoverage) covers(other accessGroupCoverage) bool {
+	return coverageIncludes(c.source, other.source) && coverageIncludes(c.destination, other.destination)
+}
+
+func coverageIncludes(outer, inner map[string]AccessCoverage) bool {
+	for itemType, in := range inner {
+		out, ok := outer[itemType]
+		if !ok {
+			if in.All || len(in.Entities) > 0 {
+				return false
+			}
+			continue
+		}
+		if out.All {
+			continue
+		}
+		if in.All {
+			return false
+		}
+		for id, services := range in.Entities {
+			outServices, ok := out.Entities[id]
+			if !ok {
+				return false
+			}
+			if outServices == nil {
+				continue
+			}
+			if services == nil {
+				return false
+			}
+			for _, s := range services {
+				if !slices.Contains(outServices, s) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// danglingReferences describes the parent and children of item that do not exist.
+func (m *AccessModel) danglingReferences(item AccessItem) []string {
+	index := m.idx()
+	names, ok := index.names[item.Type]
+	if !ok {
+		return []string{fmt.Sprintf("unknown access item type %q", item.Type)}
+	}
+	kind := strings.ToLower(strings.ReplaceAll(item.Type, "_", " "))
+	var problems []string
+	if item.Parent != "" {
+		if _, ok := names[item.Parent]; !ok {
+			problems = append(problems, fmt.Sprintf("%s %s does not exist", kind, item.Parent))
+		}
+	}
+	for _, child := range item.Children {
+		if _, ok := names[child]; ok {
+			continue
+		}
+		if s, ok := index.services[child]; ok && item.Type != AccessItemTypeUserGroup && s.parentType == item.Type &&
+			(item.Parent == "" || s.parentID == item.Parent) {
+			continue
+		}
+		if item.Parent != "" && item.Type != AccessItemTypeUserGroup {
+			problems = append(problems, fmt.Sprintf("service %s of %s %s does not exist", child, kind, item.Parent))
+		} else {
+			problems = append(problems, fmt.Sprintf("%s %s does not exist", kind, child))
+		}
+	}
+	return problems
+}
+
+// suspendedUserGroups returns the IDs of user groups with members, all of whom are suspended.
+func suspendedUserGroups(users []User) map[string]bool {
+	suspended := map[string]bool{}
+	for _, u := range users {
+		for _, id := range append([]string{u.GroupID}, u.SecondaryGroupIDs...) {
+			if id == "" {
+				continue
+			}
+			allSuspended, seen := suspended[id]
+			suspended[id] = (allSuspended || !seen) && u.Status == UserStatusSuspended
+		}
+	}
+	return suspended
+}
+
+// referencedUserGroups returns the sorted IDs of user groups referenced by group.
+func referencedUserGroups(group AccessGroup) []string {
+	var ids []string
+	for _, item := range append(slices.Clone(group.Source), group.Destination...) {
+		if item.Type != AccessItemTypeUserGroup {
+			continue
+		}
+		if item.Parent != "" {
+			ids = appendUnique(ids, item.Parent)
+		}
+		for _, child := range item.Children {
+			ids = appendUnique(ids, child)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func hasAllCovered(items []AccessItem) bool {
+	for _, item := range items {
+		if item.AllCovered && item.Parent == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func accessItemTypesPlural(items []AccessItem) string {
+	var types []string
+	for _, item := range items {
+		if item.AllCovered && item.Parent == "" {
+			types = appendUnique(types, accessItemTypePlural(item.Type))
+		}
+	}
+	return strings.Join(types, " and ")
+}
@@ -0,0 +1,113 @@
+package cloudconnexa
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLintAccessGroups(t *testing.T) {
+	model := newTestAccessModel()
+	groups := append(model.AccessGroups,
+		AccessGroup{
+			ID:          "ag-any",
+			Name:        "Anything goes",
+			Source:      []AccessItem{{Type: AccessItemTypeUserGroup, AllCovered: true}},
+			Destination: []AccessItem{{Type: AccessItemTypeNetwork, AllCovered: true}},
+		},
+		AccessGroup{
+			ID:          "ag-db-copy",
+			Name:        "Engineering to Postgres (copy)",
+			Source:      []AccessItem{{Type: AccessItemTypeUserGroup, Children: []string{"ug-eng"}}},
+			Destination: []AccessItem{{Type: AccessItemTypeHost, Parent: "host-db", Children: []string{"svc-pg"}}},
+		},
+		AccessGroup{
+			ID:          "ag-sales-web",
+			Name:        "Sales to web",
+			Source:      []AccessItem{{Type: AccessItemTypeUserGroup, Children: []string{"ug-sales"}}},
+			Destination: []AccessItem{{Type: AccessItemTypeHost, Parent: "host-web"}},
+		},
+		AccessGroup{
+			ID:          "ag-stale",
+			Name:        "Stale",
+			Source:      []AccessItem{{Type: AccessItemTypeUserGroup, Children: []string{"ug-gone"}}},
+			Destination: []AccessItem{{Type: AccessItemTypeHost, Parent: "host-db", Children: []string{"svc-gone"}}},
+		},
+		AccessGroup{ID: "ag-empty", Name: "Empty"},
+	)
+	users := []User{
+		{ID: "u1", GroupID: "ug-sales", Status: UserStatusSuspended},
+		{ID: "u2", GroupID: "ug-eng", Status: UserStatusActive},
+		{ID: "u3", GroupID: "ug-eng", Status: UserStatusSuspended},
+	}
+
+	report := LintAccessGroups(model, AccessLintOptions{AccessGroups: groups, Users: users})
+
+	var got []string
+	for _, f := range report.Findings {
+		got = append(got, f.AccessGroupID+":"+string(f.Rule)+":"+f.RelatedAccessGroupID)
+	}
+	want := []string{
+		"ag-any:all_to_all:",
+		"ag-sales-web:suspended_user_group:",
+		"ag-stale:dangling_reference:",
+		"ag-stale:dangling_reference:",
+		"ag-empty:empty_source:",
+		"ag-empty:empty_destination:",
+		"ag-db-copy:duplicate:ag-db",
+		"ag-sales-web:shadowed:ag-web",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected findings\n%v\ngot\n%v", want, got)
+	}
+	if report.AccessGroups != len(groups) || !report.HasFindings(SeverityCritical) {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if msg := report.Findings[3].Message; msg != "service svc-gone of host host-db does not exist" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+}
+
+func TestLintAccessGroups_Disabled(t *testing.T) {
+	model := newTestAccessModel()
+	groups := append(model.AccessGroups, AccessGroup{ID: "ag-empty"})
+
+	report := LintAccessGroups(model, AccessLintOptions{
+		AccessGroups: groups,
+		Disabled:     []AccessLintRule{AccessLintEmptySource, AccessLintEmptyDestination},
+	})
+	if len(report.Findings) != 0 || report.HasFindings(SeverityLow) {
+		t.Errorf("Expected no findings, got %+v", report.Findings)
+	}
+}
+
+func TestAccessGroupsService_Lint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var content any = []any{}
+		switch r.URL.Path {
+		case "/api/v1/access-groups":
+			content = []AccessGroup{{ID: "ag-1", Source: []AccessItem{{Type: AccessItemTypeUserGroup, Children: []string{"ug-1"}}}}}
+		case "/api/v1/user-groups":
+			content = []UserGroup{{ID: "ug-1", Name: "Engineering"}}
+		case "/api/v1/users":
+			content = []User{{ID: "u1", GroupID: "ug-1", Status: UserStatusSuspended}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"content": content, "totalPages": 1})
+	}))
+	defer server.Close()
+
+	client := createTestClientWithServices(server)
+	report, err := client.AccessGroups.Lint(AccessLintOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var rules []string
+	for _, f := range report.Findings {
+		rules = append(rules, string(f.Rule))
+	}
+	if strings.Join(rules, ",") != "empty_destination,suspended_user_group" {
+		t.Errorf("Unexpected findings %v", rules)
+	}
+}
@@ -0,0 +1,362 @@
+package cloudconnexa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// AccessItemTypeUserGroup is an access item covering user groups.
+	AccessItemTypeUserGroup = "USER_GROUP"
+	// AccessItemTypeNetwork is an access item covering networks and their IP services.
+	AccessItemTypeNetwork = "NETWORK"
+	// AccessItemTypeHost is an access item covering hosts and their IP services.
+	AccessItemTypeHost = "HOST"
+)
+
+// ErrAccessEntityNotFound is returned when an access query names an unknown entity.
+var ErrAccessEntityNotFound = errors.New("access entity not found")
+
+// AccessModel is a local snapshot of the resources that determine who can reach what.
+// It can be loaded with AccessGroupsService.LoadModel or assembled by hand.
+// The model must not be modified after its first query.
+type AccessModel struct {
+	AccessGroups      []AccessGroup              `json:"accessGroups"`
+	UserGroups        []UserGroup                `json:"userGroups"`
+	Networks          []Network                  `json:"networks"`
+	Hosts             []Host                     `json:"hosts"`
+	NetworkIPServices []NetworkIPServiceResponse `json:"networkIpServices"`
+	HostIPServices    []HostIPServiceResponse    `json:"hostIpServices"`
+
+	once  sync.Once
+	index *accessIndex
+}
+
+// accessService locates an IP service below its network or host.
+type accessService struct {
+	name       string
+	parentType string
+	parentID   string
+}
+
+type accessIndex struct {
+	names    map[string]map[string]string // item type -> ID -> name
+	services map[string]accessService     // service ID -> service
+}
+
+// LoadModel retrieves access groups, user groups, networks, hosts and IP services
+// concurrently and returns them as an AccessModel.
+func (c *AccessGroupsService) LoadModel() (*AccessModel, error) {
+	m := &AccessModel{}
+	loaders := []func() error{
+		func() (err error) { m.AccessGroups, err = c.List(); return err },
+		func() (err error) { m.UserGroups, err = c.client.UserGroups.List(); return err },
+		func() (err error) { m.Networks, err = c.client.Networks.List(); return err },
+		func() (err error) { m.Hosts, err = c.client.Hosts.List(); return err },
+		func() (err error) { m.NetworkIPServices, err = c.client.NetworkIPServices.List(); return err },
+		func() (err error) { m.HostIPServices, err = c.client.HostIPServices.List(); return err },
+	}
+	errs := make([]error, len(loaders))
+	_ = forEachConcurrent(context.Background(), len(loaders), defaultConcurrency, func(i int) {
+		errs[i] = loaders[i]()
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *AccessModel) idx() *accessIndex {
+	m.once.Do(func() {
+		index := &accessIndex{
+			names: map[string]map[string]string{
+				AccessItemTypeUserGroup: {},
+				AccessItemTypeNetwork:   {},
+				AccessItemTypeHost:      {},
+			},
+			services: map[string]accessService{},
+		}
+		for _, g := range m.UserGroups {
+			index.names[AccessItemTypeUserGroup][g.ID] = g.Name
+		}
+		for _, n := range m.Networks {
+			index.names[AccessItemTypeNetwork][n.ID] = n.Name
+		}
+		for _, h := range m.Hosts {
+			index.names[AccessItemTypeHost][h.ID] = h.Name
+		}
+		for _, s := range m.NetworkIPServices {
+			index.services[s.ID] = accessService{name: s.Name, parentType: AccessItemTypeNetwork, parentID: s.NetworkItemID}
+		}
+		for _, s := range m.HostIPServices {
+			index.services[s.ID] = accessService{name: s.Name, parentType: AccessItemTypeHost, parentID: s.NetworkItemID}
+		}
+		m.index = index
+	})
+	return m.index
+}
+
+// Name returns the name of the user group, network, host or IP service with the
+// given ID, or the ID itself if it is unknown.
+func (m *AccessModel) Name(itemType, id string) string {
+	index := m.idx()
+	if name, ok := index.names[itemType][id]; ok && name != "" {
+		return name
+	}
+	if s, ok := index.services[id]; ok && s.name != "" {
+		return s.name
+	}
+	return id
+}
+
+// resolve returns the ID of the entity of itemType whose ID or name is ref.
+func (m *AccessModel) resolve(itemType, ref string) (string, error) {
+	names, ok := m.idx().names[itemType]
+	if !ok {
+		return "", fmt.Errorf("unknown access item type %q", itemType)
+	}
+	if _, ok := names[ref]; ok {
+		return ref, nil
+	}
+	var matches []string
+	for id, name := range names {
+		if name == ref {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %s %q", ErrAccessEntityNotFound, strings.ToLower(strings.ReplaceAll(itemType, "_", " ")), ref)
+	case 1:
+		return matches[0], nil
+	default:
+		sort.Strings(matches)
+		return "", fmt.Errorf("ambiguous %s name %q matches %s", strings.ToLower(strings.ReplaceAll(itemType, "_", " ")), ref, strings.Join(matches, ", "))
+	}
+}
+
+// resolveService returns the ID of the IP service of the given network or host whose ID or name is ref.
+func (m *AccessModel) resolveService(parentType, parentID, ref string) (string, error) {
+	var matches []string
+	for id, s := range m.idx().services {
+		if s.parentType == parentType && s.parentID == parentID && (id == ref || s.name == ref) {
+			if id == ref {
+				return id, nil
+			}
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: service %q of %s", ErrAccessEntityNotFound, ref, m.Name(parentType, parentID))
+	case 1:
+		return matches[0], nil
+	default:
+		sort.Strings(matches)
+		return "", fmt.Errorf("ambiguous service name %q matches %s", ref, strings.Join(matches, ", "))
+	}
+}
+
+// AccessCoverage is the set of entities an AccessItem covers after resolving
+// AllCovered and parent/child relationships.
+type AccessCoverage struct {
+	// All is true if the item covers every entity of its type.
+	All bool
+	// Entities maps covered entity IDs to the IDs of the covered IP services,
+	// or to nil if all of the entity's services are covered.
+	Entities map[string][]string
+}
+
+// Covers reports whether the coverage includes the entity, restricted to service if it is not empty.
+func (c AccessCoverage) Covers(id, service string) bool {
+	if c.All {
+		return true
+	}
+	services, ok := c.Entities[id]
+	if !ok {
+		return false
+	}
+	return services == nil || service == "" || slices.Contains(services, service)
+}
+
+// Coverage resolves an access item:
+//   - AllCovered without Parent covers every entity of the item's type.
+//   - Parent covers that entity; all of its services if AllCovered is set or
+//     Children is empty, otherwise only the services listed in Children.
+//   - Without Parent, Children lists covered entities, or IP services, which
+//     cover only that service of their network or host.
+func (m *AccessModel) Coverage(item AccessItem) AccessCoverage {
+	coverage := AccessCoverage{Entities: map[string][]string{}}
+	if item.AllCovered && item.Parent == "" {
+		coverage.All = true
+		return coverage
+	}
+
+	if item.Parent != "" {
+		if item.AllCovered || len(item.Children) == 0 || item.Type == AccessItemTypeUserGroup {
+			coverage.Entities[item.Parent] = nil
+		} else {
+			coverage.Entities[item.Parent] = slices.Clone(item.Children)
+		}
+		if item.Type != AccessItemTypeUserGroup {
+			return coverage
+		}
+	}
+
+	services := m.idx().services
+	for _, child := range item.Children {
+		s, isService := services[child]
+		if !isService || item.Type == AccessItemTypeUserGroup {
+			coverage.Entities[child] = nil
+			continue
+		}
+		if existing, ok := coverage.Entities[s.parentID]; !ok || existing != nil {
+			coverage.Entities[s.parentID] = append(existing, child)
+		}
+	}
+	return coverage
+}
+
+// AccessQuery asks whether a source can reach a destination. Entities are
+// referenced by ID or name; Service optionally narrows the destination to one
+// of its IP services.
+type AccessQuery struct {
+	SourceType      string `json:"sourceType"`
+	Source          string `json:"source"`
+	DestinationType string `json:"destinationType"`
+	Destination     string `json:"destination"`
+	Service         string `json:"service,omitempty"`
+}
+
+// AccessGrant is an access group that grants the queried access.
+type AccessGrant struct {
+	AccessGroupID   string `json:"accessGroupId"`
+	AccessGroupName string `json:"accessGroupName"`
+	// Services lists the names of the destination services the group grants,
+	// or is empty if it grants the whole destination.
+	Services []string `json:"services,omitempty"`
+}
+
+// AccessDecision is the answer to an AccessQuery.
+type AccessDecision struct {
+	Allowed     bool          `json:"allowed"`
+	Grants      []AccessGrant `json:"grants"`
+	Explanation string        `json:"explanation"`
+}
+
+// CanReach evaluates query against the access groups of the model. Only access
+// groups are considered; the tenant topology setting is not.
+func (m *AccessModel) CanReach(query AccessQuery) (*AccessDecision, error) {
+	sourceID, err := m.resolve(query.SourceType, query.Source)
+	if err != nil {
+		return nil, err
+	}
+	destinationID, err := m.resolve(query.DestinationType, query.Destination)
+	if err != nil {
+		return nil, err
+	}
+	serviceID := ""
+	if query.Service != "" {
+		if query.DestinationType == AccessItemTypeUserGroup {
+			return nil, fmt.Errorf("services cannot be queried for user groups")
+		}
+		if serviceID, err = m.resolveService(query.DestinationType, destinationID, query.Service); err != nil {
+			return nil, err
+		}
+	}
+
+	decision := &AccessDecision{Grants: []AccessGrant{}}
+	for _, group := range m.AccessGroups {
+		if !m.itemsCover(group.Source, query.SourceType, sourceID, "") {
+			continue
+		}
+		grant, ok := m.grant(group, query.DestinationType, destinationID, serviceID)
+		if ok {
+			decision.Grants = append(decision.Grants, grant)
+		}
+	}
+	decision.Allowed = len(decision.Grants) > 0
+
+	subject := m.describe(query.SourceType, sourceID, "")
+	target := m.describe(query.DestinationType, destinationID, serviceID)
+	if !decision.Allowed {
+		decision.Explanation = fmt.Sprintf("no access group allows %s to reach %s", subject, target)
+		return decision, nil
+	}
+	var via []string
+	for _, grant := range decision.Grants {
+		description := fmt.Sprintf("%q", grant.AccessGroupName)
+		if len(grant.Services) > 0 {
+			description += " (services " + strings.Join(grant.Services, ", ") + ")"
+		}
+		via = append(via, description)
+	}
+	decision.Explanation = fmt.Sprintf("%s can reach %s via access group %s", subject, target, strings.Join(via, ", "))
+	return decision, nil
+}
+
+func (m *AccessModel) itemsCover(items []AccessItem, itemType, id, service string) bool {
+	for _, item := range items {
+		if item.Type == itemType && m.Coverage(item).Covers(id, service) {
+			return true
+		}
+	}
+	return false
+}
+
+// grant reports whether group's destinations cover the destination and which services they grant.
+func (m *AccessModel) grant(group AccessGroup, itemType, id, service string) (AccessGrant, bool) {
+	grant := AccessGrant{AccessGroupID: group.ID, AccessGroupName: group.Name}
+	whole := false
+	var services []string
+	for _, item := range group.Destination {
+		if item.Type != itemType {
+			continue
+		}
+		coverage := m.Coverage(item)
+		if !coverage.Covers(id, service) {
+			continue
+		}
+		covered := coverage.Entities[id]
+		switch {
+		case coverage.All || covered == nil:
+			whole = true
+		case service != "":
+			services = appendUnique(services, service)
+		default:
+			for _, s := range covered {
+				services = appendUnique(services, s)
+			}
+		}
+	}
+	if !whole && len(services) == 0 {
+		return grant, false
+	}
+	if !whole {
+		for _, s := range services {
+			grant.Services = append(grant.Services, m.Name(itemType, s))
+		}
+		sort.Strings(grant.Services)
+	}
+	return grant, true
+}
+
+func (m *AccessModel) describe(itemType, id, service string) string {
+	description := fmt.Sprintf("%s %s", strings.ToLower(strings.ReplaceAll(itemType, "_", " ")), m.Name(itemType, id))
+	if service != "" {
+		description += " service " + m.Name(itemType, service)
+	}
+	return description
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
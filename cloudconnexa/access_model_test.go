@@ -0,0 +1,149 @@
+package cloudconnexa
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestAccessModel() *AccessModel {
+	return &AccessModel{
+		UserGroups: []UserGroup{{ID: "ug-eng", Name: "Engineering"}, {ID: "ug-sales", Name: "Sales"}},
+		Networks:   []Network{{ID: "net-office", Name: "Office"}},
+		Hosts:      []Host{{ID: "host-db", Name: "db-01"}, {ID: "host-web", Name: "web-01"}},
+		HostIPServices: []HostIPServiceResponse{
+			{ID: "svc-pg", Name: "postgres", NetworkItemID: "host-db"},
+			{ID: "svc-ssh", Name: "ssh", NetworkItemID: "host-db"},
+		},
+		AccessGroups: []AccessGroup{
+			{
+				ID:          "ag-db",
+				Name:        "Engineering to Postgres",
+				Source:      []AccessItem{{Type: AccessItemTypeUserGroup, Children: []string{"ug-eng"}}},
+				Destination: []AccessItem{{Type: AccessItemTypeHost, Parent: "host-db", Children: []string{"svc-pg"}}},
+			},
+			{
+				ID:          "ag-web",
+				Name:        "Everyone to web",
+				Source:      []AccessItem{{Type: AccessItemTypeUserGroup, AllCovered: true}},
+				Destination: []AccessItem{{Type: AccessItemTypeHost, Parent: "host-web", AllCovered: true}},
+			},
+			{
+				ID:          "ag-office",
+				Name:        "Office to all hosts",
+				Source:      []AccessItem{{Type: AccessItemTypeNetwork, Parent: "net-office"}},
+				Destination: []AccessItem{{Type: AccessItemTypeHost, AllCovered: true}},
+			},
+		},
+	}
+}
+
+func TestAccessModel_CanReach(t *testing.T) {
+	model := newTestAccessModel()
+	tests := []struct {
+		name    string
+		query   AccessQuery
+		allowed bool
+		grants  string
+	}{
+		{
+			name:    "granted service",
+			query:   AccessQuery{SourceType: AccessItemTypeUserGroup, Source: "Engineering", DestinationType: AccessItemTypeHost, Destination: "db-01", Service: "postgres"},
+			allowed: true,
+			grants:  "ag-db",
+		},
+		{
+			name:  "service not granted",
+			query: AccessQuery{SourceType: AccessItemTypeUserGroup, Source: "Engineering", DestinationType: AccessItemTypeHost, Destination: "db-01", Service: "ssh"},
+		},
+		{
+			name:  "other user group",
+			query: AccessQuery{SourceType: AccessItemTypeUserGroup, Source: "ug-sales", DestinationType: AccessItemTypeHost, Destination: "db-01", Service: "postgres"},
+		},
+		{
+			name:    "all covered source",
+			query:   AccessQuery{SourceType: AccessItemTypeUserGroup, Source: "Sales", DestinationType: AccessItemTypeHost, Destination: "web-01"},
+			allowed: true,
+			grants:  "ag-web",
+		},
+		{
+			name:    "network to all hosts",
+			query:   AccessQuery{SourceType: AccessItemTypeNetwork, Source: "Office", DestinationType: AccessItemTypeHost, Destination: "db-01", Service: "ssh"},
+			allowed: true,
+			grants:  "ag-office",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := model.CanReach(tt.query)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if decision.Allowed != tt.allowed {
+				t.Errorf("Expected allowed=%v, got %+v", tt.allowed, decision)
+			}
+			var grants []string
+			for _, g := range decision.Grants {
+				grants = append(grants, g.AccessGroupID)
+			}
+			if strings.Join(grants, ",") != tt.grants {
+				t.Errorf("Expected grants %q, got %v", tt.grants, grants)
+			}
+		})
+	}
+}
+
+func TestAccessModel_CanReach_PartialDestination(t *testing.T) {
+	decision, err := newTestAccessModel().CanReach(AccessQuery{
+		SourceType: AccessItemTypeUserGroup, Source: "Engineering",
+		DestinationType: AccessItemTypeHost, Destination: "db-01",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !decision.Allowed || len(decision.Grants[0].Services) != 1 || decision.Grants[0].Services[0] != "postgres" {
+		t.Errorf("Expected a grant limited to postgres, got %+v", decision)
+	}
+	want := `user group Engineering can reach host db-01 via access group "Engineering to Postgres" (services postgres)`
+	if decision.Explanation != want {
+		t.Errorf("Expected explanation %q, got %q", want, decision.Explanation)
+	}
+}
+
+func TestAccessModel_CanReach_UnknownEntity(t *testing.T) {
+	_, err := newTestAccessModel().CanReach(AccessQuery{
+		SourceType: AccessItemTypeUserGroup, Source: "Marketing",
+		DestinationType: AccessItemTypeHost, Destination: "db-01",
+	})
+	if !errors.Is(err, ErrAccessEntityNotFound) {
+		t.Errorf("Expected ErrAccessEntityNotFound, got %v", err)
+	}
+}
+
+func TestAccessGroupsService_LoadModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var content any = []any{}
+		switch r.URL.Path {
+		case "/api/v1/access-groups":
+			content = []AccessGroup{{ID: "ag-1"}}
+		case "/api/v1/user-groups":
+			content = []UserGroup{{ID: "ug-1", Name: "Engineering"}}
+		case "/api/v1/hosts":
+			content = []Host{{ID: "host-1", Name: "db-01"}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"content": content, "totalPages": 1})
+	}))
+	defer server.Close()
+
+	client := createTestClientWithServices(server)
+	model, err := client.AccessGroups.LoadModel()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(model.AccessGroups) != 1 || model.Name(AccessItemTypeUserGroup, "ug-1") != "Engineering" || model.Name(AccessItemTypeHost, "host-1") != "db-01" {
+		t.Errorf("Unexpected model %+v", model)
+	}
+}
@@ -0,0 +1,395 @@
+package cloudconnexa
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrAuditFailed is returned by DoRequest when the audit sink rejects the
+	// entry of a write. The write itself may have been applied.
+	ErrAuditFailed = errors.New("audit failed")
+	// ErrAuditChainBroken is returned by VerifyAuditLog for an entry whose hash
+	// does not match its content or predecessor.
+	ErrAuditChainBroken = errors.New("audit chain broken")
+)
+
+// auditRedacted replaces the values of redacted request body fields.
+const auditRedacted = "[REDACTED]"
+
+// AuditRedactedFields lists substrings of JSON field names, matched
+// case-insensitively, whose values are redacted from audited request bodies.
+var AuditRedactedFields = []string{"secret", "password", "token", "presharedkey", "privatekey", "profile"}
+
+// AuditEntry records one write made through Client.DoRequest. Entries are
+// chained: Hash covers the entry including PrevHash, the Hash of the entry
+// before it, so that any change or removal is detectable with VerifyAuditLog.
+type AuditEntry struct {
+	Time  time.Time `json:"time"`
+	Actor string    `json:"actor"`
+	// Operation is "create", "update" or "delete" for POST, PUT/PATCH and
+	// DELETE, or the action of action endpoints such as "activate".
+	Operation  string `json:"operation"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Resource   string `json:"resource"`
+	ResourceID string `json:"resourceId,omitempty"`
+	// Request is the request body with AuditRedactedFields redacted.
+	Request json.RawMessage `json:"request,omitempty"`
+	// Status is the HTTP status of the response, or 0 if none was received.
+	Status   int    `json:"status"`
+	Error    string `json:"error,omitempty"`
+	PrevHash string `json:"prevHash"`
+	Hash     string `json:"hash"`
+}
+
+// AuditSink receives the audit entries of a Client. Record is called after
+// each write completes, possibly from several goroutines.
+type AuditSink interface {
+	Record(entry AuditEntry) error
+}
+
+// AuditChain links audit entries by hash. Sinks embed it and call Link while
+// holding the lock that serializes their writes.
+type AuditChain struct {
+	last string
+}
+
+// Link sets entry.PrevHash to the hash of the previous entry and computes entry.Hash.
+func (c *AuditChain) Link(entry *AuditEntry) error {
+	entry.PrevHash = c.last
+	hash, err := auditHash(*entry)
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+	c.last = hash
+	return nil
+}
+
+// Resume continues the chain after the entry with the given hash.
+func (c *AuditChain) Resume(hash string) {
+	c.last = hash
+}
+
+func auditHash(entry AuditEntry) (string, error) {
+	entry.Hash = ""
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// JSONLAuditSink appends audit entries as JSON Lines to a file. A sink
+// opened on an existing log continues its hash chain.
+type JSONLAuditSink struct {
+	mu    sync.Mutex
+	chain AuditChain
+	path  string
+}
+
+// NewJSONLAuditSink creates a sink appending to path, which is created if missing.
+func NewJSONLAuditSink(path string) (*JSONLAuditSink, error) {
+	sink := &JSONLAuditSink{path: path}
+	f, err := os.Open(path) //nolint:gosec // path is chosen by the caller
+	if errors.Is(err, os.ErrNotExist) {
+		return sink, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), int(DefaultMaxResponseSize))
+	var last AuditEntry
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &last); err != nil {
+			return nil, fmt.Errorf("reading audit log %s: %w", path, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sink.chain.Resume(last.Hash)
+	return sink, nil
+}
+
+// Record links entry into the chain and appends it to the file.
+func (s *JSONLAuditSink) Record(entry AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chain := s.chain
+	if err := chain.Link(&entry); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// Advance the chain only once the entry is stored.
+	s.chain = chain
+	return nil
+}
+
+// SlogAuditSink logs audit entries to a slog.Logger at level Info.
+type SlogAuditSink struct {
+	mu     sync.Mutex
+	chain  AuditChain
+	logger *slog.Logger
+}
+
+// NewSlogAuditSink creates a sink logging to logger, or slog.Default() if logger is nil.
+func NewSlogAuditSink(logger *slog.Logger) *SlogAuditSink {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogAuditSink{logger: logger}
+}
+
+// Record links entry into the chain and logs it.
+func (s *SlogAuditSink) Record(entry AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.chain.Link(&entry); err != nil {
+		return err
+	}
+	s.logger.LogAttrs(context.Background(), slog.LevelInfo, "cloudconnexa audit",
+		slog.Time("time", entry.Time),
+		slog.String("actor", entry.Actor),
+		slog.String("operation", entry.Operation),
+		slog.String("method", entry.Method),
+		slog.String("path", entry.Path),
+		slog.String("resource", entry.Resource),
+		slog.String("resourceId", entry.ResourceID),
+		slog.String("request", string(entry.Request)),
+		slog.Int("status", entry.Status),
+		slog.String("error", entry.Error),
+		slog.String("prevHash", entry.PrevHash),
+		slog.String("hash", entry.Hash),
+	)
+	return nil
+}
+
+// VerifyAuditLog checks the hash chain of a JSON Lines audit log and returns
+// the number of entries verified. The first entry may continue an earlier,
+// rotated log. A broken chain yields an error wrapping ErrAuditChainBroken
+// that names the offending line.
+func VerifyAuditLog(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), int(DefaultMaxResponseSize))
+	count, line := 0, 0
+	prev := ""
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return count, fmt.Errorf("%w: line %d: %v", ErrAuditChainBroken, line, err)
+		}
+		if count > 0 && entry.PrevHash != prev {
+			return count, fmt.Errorf("%w: line %d does not follow the previous entry", ErrAuditChainBroken, line)
+		}
+		hash, err := auditHash(entry)
+		if err != nil {
+			return count, err
+		}
+		if hash != entry.Hash {
+			return count, fmt.Errorf("%w: line %d has been modified", ErrAuditChainBroken, line)
+		}
+		prev = entry.Hash
+		count++
+	}
+	return count, scanner.Err()
+}
+
+// newAuditEntry describes the write req before it is sent.
+func (c *Client) newAuditEntry(req *http.Request) *AuditEntry {
+	resource, id, action := auditTarget(req.URL.Path)
+	entry := &AuditEntry{
+		Time:       time.Now().UTC(),
+		Actor:      c.ClientID,
+		Operation:  auditOperation(req.Method, action),
+		Method:     req.Method,
+		Path:       req.URL.RequestURI(),
+		Resource:   resource,
+		ResourceID: id,
+	}
+	if body := peekRequestBody(req); len(body) > 0 {
+		entry.Request = redactAuditBody(body)
+	}
+	return entry
+}
+
+// recordAudit completes entry with the outcome of the request and records it.
+func (c *Client) recordAudit(entry *AuditEntry, status int, body []byte, reqErr error) error {
+	entry.Status = status
+	var apiErr *ErrClientResponse
+	switch {
+	case errors.As(reqErr, &apiErr):
+		entry.Status = apiErr.StatusCode()
+		entry.Error = fmt.Sprintf("status %d", apiErr.StatusCode())
+	case reqErr != nil:
+		entry.Error = reqErr.Error()
+	}
+	if entry.ResourceID == "" && reqErr == nil {
+		var created struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(body, &created) == nil {
+			entry.ResourceID = created.ID
+		}
+	}
+	if err := c.AuditSink.Record(*entry); err != nil {
+		return errors.Join(reqErr, fmt.Errorf("%w: %v", ErrAuditFailed, err))
+	}
+	return reqErr
+}
+
+// auditOperation names a write: its action, if any, or "create", "update"
+// or "delete" by method.
+func auditOperation(method, action string) string {
+	if action != "" {
+		return action
+	}
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "update"
+	}
+}
+
+// auditSubResources are the collections nested under a top-level resource,
+// as in /networks/connectors/{id}.
+var auditSubResources = map[string]bool{
+	"applications": true,
+	"connectors":   true,
+	"ip-services":  true,
+	"routes":       true,
+}
+
+// auditSingletons are resources written without an ID, such as
+// /settings/wpc/subnet; their whole path names the resource.
+var auditSingletons = map[string]bool{
+	"access-visibility": true,
+	"dns-log":           true,
+	"settings":          true,
+}
+
+// auditTarget splits an API path into its resource, ID and trailing action
+// following the route shape resource[/sub-resource][/id][/action...], so
+// /api/v1/networks/connectors/{id}/ipsec/start yields "networks/connectors",
+// the ID and "ipsec/start".
+func auditTarget(path string) (resource, id, action string) {
+	if _, rest, ok := strings.Cut(path, "/api/v1/"); ok {
+		path = rest
+	}
+	path = strings.Trim(path, "/")
+	segments := strings.Split(path, "/")
+	if auditSingletons[segments[0]] {
+		return path, "", ""
+	}
+	resource, segments = segments[0], segments[1:]
+	if len(segments) > 0 && auditSubResources[segments[0]] {
+		resource, segments = resource+"/"+segments[0], segments[1:]
+	}
+	if len(segments) > 0 {
+		id, segments = segments[0], segments[1:]
+	}
+	return resource, id, strings.Join(segments, "/")
+}
+
+// peekRequestBody returns the body of req without consuming it.
+func peekRequestBody(req *http.Request) []byte {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.GetBody != nil {
+		if rc, err := req.GetBody(); err == nil {
+			defer rc.Close()
+			data, _ := io.ReadAll(rc)
+			return data
+		}
+	}
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// redactAuditBody returns body as compact JSON with redacted fields. Bodies
+// that are not JSON are recorded by size only.
+func redactAuditBody(body []byte) json.RawMessage {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		data, _ := json.Marshal(fmt.Sprintf("[%d bytes]", len(body)))
+		return data
+	}
+	data, err := json.Marshal(redactAuditValue(v))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func redactAuditValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			lower := strings.ToLower(key)
+			redacted := false
+			for _, field := range AuditRedactedFields {
+				if strings.Contains(lower, field) {
+					redacted = true
+					break
+				}
+			}
+			if redacted {
+				v[key] = auditRedacted
+			} else {
+				v[key] = redactAuditValue(value)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactAuditValue(v[i])
+		}
+	}
+	return v
+}
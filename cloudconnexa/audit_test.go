@@ -0,0 +1,179 @@
+package cloudconnexa
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newAuditTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/networks/connectors":
+			_, _ = w.Write([]byte(`{"id":"nc-1","name":"office"}`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+}
+
+func readAuditEntries(t *testing.T, path string) []AuditEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer f.Close()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestClient_AuditSink(t *testing.T) {
+	server := newAuditTestServer()
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewJSONLAuditSink(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	client := createTestClientWithServices(server)
+	client.ClientID = "automation"
+	client.AuditSink = sink
+
+	body := `{"name":"office","ipSecConfig":{"preSharedKey":"hunter2"}}`
+	req, _ := http.NewRequest(http.MethodPost, client.GetV1Url()+"/networks/connectors?networkId=n-1", strings.NewReader(body))
+	if _, err := client.DoRequest(req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := client.Users.Activate("u-1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := client.Networks.Delete("n-2"); err == nil {
+		t.Fatal("Expected the API error")
+	}
+	if _, err := client.Networks.List(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries := readAuditEntries(t, path)
+	if len(entries) != 3 {
+		t.Fatalf("Expected one entry per write, got %d", len(entries))
+	}
+	create := entries[0]
+	if create.Actor != "automation" || create.Operation != "create" || create.Resource != "networks/connectors" ||
+		create.ResourceID != "nc-1" || create.Status != http.StatusOK || create.Path != "/api/v1/networks/connectors?networkId=n-1" {
+		t.Errorf("Unexpected create entry %+v", create)
+	}
+	if strings.Contains(string(create.Request), "hunter2") || !strings.Contains(string(create.Request), auditRedacted) {
+		t.Errorf("Expected the pre-shared key to be redacted, got %s", create.Request)
+	}
+	if e := entries[1]; e.Operation != "activate" || e.Resource != "users" || e.ResourceID != "u-1" {
+		t.Errorf("Unexpected activate entry %+v", e)
+	}
+	if e := entries[2]; e.Operation != "delete" || e.Status != http.StatusNotFound || e.Error == "" {
+		t.Errorf("Unexpected delete entry %+v", e)
+	}
+
+	// A reopened sink continues the chain.
+	sink, err = NewJSONLAuditSink(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	client.AuditSink = sink
+	_ = client.Users.Suspend("u-1")
+
+	data, _ := os.ReadFile(path)
+	if n, err := VerifyAuditLog(bytes.NewReader(data)); err != nil || n != 4 {
+		t.Errorf("Expected 4 verified entries, got %d, %v", n, err)
+	}
+	tampered := bytes.Replace(data, []byte(`"status":404`), []byte(`"status":204`), 1)
+	if _, err := VerifyAuditLog(bytes.NewReader(tampered)); !errors.Is(err, ErrAuditChainBroken) || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected a modified line 3, got %v", err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	removed := bytes.Join(append(lines[:1:1], lines[2:]...), nil)
+	if _, err := VerifyAuditLog(bytes.NewReader(removed)); !errors.Is(err, ErrAuditChainBroken) {
+		t.Errorf("Expected a removed entry to break the chain, got %v", err)
+	}
+}
+
+type failingAuditSink struct{}
+
+func (failingAuditSink) Record(AuditEntry) error { return errors.New("disk full") }
+
+func TestClient_AuditSink_Failure(t *testing.T) {
+	server := newAuditTestServer()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+	client.AuditSink = failingAuditSink{}
+
+	if err := client.Users.Activate("u-1"); !errors.Is(err, ErrAuditFailed) {
+		t.Errorf("Expected ErrAuditFailed, got %v", err)
+	}
+}
+
+func TestSlogAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewSlogAuditSink(slog.New(slog.NewJSONHandler(&buf, nil)))
+	for range 2 {
+		if err := sink.Record(AuditEntry{Operation: "update", Resource: "users"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	var records []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		_ = json.Unmarshal(line, &record)
+		records = append(records, record)
+	}
+	if len(records) != 2 || records[1]["prevHash"] != records[0]["hash"] || records[0]["hash"] == "" {
+		t.Errorf("Expected chained log records, got %v", records)
+	}
+}
+
+func TestAuditTarget(t *testing.T) {
+	tests := []struct {
+		path                 string
+		resource, id, action string
+		method, operation    string
+	}{
+		{"/api/v1/users", "users", "", "", http.MethodPost, "create"},
+		{"/api/v1/users/u-1", "users", "u-1", "", http.MethodPut, "update"},
+		{"/api/v1/users/alice/activate", "users", "alice", "activate", http.MethodPut, "activate"},
+		{"/api/v1/users/alice/suspend", "users", "alice", "suspend", http.MethodPut, "suspend"},
+		{"/api/v1/networks/routes/r-7", "networks/routes", "r-7", "", http.MethodDelete, "delete"},
+		{"/api/v1/networks/connectors/nc-1/ipsec/start", "networks/connectors", "nc-1", "ipsec/start", http.MethodPost, "ipsec/start"},
+		{"/api/v1/networks/connectors/office/ipsec/stop", "networks/connectors", "office", "ipsec/stop", http.MethodPost, "ipsec/stop"},
+		{"/api/v1/hosts/connectors/hc-1/profile", "hosts/connectors", "hc-1", "profile", http.MethodPost, "profile"},
+		{"/api/v1/hosts/connectors/hc-1/profile/encrypt", "hosts/connectors", "hc-1", "profile/encrypt", http.MethodPost, "profile/encrypt"},
+		{"/api/v1/devices/laptop/profile", "devices", "laptop", "profile", http.MethodPost, "profile"},
+		{"/api/v1/settings/wpc/subnet", "settings/wpc/subnet", "", "", http.MethodPut, "update"},
+	}
+	for _, tt := range tests {
+		resource, id, action := auditTarget(tt.path)
+		if resource != tt.resource || id != tt.id || action != tt.action {
+			t.Errorf("auditTarget(%q) = %q, %q, %q; expected %q, %q, %q", tt.path, resource, id, action, tt.resource, tt.id, tt.action)
+		}
+		if op := auditOperation(tt.method, action); op != tt.operation {
+			t.Errorf("auditOperation(%s, %q) = %q; expected %q", tt.method, action, op, tt.operation)
+		}
+	}
+}
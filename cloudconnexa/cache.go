@@ -0,0 +1,220 @@
+package cloudconnexa
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStore stores cached API responses by key. Implementations must be
+// safe for concurrent use.
+type CacheStore interface {
+	// Get returns the value stored for key if it has not expired.
+	Get(key string) ([]byte, bool)
+	// Set stores value for key for the duration ttl.
+	Set(key string, value []byte, ttl time.Duration)
+	// DeletePrefix removes all values whose key starts with prefix.
+	DeletePrefix(prefix string)
+}
+
+// MemoryCacheStore is the default in-memory CacheStore.
+type MemoryCacheStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	now     func() time.Time
+}
+
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCacheStore creates an empty in-memory cache store.
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{entries: map[string]memoryCacheEntry{}, now: time.Now}
+}
+
+// Get returns the value stored for key if it has not expired.
+func (s *MemoryCacheStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !s.now().Before(entry.expires) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set stores value for key for the duration ttl.
+func (s *MemoryCacheStore) Set(key string, value []byte, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryCacheEntry{value: value, expires: s.now().Add(ttl)}
+}
+
+// DeletePrefix removes all values whose key starts with prefix.
+func (s *MemoryCacheStore) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// CacheOptions configures the response cache of a Client.
+//
+// TTLs are set per resource, the first path segment after /api/v1, e.g.
+// "networks" (which includes network connectors and routes), "users",
+// "user-groups" or "regions". Resources without a TTL use DefaultTTL;
+// a zero TTL disables caching for the resource. Sessions are never cached:
+// polling them with a cursor returns new data for the same URL.
+type CacheOptions struct {
+	// Store holds cached responses. Defaults to a new MemoryCacheStore.
+	Store      CacheStore
+	DefaultTTL time.Duration
+	TTLs       map[string]time.Duration
+}
+
+// cacheDependencies lists resources whose cached responses embed data of
+// another resource and must be invalidated when it changes.
+var cacheDependencies = map[string][]string{
+	"devices":     {"users"},
+	"users":       {"devices"},
+	"user-groups": {"users"},
+}
+
+type responseCache struct {
+	store      CacheStore
+	defaultTTL time.Duration
+	ttls       map[string]time.Duration
+}
+
+// EnableCache enables a read-through cache for successful GET responses.
+// A write (any other method) through the client drops the cached responses
+// of the written resource and of resources embedding it, e.g. a route write
+// drops every cached "networks" response including that network's routes.
+// Call EnableCache before the client is shared between goroutines.
+func (c *Client) EnableCache(opts CacheOptions) {
+	store := opts.Store
+	if store == nil {
+		store = NewMemoryCacheStore()
+	}
+	ttls := make(map[string]time.Duration, len(opts.TTLs))
+	for resource, ttl := range opts.TTLs {
+		ttls[resource] = ttl
+	}
+	c.cache = &responseCache{store: store, defaultTTL: opts.DefaultTTL, ttls: ttls}
+}
+
+// DisableCache disables the response cache.
+func (c *Client) DisableCache() {
+	c.cache = nil
+}
+
+// InvalidateCache drops the cached responses of resource, e.g. "networks",
+// or all cached responses if resource is empty. This is needed only after
+// changes made outside the client.
+func (c *Client) InvalidateCache(resource string) {
+	if c.cache == nil {
+		return
+	}
+	if resource == "" {
+		c.cache.store.DeletePrefix(c.GetV1Url() + "/")
+		return
+	}
+	c.invalidateCachedResource(resource)
+}
+
+// WithoutCache returns a client that shares c's credentials, HTTP client,
+// rate limiters and cache store but neither reads nor stores cached
+// responses. It keeps lookup indexes of its own. Writes made through it
+// still invalidate the cache and the lookup indexes of c.
+func (c *Client) WithoutCache() *Client {
+	nc := &Client{
+		client:                 c.client,
+		BaseURL:                c.BaseURL,
+		Token:                  c.Token,
+		ReadRateLimiter:        c.ReadRateLimiter,
+		UpdateRateLimiter:      c.UpdateRateLimiter,
+		UserAgent:              c.UserAgent,
+		ClientID:               c.ClientID,
+		AuditSink:              c.AuditSink,
+		DryRun:                 c.DryRun,
+		AllowUnknownEnumValues: c.AllowUnknownEnumValues,
+		LookupIndexTTL:         c.LookupIndexTTL,
+		cache:                  c.cache,
+		bypassCache:            true,
+		indexParent:            c,
+	}
+	nc.initServices()
+	return nc
+}
+
+// apiResource returns the resource of an API URL path, e.g. "networks" for
+// /api/v1/networks/routes/{id}.
+func apiResource(path string) string {
+	if _, rest, ok := strings.Cut(path, "/api/v1/"); ok {
+		path = rest
+	}
+	resource, _, _ := strings.Cut(strings.Trim(path, "/"), "/")
+	return resource
+}
+
+// uncachedResources lists resources whose responses change between identical
+// requests, such as a sessions poll with a cursor and returnOnlyNew.
+var uncachedResources = map[string]bool{
+	"sessions": true,
+}
+
+// cacheable reports whether the response to req may be read from or stored in the cache.
+func (c *Client) cacheable(req *http.Request) bool {
+	return c.cache != nil && !c.bypassCache && req.Method == http.MethodGet &&
+		!uncachedResources[apiResource(req.URL.Path)] && req.URL.Query().Get("returnOnlyNew") == ""
+}
+
+// cachedResponse returns the cached body for req, if any. Requests sent with
+// "Cache-Control: no-cache" are never answered from the cache.
+func (c *Client) cachedResponse(req *http.Request) ([]byte, bool) {
+	if !c.cacheable(req) || req.Header.Get("Cache-Control") == "no-cache" {
+		return nil, false
+	}
+	return c.cache.store.Get(req.URL.String())
+}
+
+// storeResponse caches a successful GET response for the TTL of its resource.
+func (c *Client) storeResponse(req *http.Request, body []byte) {
+	if !c.cacheable(req) {
+		return
+	}
+	ttl, ok := c.cache.ttls[apiResource(req.URL.Path)]
+	if !ok {
+		ttl = c.cache.defaultTTL
+	}
+	if ttl > 0 {
+		c.cache.store.Set(req.URL.String(), body, ttl)
+	}
+}
+
+// invalidateCache drops the cached responses affected by a write to req.URL.
+func (c *Client) invalidateCache(req *http.Request) {
+	if c.cache == nil || req.Method == http.MethodGet {
+		return
+	}
+	c.invalidateCachedResource(apiResource(req.URL.Path))
+}
+
+// invalidateCachedResource drops the cached responses of resource and its
+// dependents. No resource name is a prefix of another, so a plain key prefix
+// matches exactly the URLs of the resource.
+func (c *Client) invalidateCachedResource(resource string) {
+	for _, r := range append([]string{resource}, cacheDependencies[resource]...) {
+		c.cache.store.DeletePrefix(c.GetV1Url() + "/" + r)
+	}
+}
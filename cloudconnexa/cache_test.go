@@ -0,0 +1,163 @@
+package cloudconnexa
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheStore(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := NewMemoryCacheStore()
+	store.now = func() time.Time { return now }
+
+	store.Set("a/1", []byte("one"), time.Minute)
+	store.Set("a/2", []byte("two"), time.Hour)
+	store.Set("b/1", []byte("three"), time.Hour)
+	if v, ok := store.Get("a/1"); !ok || string(v) != "one" {
+		t.Errorf("Expected a cached value, got %q, %v", v, ok)
+	}
+	now = now.Add(time.Minute)
+	if _, ok := store.Get("a/1"); ok {
+		t.Error("Expected the value to expire")
+	}
+	store.DeletePrefix("a/")
+	if _, ok := store.Get("a/2"); ok {
+		t.Error("Expected the value to be deleted")
+	}
+	if _, ok := store.Get("b/1"); !ok {
+		t.Error("Expected other prefixes to be kept")
+	}
+}
+
+type cacheAPI struct {
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (a *cacheAPI) server() *httptest.Server {
+	a.requests = map[string]int{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.requests[r.Method+" "+r.URL.Path]++
+		switch r.URL.Path {
+		case "/api/v1/networks/routes":
+			_ = json.NewEncoder(w).Encode(RoutePageResponse{Content: []Route{{ID: "r-1", Subnet: "10.0.0.0/24"}}, TotalPages: 1})
+		case "/api/v1/users":
+			_ = json.NewEncoder(w).Encode(UserPageResponse{TotalPages: 1})
+		case "/api/v1/sessions":
+			_ = json.NewEncoder(w).Encode(SessionsResponse{NextCursor: "c-1"})
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+}
+
+func (a *cacheAPI) count(key string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.requests[key]
+}
+
+func TestClient_Cache(t *testing.T) {
+	api := &cacheAPI{}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+	client.EnableCache(CacheOptions{
+		DefaultTTL: time.Hour,
+		TTLs:       map[string]time.Duration{"regions": 0},
+	})
+
+	for range 3 {
+		if _, err := client.Routes.List("n-1"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := client.VPNRegions.List(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if n := api.count("GET /api/v1/networks/routes"); n != 1 {
+		t.Errorf("Expected routes to be cached, got %d requests", n)
+	}
+	if n := api.count("GET /api/v1/regions"); n != 3 {
+		t.Errorf("Expected regions not to be cached with a zero TTL, got %d requests", n)
+	}
+
+	if err := client.Routes.Update(Route{ID: "r-1", Subnet: "10.0.1.0/24"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, _ = client.Routes.List("n-1")
+	if n := api.count("GET /api/v1/networks/routes"); n != 2 {
+		t.Errorf("Expected a route write to invalidate the route list, got %d requests", n)
+	}
+
+	// Explicit bypasses.
+	_, _ = client.WithoutCache().Routes.List("n-1")
+	req, _ := http.NewRequest(http.MethodGet, client.GetV1Url()+"/networks/routes?networkId=n-1&page=0&size=100", nil)
+	req.Header.Set("Cache-Control", "no-cache")
+	_, _ = client.DoRequest(req)
+	if n := api.count("GET /api/v1/networks/routes"); n != 4 {
+		t.Errorf("Expected bypassed requests to reach the API, got %d requests", n)
+	}
+	_, _ = client.Routes.List("n-1")
+	if n := api.count("GET /api/v1/networks/routes"); n != 4 {
+		t.Errorf("Expected the cache to be used again, got %d requests", n)
+	}
+
+	// A device write invalidates cached users, which embed devices.
+	_, _ = client.Users.List()
+	req, _ = http.NewRequest(http.MethodDelete, client.GetV1Url()+"/devices/d-1", nil)
+	_, _ = client.DoRequest(req)
+	_, _ = client.Users.List()
+	if n := api.count("GET /api/v1/users"); n != 2 {
+		t.Errorf("Expected a device write to invalidate users, got %d requests", n)
+	}
+
+	client.InvalidateCache("")
+	client.DisableCache()
+	_, _ = client.Routes.List("n-1")
+	if n := api.count("GET /api/v1/networks/routes"); n != 5 {
+		t.Errorf("Expected no caching once disabled, got %d requests", n)
+	}
+}
+
+func TestClient_Cache_PerResourceTTL(t *testing.T) {
+	api := &cacheAPI{}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+	client.EnableCache(CacheOptions{TTLs: map[string]time.Duration{"networks": time.Hour}})
+
+	for range 2 {
+		_, _ = client.Routes.List("n-1")
+		_, _ = client.Users.List()
+	}
+	if n := api.count("GET /api/v1/networks/routes"); n != 1 {
+		t.Errorf("Expected routes to be cached, got %d requests", n)
+	}
+	if n := api.count("GET /api/v1/users"); n != 2 {
+		t.Errorf("Expected users not to be cached without a TTL, got %d requests", n)
+	}
+}
+
+func TestClient_Cache_Sessions(t *testing.T) {
+	api := &cacheAPI{}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+	client.EnableCache(CacheOptions{DefaultTTL: time.Hour, TTLs: map[string]time.Duration{"sessions": time.Hour}})
+
+	for range 2 {
+		if _, err := client.Sessions.List(SessionsListOptions{Size: 10, ReturnOnlyNew: true, Cursor: "c-1"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if n := api.count("GET /api/v1/sessions"); n != 2 {
+		t.Errorf("Expected sessions polls not to be cached, got %d requests", n)
+	}
+}
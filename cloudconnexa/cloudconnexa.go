@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -34,6 +35,10 @@ type ClientOptions struct {
 	// (localhost, 127.0.0.1, ::1). This is intended for local development and testing.
 	// WARNING: HTTP connections to non-loopback addresses are always rejected.
 	AllowInsecureHTTP bool
+
+	// AllowUnknownEnumValues disables client-side validation of enum fields such as
+	// topology or connect auth, for values the API supports but this client does not know yet.
+	AllowUnknownEnumValues bool
 }
 
 // validateBaseURL validates the base URL for the API client.
@@ -129,6 +134,36 @@ type Client struct {
 
 	UserAgent string
 
+	// ClientID is the OAuth client ID the client authenticated with. It is
+	// recorded as the actor of audit entries.
+	ClientID string
+
+	// AuditSink, if set, receives an AuditEntry for every non-GET request
+	// made through DoRequest.
+	AuditSink AuditSink
+
+	// DryRun, if set, receives every non-GET request made through DoRequest
+	// instead of the API, and the request is answered with a synthetic
+	// response. GET requests are still sent.
+	DryRun *DryRunPlan
+
+	// AllowUnknownEnumValues disables client-side validation of enum fields.
+	AllowUnknownEnumValues bool
+
+	// LookupIndexTTL enables in-memory indexes for GetByName, GetByUsername,
+	// FindByUsernameAndRole and Query. Each index lists its collection once and
+	// is reloaded after LookupIndexTTL or after a write to the collection
+	// through this client. Zero disables the indexes.
+	LookupIndexTTL time.Duration
+
+	lookupIndexes sync.Map // collection path -> *Index[T]
+	// indexParent is the client whose lookup indexes writes through this
+	// client also invalidate, set by WithoutCache.
+	indexParent *Client
+
+	cache       *responseCache
+	bypassCache bool
+
 	common service
 
 	HostConnectors      *HostConnectorsService
@@ -191,8 +226,10 @@ func NewClientWithOptions(baseURL, clientID, clientSecret string, opts *ClientOp
 	}
 
 	allowHTTP := false
+	allowUnknownEnums := false
 	if opts != nil {
 		allowHTTP = opts.AllowInsecureHTTP
+		allowUnknownEnums = opts.AllowUnknownEnumValues
 	}
 
 	normalizedURL, err := validateBaseURL(baseURL, allowHTTP)
@@ -250,9 +287,18 @@ func NewClientWithOptions(baseURL, clientID, clientSecret string, opts *ClientOp
 		BaseURL:           normalizedURL,
 		Token:             credentials.AccessToken,
 		UserAgent:         userAgent,
+		ClientID:          clientID,
 		ReadRateLimiter:   rate.NewLimiter(rate.Every(1*time.Second), 1),
 		UpdateRateLimiter: rate.NewLimiter(rate.Every(4*time.Second), 1),
+
+		AllowUnknownEnumValues: allowUnknownEnums,
 	}
+	c.initServices()
+	return c, nil
+}
+
+// initServices points the services of c at c.
+func (c *Client) initServices() {
 	c.common.client = c
 	c.HostConnectors = (*HostConnectorsService)(&c.common)
 	c.NetworkConnectors = (*NetworkConnectorsService)(&c.common)
@@ -272,7 +318,6 @@ func NewClientWithOptions(baseURL, clientID, clientSecret string, opts *ClientOp
 	c.Settings = (*SettingsService)(&c.common)
 	c.Sessions = (*SessionsService)(&c.common)
 	c.Devices = (*DevicesService)(&c.common)
-	return c, nil
 }
 
 // setCommonHeaders sets the standard headers for API requests.
@@ -289,21 +334,40 @@ func (c *Client) setCommonHeaders(req *http.Request) {
 
 // DoRequest executes an HTTP request with authentication and rate limiting.
 // It automatically adds the Bearer token, sets headers, and handles errors.
-func (c *Client) DoRequest(req *http.Request) ([]byte, error) {
+func (c *Client) DoRequest(req *http.Request) (body []byte, err error) {
 	var rateLimiter *rate.Limiter
 	if req.Method == "GET" {
 		rateLimiter = c.ReadRateLimiter
 	} else {
 		rateLimiter = c.UpdateRateLimiter
 	}
-	err := rateLimiter.Wait(context.Background())
+	if c.DryRun != nil && req.Method != http.MethodGet {
+		return c.DryRun.record(req), nil
+	}
+	if cached, ok := c.cachedResponse(req); ok {
+		return cached, nil
+	}
+	err = rateLimiter.Wait(context.Background())
 	if err != nil {
 		return nil, err
 	}
 
 	c.setCommonHeaders(req)
 
+	var status int
+	if c.AuditSink != nil && req.Method != http.MethodGet {
+		entry := c.newAuditEntry(req)
+		defer func() { err = c.recordAudit(entry, status, body, err) }()
+	}
+
 	res, err := c.client.Do(req)
+	if res != nil {
+		status = res.StatusCode
+	}
+	if req.Method != http.MethodGet {
+		c.invalidateLookupIndexes(req.URL.Path)
+		c.invalidateCache(req)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -316,7 +380,7 @@ func (c *Client) DoRequest(req *http.Request) ([]byte, error) {
 
 	// Bound response body size to prevent memory exhaustion (CWE-400)
 	limitedReader := io.LimitReader(res.Body, DefaultMaxResponseSize+1)
-	body, err := io.ReadAll(limitedReader)
+	body, err = io.ReadAll(limitedReader)
 	if err != nil {
 		return nil, err
 	}
@@ -334,6 +398,7 @@ func (c *Client) DoRequest(req *http.Request) ([]byte, error) {
 		return nil, err
 	}
 
+	c.storeResponse(req, body)
 	return body, nil
 }
 
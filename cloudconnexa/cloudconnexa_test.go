@@ -39,6 +39,19 @@ func setupMockServer() *httptest.Server {
 	return server
 }
 
+// createTestClientWithServices creates a test client with all services for the given server
+func createTestClientWithServices(server *httptest.Server) *Client {
+	client := &Client{
+		client:            server.Client(),
+		BaseURL:           server.URL,
+		Token:             "test-token",
+		ReadRateLimiter:   rate.NewLimiter(rate.Every(1), 5),
+		UpdateRateLimiter: rate.NewLimiter(rate.Every(1), 5),
+	}
+	client.initServices()
+	return client
+}
+
 // TestNewClient tests the creation of a new CloudConnexa client with various credential combinations.
 // It verifies that the client is properly initialized with valid credentials and returns
 // appropriate errors for invalid credentials.
@@ -0,0 +1,214 @@
+package cloudconnexa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DeviceCleanupAction is the action applied to devices matched by a cleanup policy.
+type DeviceCleanupAction string
+
+const (
+	// DeviceCleanupActionReport only reports matching devices.
+	DeviceCleanupActionReport DeviceCleanupAction = "report"
+	// DeviceCleanupActionRevokeProfile revokes the active profile of matching devices.
+	DeviceCleanupActionRevokeProfile DeviceCleanupAction = "revoke_profile"
+	// DeviceCleanupActionDelete deletes matching devices, freeing the user's device allowance.
+	DeviceCleanupActionDelete DeviceCleanupAction = "delete"
+)
+
+// DeviceCleanupReason explains why a device was matched by a cleanup policy.
+type DeviceCleanupReason string
+
+const (
+	// DeviceCleanupReasonStale marks a device without any session in the policy window.
+	DeviceCleanupReasonStale DeviceCleanupReason = "stale"
+	// DeviceCleanupReasonSuspendedUser marks a device owned by a suspended user.
+	DeviceCleanupReasonSuspendedUser DeviceCleanupReason = "suspended_user"
+	// DeviceCleanupReasonDuplicateClientUUID marks a device sharing its ClientUUID with a more recently used device.
+	DeviceCleanupReasonDuplicateClientUUID DeviceCleanupReason = "duplicate_client_uuid"
+)
+
+// DeviceCleanupPolicy configures DevicesService.Cleanup.
+type DeviceCleanupPolicy struct {
+	// StaleAfter matches devices that have not started a session within this duration.
+	// Devices that are currently connected are never considered stale. Zero disables the check.
+	StaleAfter time.Duration
+	// SuspendedUsers matches devices owned by suspended users.
+	SuspendedUsers bool
+	// DuplicateClientUUIDs matches all but one device of every ClientUUID. The device kept
+	// is the one with the most recent session within StaleAfter, or the first one listed.
+	DuplicateClientUUIDs bool
+	// Action is applied to every matched device. Defaults to DeviceCleanupActionReport.
+	Action DeviceCleanupAction
+	// Enforce applies Action. When false the run is a dry run and nothing is modified.
+	Enforce bool
+	// Now is the reference time for StaleAfter. Defaults to time.Now().
+	Now time.Time
+}
+
+// DeviceCleanupFinding describes a device matched by a cleanup policy and what was done with it.
+type DeviceCleanupFinding struct {
+	Time       time.Time             `json:"time"`
+	DeviceID   string                `json:"deviceId"`
+	DeviceName string                `json:"deviceName"`
+	UserID     string                `json:"userId"`
+	Username   string                `json:"username,omitempty"`
+	ClientUUID string                `json:"clientUUID,omitempty"`
+	Reasons    []DeviceCleanupReason `json:"reasons"`
+	Action     DeviceCleanupAction   `json:"action"`
+	Applied    bool                  `json:"applied"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// DeviceCleanupReport summarizes a cleanup run.
+type DeviceCleanupReport struct {
+	GeneratedAt    time.Time              `json:"generatedAt"`
+	Enforce        bool                   `json:"enforce"`
+	Action         DeviceCleanupAction    `json:"action"`
+	DevicesScanned int                    `json:"devicesScanned"`
+	Findings       []DeviceCleanupFinding `json:"findings"`
+}
+
+// Cleanup finds devices matching policy and, in enforce mode, applies the policy action.
+// One JSON line per finding is written to audit, if it is not nil, after the action was attempted.
+// Failures of individual actions are recorded in the findings rather than aborting the run.
+func (d *DevicesService) Cleanup(policy DeviceCleanupPolicy, audit io.Writer) (*DeviceCleanupReport, error) {
+	if policy.Action == "" {
+		policy.Action = DeviceCleanupActionReport
+	}
+	switch policy.Action {
+	case DeviceCleanupActionReport, DeviceCleanupActionRevokeProfile, DeviceCleanupActionDelete:
+	default:
+		return nil, fmt.Errorf("unknown device cleanup action %q", policy.Action)
+	}
+	now := policy.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	devices, err := d.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	users, err := d.client.Users.List()
+	if err != nil {
+		return nil, err
+	}
+	usersByID := make(map[string]User, len(users))
+	for _, u := range users {
+		usersByID[u.ID] = u
+	}
+
+	// Most recent session start per device within the stale window.
+	lastSeen := make(map[string]time.Time)
+	if policy.StaleAfter > 0 {
+		start := now.Add(-policy.StaleAfter)
+		sessions, err := d.client.Sessions.ListAll(SessionsListOptions{StartDate: &start})
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range sessions {
+			if s.StartDateTime.After(lastSeen[s.DeviceID]) {
+				lastSeen[s.DeviceID] = s.StartDateTime
+			}
+		}
+	}
+
+	reasons := make(map[string][]DeviceCleanupReason)
+	if policy.StaleAfter > 0 {
+		for _, dev := range devices {
+			if _, seen := lastSeen[dev.ID]; !seen && !strings.EqualFold(dev.ConnectionStatus, "ONLINE") {
+				reasons[dev.ID] = append(reasons[dev.ID], DeviceCleanupReasonStale)
+			}
+		}
+	}
+	if policy.SuspendedUsers {
+		for _, dev := range devices {
+			if usersByID[dev.UserID].Status == UserStatusSuspended {
+				reasons[dev.ID] = append(reasons[dev.ID], DeviceCleanupReasonSuspendedUser)
+			}
+		}
+	}
+	if policy.DuplicateClientUUIDs {
+		for _, dup := range duplicateClientUUIDDevices(devices, lastSeen) {
+			reasons[dup] = append(reasons[dup], DeviceCleanupReasonDuplicateClientUUID)
+		}
+	}
+
+	report := &DeviceCleanupReport{
+		GeneratedAt:    now,
+		Enforce:        policy.Enforce,
+		Action:         policy.Action,
+		DevicesScanned: len(devices),
+	}
+	var enc *json.Encoder
+	if audit != nil {
+		enc = json.NewEncoder(audit)
+	}
+	for _, dev := range devices {
+		rs, ok := reasons[dev.ID]
+		if !ok {
+			continue
+		}
+		finding := DeviceCleanupFinding{
+			Time:       time.Now().UTC(),
+			DeviceID:   dev.ID,
+			DeviceName: dev.Name,
+			UserID:     dev.UserID,
+			Username:   usersByID[dev.UserID].Username,
+			ClientUUID: dev.ClientUUID,
+			Reasons:    rs,
+			Action:     policy.Action,
+		}
+		if policy.Enforce && policy.Action != DeviceCleanupActionReport {
+			var err error
+			if policy.Action == DeviceCleanupActionDelete {
+				err = d.Delete(dev.UserID, dev.ID)
+			} else {
+				err = d.RevokeProfile(dev.UserID, dev.ID)
+			}
+			if err != nil {
+				finding.Error = err.Error()
+			} else {
+				finding.Applied = true
+			}
+		}
+		if enc != nil {
+			if err := enc.Encode(finding); err != nil {
+				return report, err
+			}
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+	return report, nil
+}
+
+// duplicateClientUUIDDevices returns the IDs of devices that share a ClientUUID with
+// another device, keeping the most recently seen device (or the first listed) of each group.
+func duplicateClientUUIDDevices(devices []DeviceDetail, lastSeen map[string]time.Time) []string {
+	groups := make(map[string][]DeviceDetail)
+	for _, dev := range devices {
+		if dev.ClientUUID != "" {
+			groups[dev.ClientUUID] = append(groups[dev.ClientUUID], dev)
+		}
+	}
+
+	var duplicates []string
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			return lastSeen[group[i].ID].After(lastSeen[group[j].ID])
+		})
+		for _, dev := range group[1:] {
+			duplicates = append(duplicates, dev.ID)
+		}
+	}
+	return duplicates
+}
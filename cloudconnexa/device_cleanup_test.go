@@ -0,0 +1,134 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newCleanupTestServer(t *testing.T, deleted *[]string) *httptest.Server {
+	now := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/devices":
+			_ = json.NewEncoder(w).Encode(DevicePageResponse{
+				Content: []DeviceDetail{
+					{ID: "d-active", Name: "active", UserID: "u-1", ClientUUID: "uuid-1"},
+					{ID: "d-stale", Name: "stale", UserID: "u-1"},
+					{ID: "d-online", Name: "online", UserID: "u-1", ConnectionStatus: "ONLINE"},
+					{ID: "d-dup", Name: "dup", UserID: "u-1", ClientUUID: "uuid-1"},
+					{ID: "d-suspended", Name: "suspended", UserID: "u-2"},
+				},
+				TotalPages: 1,
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/users":
+			_ = json.NewEncoder(w).Encode(UserPageResponse{
+				Content: []User{
+					{ID: "u-1", Username: "alice", Status: UserStatusActive},
+					{ID: "u-2", Username: "bob", Status: UserStatusSuspended},
+				},
+				TotalPages: 1,
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/sessions":
+			if got := r.URL.Query().Get("startDate"); got != now.Add(-30*24*time.Hour).Format(time.RFC3339) {
+				t.Errorf("Unexpected startDate %s", got)
+			}
+			_ = json.NewEncoder(w).Encode(SessionsResponse{Sessions: []Session{
+				{SessionID: "s-1", DeviceID: "d-active", StartDateTime: now.Add(-time.Hour)},
+				{SessionID: "s-2", DeviceID: "d-dup", StartDateTime: now.Add(-48 * time.Hour)},
+				{SessionID: "s-3", DeviceID: "d-suspended", StartDateTime: now.Add(-time.Hour)},
+			}})
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v1/devices/"):
+			mu.Lock()
+			*deleted = append(*deleted, strings.TrimPrefix(r.URL.Path, "/api/v1/devices/"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestDevicesService_Cleanup_DryRun(t *testing.T) {
+	var deleted []string
+	server := newCleanupTestServer(t, &deleted)
+	defer server.Close()
+
+	client := createTestProvisioningClient(server)
+	client.Sessions = (*SessionsService)(&service{client: client})
+
+	var audit bytes.Buffer
+	report, err := client.Devices.Cleanup(DeviceCleanupPolicy{
+		StaleAfter:           30 * 24 * time.Hour,
+		SuspendedUsers:       true,
+		DuplicateClientUUIDs: true,
+		Action:               DeviceCleanupActionDelete,
+		Now:                  time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+	}, &audit)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := map[string]DeviceCleanupReason{
+		"d-stale":     DeviceCleanupReasonStale,
+		"d-dup":       DeviceCleanupReasonDuplicateClientUUID,
+		"d-suspended": DeviceCleanupReasonSuspendedUser,
+	}
+	if len(report.Findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %+v", len(want), report.Findings)
+	}
+	for _, f := range report.Findings {
+		if len(f.Reasons) != 1 || f.Reasons[0] != want[f.DeviceID] {
+			t.Errorf("Unexpected reasons for %s: %v", f.DeviceID, f.Reasons)
+		}
+		if f.Applied {
+			t.Errorf("Expected no action in dry-run mode for %s", f.DeviceID)
+		}
+	}
+	if len(deleted) != 0 {
+		t.Errorf("Expected no deletes in dry-run mode, got %v", deleted)
+	}
+	if lines := strings.Count(audit.String(), "\n"); lines != 3 {
+		t.Errorf("Expected 3 audit lines, got %d", lines)
+	}
+}
+
+func TestDevicesService_Cleanup_Enforce(t *testing.T) {
+	var deleted []string
+	server := newCleanupTestServer(t, &deleted)
+	defer server.Close()
+
+	client := createTestProvisioningClient(server)
+
+	report, err := client.Devices.Cleanup(DeviceCleanupPolicy{
+		SuspendedUsers: true,
+		Action:         DeviceCleanupActionDelete,
+		Enforce:        true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Findings) != 1 || !report.Findings[0].Applied {
+		t.Fatalf("Expected one applied finding, got %+v", report.Findings)
+	}
+	if len(deleted) != 1 || deleted[0] != "d-suspended" {
+		t.Errorf("Expected d-suspended to be deleted, got %v", deleted)
+	}
+}
+
+func TestDevicesService_Cleanup_UnknownAction(t *testing.T) {
+	client := createTestProvisioningClient(httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("Expected no HTTP call for an invalid policy")
+	})))
+
+	if _, err := client.Devices.Cleanup(DeviceCleanupPolicy{Action: "wipe"}, nil); err == nil {
+		t.Error("Expected error for unknown action, got nil")
+	}
+}
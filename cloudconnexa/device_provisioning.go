@@ -0,0 +1,324 @@
+package cloudconnexa
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidProvisionSpec is returned for a provisioning spec that is missing
+// a username, device name or region ID.
+var ErrInvalidProvisionSpec = errors.New("invalid device provision spec")
+
+// DeviceProvisionSpec describes a single user/device pair to provision.
+// The user is matched by Username and created from User when it does not exist yet.
+// The device is matched by name among the user's devices and created when missing.
+type DeviceProvisionSpec struct {
+	User     User                `json:"user"`
+	Device   DeviceCreateRequest `json:"device"`
+	RegionID string              `json:"regionId"`
+	// FileName overrides the profile file name. Defaults to "<username>-<device>.ovpn".
+	FileName string `json:"fileName,omitempty"`
+}
+
+// DeviceProvisionOptions configures DevicesService.Provision.
+type DeviceProvisionOptions struct {
+	// Concurrency is the number of users provisioned in parallel. Defaults to 4.
+	// All requests still go through the client's rate limiters.
+	Concurrency int
+	// Overwrite regenerates profiles that already exist in the ProfileWriter.
+	// By default existing profiles are left untouched so that re-runs are idempotent.
+	Overwrite bool
+}
+
+// DeviceProvisionResult reports the outcome of provisioning a single spec.
+type DeviceProvisionResult struct {
+	Username       string `json:"username"`
+	UserID         string `json:"userId,omitempty"`
+	DeviceName     string `json:"deviceName"`
+	DeviceID       string `json:"deviceId,omitempty"`
+	FileName       string `json:"fileName,omitempty"`
+	UserCreated    bool   `json:"userCreated"`
+	DeviceCreated  bool   `json:"deviceCreated"`
+	ProfileWritten bool   `json:"profileWritten"`
+	Error          string `json:"error,omitempty"`
+	Err            error  `json:"-"`
+}
+
+// ProfileWriter receives generated .ovpn profiles.
+type ProfileWriter interface {
+	WriteProfile(name string, profile []byte) error
+}
+
+// ProfileChecker is implemented by ProfileWriters that can tell whether a
+// profile was already written, which lets Provision skip regenerating it.
+type ProfileChecker interface {
+	HasProfile(name string) (bool, error)
+}
+
+// Provision creates missing users and devices and writes one .ovpn profile per spec.
+// Specs for different users run concurrently; specs for the same user run in order
+// so that the user is created only once. Existing users and devices are reused,
+// making repeated runs with the same specs idempotent.
+// Per-spec failures are reported in the results; the returned error is only set
+// when the run could not start or ctx was cancelled.
+func (d *DevicesService) Provision(ctx context.Context, specs []DeviceProvisionSpec, w ProfileWriter, opts *DeviceProvisionOptions) ([]DeviceProvisionResult, error) {
+	if w == nil {
+		return nil, errors.New("profile writer is required")
+	}
+	if opts == nil {
+		opts = &DeviceProvisionOptions{}
+	}
+
+	existing, err := d.client.Users.List()
+	if err != nil {
+		return nil, err
+	}
+	usersByName := make(map[string]User, len(existing))
+	for _, u := range existing {
+		usersByName[u.Username] = u
+	}
+
+	results := make([]DeviceProvisionResult, len(specs))
+	var order []string
+	byUser := make(map[string][]int)
+	for i, spec := range specs {
+		results[i] = DeviceProvisionResult{
+			Username:   spec.User.Username,
+			DeviceName: spec.Device.Name,
+			FileName:   profileFileName(spec),
+		}
+		if err := validateProvisionSpec(spec); err != nil {
+			results[i].setErr(err)
+			continue
+		}
+		if _, ok := byUser[spec.User.Username]; !ok {
+			order = append(order, spec.User.Username)
+		}
+		byUser[spec.User.Username] = append(byUser[spec.User.Username], i)
+	}
+
+	started := make([]bool, len(order))
+	err = forEachConcurrent(ctx, len(order), opts.Concurrency, func(n int) {
+		started[n] = true
+		indexes := byUser[order[n]]
+		user, ok := usersByName[order[n]]
+		var devices map[string]DeviceDetail
+		for _, i := range indexes {
+			if ctx.Err() != nil {
+				results[i].setErr(ctx.Err())
+				continue
+			}
+			r := &results[i]
+			if !ok {
+				created, err := d.client.Users.Create(specs[i].User)
+				if err != nil {
+					r.setErr(fmt.Errorf("create user: %w", err))
+					continue
+				}
+				user, ok = *created, true
+				r.UserCreated = true
+			}
+			r.UserID = user.ID
+
+			if devices == nil {
+				list, err := d.ListByUserID(user.ID)
+				if err != nil {
+					r.setErr(fmt.Errorf("list devices: %w", err))
+					continue
+				}
+				devices = make(map[string]DeviceDetail, len(list))
+				for _, dev := range list {
+					devices[dev.Name] = dev
+				}
+			}
+			d.provisionDevice(specs[i], user.ID, devices, w, opts, r)
+		}
+	})
+	// Users not started before ctx was cancelled were never provisioned.
+	for n, username := range order {
+		if started[n] {
+			continue
+		}
+		for _, i := range byUser[username] {
+			results[i].setErr(ctx.Err())
+		}
+	}
+	return results, err
+}
+
+// provisionDevice ensures the device from spec exists and writes its profile.
+func (d *DevicesService) provisionDevice(spec DeviceProvisionSpec, userID string, devices map[string]DeviceDetail, w ProfileWriter, opts *DeviceProvisionOptions, r *DeviceProvisionResult) {
+	device, ok := devices[spec.Device.Name]
+	if !ok {
+		created, err := d.Create(userID, spec.Device)
+		if err != nil {
+			r.setErr(fmt.Errorf("create device: %w", err))
+			return
+		}
+		device = *created
+		devices[device.Name] = device
+		r.DeviceCreated = true
+	}
+	r.DeviceID = device.ID
+
+	if checker, ok := w.(ProfileChecker); ok && !opts.Overwrite && !r.DeviceCreated {
+		exists, err := checker.HasProfile(r.FileName)
+		if err != nil {
+			r.setErr(err)
+			return
+		}
+		if exists {
+			return
+		}
+	}
+
+	profile, err := d.GenerateProfile(userID, device.ID, spec.RegionID)
+	if err != nil {
+		r.setErr(fmt.Errorf("generate profile: %w", err))
+		return
+	}
+	if err := w.WriteProfile(r.FileName, []byte(profile)); err != nil {
+		r.setErr(fmt.Errorf("write profile: %w", err))
+		return
+	}
+	r.ProfileWritten = true
+}
+
+func (r *DeviceProvisionResult) setErr(err error) {
+	r.Err = err
+	r.Error = err.Error()
+}
+
+func validateProvisionSpec(spec DeviceProvisionSpec) error {
+	switch {
+	case spec.User.Username == "":
+		return fmt.Errorf("%w: username is required", ErrInvalidProvisionSpec)
+	case spec.Device.Name == "":
+		return fmt.Errorf("%w: device name is required", ErrInvalidProvisionSpec)
+	case spec.RegionID == "":
+		return fmt.Errorf("%w: region ID is required", ErrInvalidProvisionSpec)
+	}
+	return nil
+}
+
+// profileFileName returns the file name for a spec's profile, stripped of any
+// path separators so that writers cannot be pointed outside their target.
+func profileFileName(spec DeviceProvisionSpec) string {
+	name := spec.FileName
+	if name == "" {
+		name = spec.User.Username + "-" + spec.Device.Name + ".ovpn"
+	}
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if name == "." || name == ".." {
+		name = "_"
+	}
+	return name
+}
+
+// DirProfileWriter writes profiles as individual files in a directory.
+type DirProfileWriter struct {
+	dir string
+}
+
+// NewDirProfileWriter returns a ProfileWriter that stores profiles in dir,
+// creating the directory if needed. Profiles contain private keys, so files
+// are created with owner-only permissions.
+func NewDirProfileWriter(dir string) (*DirProfileWriter, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &DirProfileWriter{dir: dir}, nil
+}
+
+// WriteProfile writes the profile to <dir>/<name>.
+func (p *DirProfileWriter) WriteProfile(name string, profile []byte) error {
+	return os.WriteFile(filepath.Join(p.dir, name), profile, 0o600)
+}
+
+// HasProfile reports whether <dir>/<name> already exists.
+func (p *DirProfileWriter) HasProfile(name string) (bool, error) {
+	_, err := os.Stat(filepath.Join(p.dir, name))
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
+
+// TarProfileWriter writes profiles as entries of a tar archive.
+// It is safe for concurrent use. Close must be called to flush the archive.
+type TarProfileWriter struct {
+	mu sync.Mutex
+	tw *tar.Writer
+}
+
+// NewTarProfileWriter returns a ProfileWriter that writes a tar archive to w.
+func NewTarProfileWriter(w io.Writer) *TarProfileWriter {
+	return &TarProfileWriter{tw: tar.NewWriter(w)}
+}
+
+// WriteProfile adds the profile to the archive.
+func (p *TarProfileWriter) WriteProfile(name string, profile []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(profile)),
+		ModTime: time.Now(),
+	}
+	if err := p.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := p.tw.Write(profile)
+	return err
+}
+
+// Close finishes the archive. It does not close the underlying writer.
+func (p *TarProfileWriter) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.tw.Close()
+}
+
+// ZipProfileWriter writes profiles as entries of a zip archive.
+// It is safe for concurrent use. Close must be called to flush the archive.
+type ZipProfileWriter struct {
+	mu sync.Mutex
+	zw *zip.Writer
+}
+
+// NewZipProfileWriter returns a ProfileWriter that writes a zip archive to w.
+func NewZipProfileWriter(w io.Writer) *ZipProfileWriter {
+	return &ZipProfileWriter{zw: zip.NewWriter(w)}
+}
+
+// WriteProfile adds the profile to the archive.
+func (p *ZipProfileWriter) WriteProfile(name string, profile []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f, err := p.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(profile)
+	return err
+}
+
+// Close finishes the archive. It does not close the underlying writer.
+func (p *ZipProfileWriter) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.zw.Close()
+}
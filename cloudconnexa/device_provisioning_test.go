@@ -0,0 +1,197 @@
+package cloudconnexa
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+// provisioningAPI is a minimal in-memory users/devices API used by the provisioning tests.
+type provisioningAPI struct {
+	mu       sync.Mutex
+	users    []User
+	devices  []DeviceDetail
+	profiles int
+}
+
+func (a *provisioningAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/users":
+		_ = json.NewEncoder(w).Encode(UserPageResponse{Content: a.users, TotalPages: 1})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/users":
+		var u User
+		_ = json.NewDecoder(r.Body).Decode(&u)
+		u.ID = "user-" + u.Username
+		a.users = append(a.users, u)
+		_ = json.NewEncoder(w).Encode(u)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/devices":
+		var content []DeviceDetail
+		for _, d := range a.devices {
+			if d.UserID == r.URL.Query().Get("userId") {
+				content = append(content, d)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(DevicePageResponse{Content: content, TotalPages: 1})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/devices":
+		var req DeviceCreateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		d := DeviceDetail{ID: "device-" + req.Name, Name: req.Name, UserID: r.URL.Query().Get("userId")}
+		a.devices = append(a.devices, d)
+		_ = json.NewEncoder(w).Encode(d)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/profile"):
+		a.profiles++
+		_, _ = w.Write([]byte("client\n# " + r.URL.Path + "\n"))
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+	}
+}
+
+func createTestProvisioningClient(server *httptest.Server) *Client {
+	client := &Client{
+		client:            server.Client(),
+		BaseURL:           server.URL,
+		Token:             "test-token",
+		ReadRateLimiter:   rate.NewLimiter(rate.Every(1), 5),
+		UpdateRateLimiter: rate.NewLimiter(rate.Every(1), 5),
+	}
+	client.Users = (*UsersService)(&service{client: client})
+	client.Devices = (*DevicesService)(&service{client: client})
+	return client
+}
+
+func TestDevicesService_Provision(t *testing.T) {
+	api := &provisioningAPI{users: []User{{ID: "user-alice", Username: "alice"}}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client := createTestProvisioningClient(server)
+	dir := t.TempDir()
+	writer, err := NewDirProfileWriter(dir)
+	if err != nil {
+		t.Fatalf("NewDirProfileWriter failed: %v", err)
+	}
+
+	specs := []DeviceProvisionSpec{
+		{User: User{Username: "alice"}, Device: DeviceCreateRequest{Name: "laptop"}, RegionID: "eu"},
+		{User: User{Username: "bob"}, Device: DeviceCreateRequest{Name: "laptop"}, RegionID: "eu"},
+		{User: User{Username: "bob"}, Device: DeviceCreateRequest{Name: "phone"}, RegionID: "eu"},
+		{User: User{Username: "carol"}, Device: DeviceCreateRequest{Name: "laptop"}},
+	}
+
+	results, err := client.Devices.Provision(context.Background(), specs, writer, &DeviceProvisionOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != len(specs) {
+		t.Fatalf("Expected %d results, got %d", len(specs), len(results))
+	}
+
+	if results[0].UserCreated || !results[0].DeviceCreated || !results[0].ProfileWritten {
+		t.Errorf("Unexpected result for existing user: %+v", results[0])
+	}
+	if !results[1].UserCreated || results[2].UserCreated {
+		t.Errorf("Expected bob to be created exactly once, got %+v and %+v", results[1], results[2])
+	}
+	if !errors.Is(results[3].Err, ErrInvalidProvisionSpec) {
+		t.Errorf("Expected ErrInvalidProvisionSpec for spec without region, got %v", results[3].Err)
+	}
+	if len(api.users) != 2 {
+		t.Errorf("Expected 2 users in API, got %d", len(api.users))
+	}
+
+	for _, name := range []string{"alice-laptop.ovpn", "bob-laptop.ovpn", "bob-phone.ovpn"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected profile %s to be written: %v", name, err)
+		}
+	}
+
+	// A second run must not create anything or regenerate existing profiles.
+	results, err = client.Devices.Provision(context.Background(), specs[:3], writer, nil)
+	if err != nil {
+		t.Fatalf("Expected no error on re-run, got %v", err)
+	}
+	for _, r := range results {
+		if r.UserCreated || r.DeviceCreated || r.ProfileWritten || r.Err != nil {
+			t.Errorf("Expected no-op re-run, got %+v", r)
+		}
+	}
+	if api.profiles != 3 {
+		t.Errorf("Expected 3 generated profiles, got %d", api.profiles)
+	}
+}
+
+func TestDevicesService_Provision_Cancelled(t *testing.T) {
+	api := &provisioningAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client := createTestProvisioningClient(server)
+	writer, err := NewDirProfileWriter(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDirProfileWriter failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	specs := []DeviceProvisionSpec{
+		{User: User{Username: "alice"}, Device: DeviceCreateRequest{Name: "laptop"}, RegionID: "eu"},
+		{User: User{Username: "bob"}, Device: DeviceCreateRequest{Name: "laptop"}, RegionID: "eu"},
+		{User: User{Username: "bob"}, Device: DeviceCreateRequest{Name: "phone"}, RegionID: "eu"},
+	}
+	results, err := client.Devices.Provision(ctx, specs, writer, &DeviceProvisionOptions{Concurrency: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) || r.Error == "" || r.ProfileWritten {
+			t.Errorf("Expected every spec to report the cancellation, got %+v", r)
+		}
+	}
+	if len(api.users) != 0 || len(api.devices) != 0 || api.profiles != 0 {
+		t.Errorf("Expected no writes, got %d users, %d devices, %d profiles", len(api.users), len(api.devices), api.profiles)
+	}
+}
+
+func TestDevicesService_Provision_ZipArchive(t *testing.T) {
+	server := httptest.NewServer(&provisioningAPI{})
+	defer server.Close()
+
+	client := createTestProvisioningClient(server)
+	var buf bytes.Buffer
+	writer := NewZipProfileWriter(&buf)
+
+	specs := []DeviceProvisionSpec{
+		{User: User{Username: "dave"}, Device: DeviceCreateRequest{Name: "../escape"}, RegionID: "us"},
+	}
+	results, err := client.Devices.Provision(context.Background(), specs, writer, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if results[0].Err != nil {
+		t.Fatalf("Expected success, got %v", results[0].Err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Reading archive failed: %v", err)
+	}
+	if len(zr.File) != 1 || zr.File[0].Name != "dave-.._escape.ovpn" {
+		t.Errorf("Unexpected archive contents: %+v", zr.File)
+	}
+}
@@ -0,0 +1,550 @@
+package cloudconnexa
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrInvalidLDIF is returned when an LDIF export cannot be parsed.
+	ErrInvalidLDIF = errors.New("invalid LDIF")
+	// ErrInvalidDirectorySyncConfig is returned for a directory sync config that cannot be applied.
+	ErrInvalidDirectorySyncConfig = errors.New("invalid directory sync config")
+	// ErrDirectorySyncAborted is returned when a directory sync plan exceeds a safety threshold.
+	ErrDirectorySyncAborted = errors.New("directory sync aborted")
+)
+
+// DirectoryUser is a user of a directory export. Groups lists the names of
+// the directory groups the user belongs to.
+type DirectoryUser struct {
+	Username  string   `json:"username"`
+	Email     string   `json:"email,omitempty"`
+	FirstName string   `json:"firstName,omitempty"`
+	LastName  string   `json:"lastName,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+}
+
+// DirectoryGroup is a group of a directory export whose members are usernames.
+type DirectoryGroup struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// Directory is a directory export. Group membership may be given on users,
+// on groups, or both.
+type Directory struct {
+	Users  []DirectoryUser  `json:"users"`
+	Groups []DirectoryGroup `json:"groups,omitempty"`
+}
+
+// ReadDirectoryJSON reads a directory export in JSON form.
+func ReadDirectoryJSON(r io.Reader) (*Directory, error) {
+	var dir Directory
+	if err := json.NewDecoder(r).Decode(&dir); err != nil {
+		return nil, err
+	}
+	return &dir, nil
+}
+
+// ReadDirectoryFile reads a directory export from an .ldif or .json file.
+func ReadDirectoryFile(path string) (*Directory, error) {
+	f, err := os.Open(path) //nolint:gosec // path is chosen by the caller
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ldif":
+		return ReadLDIF(f)
+	case ".json":
+		return ReadDirectoryJSON(f)
+	default:
+		return nil, fmt.Errorf("cannot infer directory format from %q", path)
+	}
+}
+
+type ldifEntry struct {
+	dn    string
+	attrs map[string][]string
+}
+
+func (e ldifEntry) first(names ...string) string {
+	for _, name := range names {
+		if values := e.attrs[name]; len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func (e ldifEntry) hasClass(classes ...string) bool {
+	for _, class := range e.attrs["objectclass"] {
+		if slices.Contains(classes, strings.ToLower(class)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadLDIF reads users and groups from an LDIF export (RFC 2849). Users are
+// entries of class person, organizationalPerson, inetOrgPerson, user or
+// posixAccount, named by uid, sAMAccountName or mail. Groups are entries of
+// class groupOfNames, groupOfUniqueNames, group or posixGroup, named by cn.
+// Membership is taken from member, uniqueMember, memberUid and memberOf.
+func ReadLDIF(r io.Reader) (*Directory, error) {
+	entries, err := parseLDIF(r)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := &Directory{}
+	usernames := map[string]string{}  // normalized DN -> username
+	groupNames := map[string]string{} // normalized DN -> group name
+	var users, groups []ldifEntry
+	for _, e := range entries {
+		switch {
+		case e.hasClass("groupofnames", "groupofuniquenames", "group", "posixgroup"):
+			if name := e.first("cn"); name != "" {
+				groupNames[normalizeDN(e.dn)] = name
+				groups = append(groups, e)
+			}
+		case e.hasClass("person", "organizationalperson", "inetorgperson", "user", "posixaccount"):
+			if username := e.first("uid", "samaccountname", "mail"); username != "" {
+				usernames[normalizeDN(e.dn)] = username
+				users = append(users, e)
+			}
+		}
+	}
+
+	for _, e := range users {
+		user := DirectoryUser{
+			Username:  e.first("uid", "samaccountname", "mail"),
+			Email:     e.first("mail"),
+			FirstName: e.first("givenname"),
+			LastName:  e.first("sn"),
+		}
+		for _, dn := range e.attrs["memberof"] {
+			name, ok := groupNames[normalizeDN(dn)]
+			if !ok {
+				name = firstRDNValue(dn)
+			}
+			user.Groups = appendUnique(user.Groups, name)
+		}
+		dir.Users = append(dir.Users, user)
+	}
+	for _, e := range groups {
+		group := DirectoryGroup{Name: e.first("cn")}
+		for _, dn := range append(slices.Clone(e.attrs["member"]), e.attrs["uniquemember"]...) {
+			if username, ok := usernames[normalizeDN(dn)]; ok {
+				group.Members = appendUnique(group.Members, username)
+			}
+		}
+		for _, uid := range e.attrs["memberuid"] {
+			group.Members = appendUnique(group.Members, uid)
+		}
+		dir.Groups = append(dir.Groups, group)
+	}
+	return dir, nil
+}
+
+// parseLDIF splits an LDIF stream into entries with lower-cased attribute names.
+func parseLDIF(r io.Reader) ([]ldifEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var entries []ldifEntry
+	var lines []string
+	lineNo := 0
+	flush := func() error {
+		if len(lines) == 0 {
+			return nil
+		}
+		entry := ldifEntry{attrs: map[string][]string{}}
+		for _, line := range lines {
+			name, value, err := parseLDIFLine(line)
+			if err != nil {
+				return fmt.Errorf("%w: line %d: %v", ErrInvalidLDIF, lineNo, err)
+			}
+			if name == "dn" {
+				entry.dn = value
+				continue
+			}
+			entry.attrs[name] = append(entry.attrs[name], value)
+		}
+		lines = nil
+		if entry.dn != "" {
+			entries = append(entries, entry)
+		}
+		return nil
+	}
+
+	comment := false
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		switch {
+		case strings.HasPrefix(line, " "):
+			if !comment && len(lines) > 0 {
+				lines[len(lines)-1] += line[1:]
+			}
+		case line == "":
+			comment = false
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "#"):
+			comment = true
+		case len(lines) == 0 && strings.HasPrefix(strings.ToLower(line), "version:"):
+			comment = false
+		default:
+			comment = false
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func parseLDIFLine(line string) (name, value string, err error) {
+	name, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", fmt.Errorf("missing colon in %q", line)
+	}
+	// Attribute options such as cn;lang-en are ignored.
+	name, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(name)), ";")
+	switch {
+	case strings.HasPrefix(value, ":"):
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+		if err != nil {
+			return "", "", fmt.Errorf("attribute %s: %v", name, err)
+		}
+		return name, string(decoded), nil
+	case strings.HasPrefix(value, "<"):
+		return "", "", fmt.Errorf("attribute %s: URL values are not supported", name)
+	default:
+		return name, strings.TrimSpace(value), nil
+	}
+}
+
+// normalizeDN lower-cases a DN and removes spaces around separators.
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		attr, value, _ := strings.Cut(part, "=")
+		parts[i] = strings.ToLower(strings.TrimSpace(attr)) + "=" + strings.ToLower(strings.TrimSpace(value))
+	}
+	return strings.Join(parts, ",")
+}
+
+// firstRDNValue returns the value of the first RDN of dn, e.g. "vpn" for "cn=vpn,ou=groups,dc=example,dc=com".
+func firstRDNValue(dn string) string {
+	rdn, _, _ := strings.Cut(dn, ",")
+	if _, value, ok := strings.Cut(rdn, "="); ok {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(dn)
+}
+
+// DirectoryGroupMapping maps a directory group to a CloudConnexa user group by name.
+type DirectoryGroupMapping struct {
+	// DirectoryGroup is the directory group name, or a DN whose first RDN value is the name.
+	DirectoryGroup string `json:"directoryGroup"`
+	UserGroup      string `json:"userGroup"`
+}
+
+// DirectorySyncConfig configures a directory sync.
+//
+// Example:
+//
+//	{"groups": [
+//	  {"directoryGroup": "vpn-admins", "userGroup": "Admins"},
+//	  {"directoryGroup": "cn=vpn-users,ou=groups,dc=example,dc=com", "userGroup": "Employees"}
+//	 ],
+//	 "protectedUsers": ["owner@example.com"],
+//	 "maxSuspendPercent": 10}
+type DirectorySyncConfig struct {
+	// Groups maps directory groups to user groups in priority order. A user's
+	// first matching mapping determines its GroupID; further matches become
+	// SecondaryGroupIDs.
+	Groups []DirectoryGroupMapping `json:"groups"`
+	// DefaultUserGroup is assigned to directory users without a mapped group.
+	// If empty, such users are not synced and treated as removed.
+	DefaultUserGroup string `json:"defaultUserGroup,omitempty"`
+	// Role is the role of created users. Defaults to "MEMBER".
+	Role string `json:"role,omitempty"`
+	// AuthType is the authentication type of created users. If empty it is left to the API.
+	AuthType string `json:"authType,omitempty"`
+	// ProtectedUsers lists usernames that are never modified or suspended.
+	ProtectedUsers []string `json:"protectedUsers,omitempty"`
+	// MaxSuspend aborts the sync if more users would be suspended. Zero disables the check.
+	MaxSuspend int `json:"maxSuspend,omitempty"`
+	// MaxSuspendPercent aborts the sync if more than this percentage of the
+	// active managed users would be suspended. Zero disables the check.
+	MaxSuspendPercent float64 `json:"maxSuspendPercent,omitempty"`
+	// AllowEmptyDirectory permits syncing an export without users, which
+	// otherwise aborts because it would suspend every managed user.
+	AllowEmptyDirectory bool `json:"allowEmptyDirectory,omitempty"`
+	// DryRun computes the plan without applying it.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// LoadDirectorySyncConfig reads a JSON directory sync config. Unknown fields are rejected.
+func LoadDirectorySyncConfig(r io.Reader) (*DirectorySyncConfig, error) {
+	var cfg DirectorySyncConfig
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDirectorySyncConfig, err)
+	}
+	return &cfg, nil
+}
+
+// DirectoryUserUpdate is a change to an existing user.
+type DirectoryUserUpdate struct {
+	Current User `json:"current"`
+	Desired User `json:"desired"`
+}
+
+// DirectorySyncPlan lists the changes that make users match a directory.
+type DirectorySyncPlan struct {
+	Creates   []User                `json:"creates"`
+	Updates   []DirectoryUserUpdate `json:"updates"`
+	Activates []User                `json:"activates"`
+	Suspends  []User                `json:"suspends"`
+	Unchanged int                   `json:"unchanged"`
+	// Unmapped lists directory users without a mapped group, which are not synced.
+	Unmapped []string `json:"unmapped,omitempty"`
+}
+
+// HasChanges reports whether applying the plan would modify any user.
+func (p *DirectorySyncPlan) HasChanges() bool {
+	return len(p.Creates)+len(p.Updates)+len(p.Activates)+len(p.Suspends) > 0
+}
+
+// PlanDirectorySync computes the changes that make existing users match dir.
+//
+// Managed users are the directory users with a mapped group and the existing
+// users belonging to a mapped user group. Managed users missing from the
+// directory are suspended, returning users are activated, and the managed
+// groups of every synced user are set from the mappings; memberships in
+// unmapped user groups are kept as secondary groups.
+//
+// If the plan exceeds a safety threshold it is returned together with an
+// error wrapping ErrDirectorySyncAborted.
+func PlanDirectorySync(existing []User, groups []UserGroup, dir *Directory, cfg DirectorySyncConfig) (*DirectorySyncPlan, error) {
+	groupIDs := make(map[string]string, len(groups))
+	for _, g := range groups {
+		groupIDs[g.Name] = g.ID
+	}
+	type mapping struct{ directoryGroup, groupID string }
+	var mappings []mapping
+	managed := map[string]bool{}
+	for _, m := range cfg.Groups {
+		id, ok := groupIDs[m.UserGroup]
+		if !ok || m.DirectoryGroup == "" {
+			return nil, fmt.Errorf("%w: unknown user group %q or empty directory group", ErrInvalidDirectorySyncConfig, m.UserGroup)
+		}
+		name := m.DirectoryGroup
+		if strings.Contains(name, "=") {
+			name = firstRDNValue(name)
+		}
+		mappings = append(mappings, mapping{directoryGroup: strings.ToLower(name), groupID: id})
+		managed[id] = true
+	}
+	defaultID := ""
+	if cfg.DefaultUserGroup != "" {
+		id, ok := groupIDs[cfg.DefaultUserGroup]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown default user group %q", ErrInvalidDirectorySyncConfig, cfg.DefaultUserGroup)
+		}
+		defaultID = id
+		managed[id] = true
+	}
+	if len(managed) == 0 {
+		return nil, fmt.Errorf("%w: no group mappings", ErrInvalidDirectorySyncConfig)
+	}
+	if len(dir.Users) == 0 && !cfg.AllowEmptyDirectory {
+		return nil, fmt.Errorf("%w: directory export has no users", ErrDirectorySyncAborted)
+	}
+
+	memberships := map[string][]string{}
+	for _, u := range dir.Users {
+		key := strings.ToLower(u.Username)
+		for _, g := range u.Groups {
+			memberships[key] = appendUnique(memberships[key], strings.ToLower(g))
+		}
+	}
+	for _, g := range dir.Groups {
+		for _, member := range g.Members {
+			key := strings.ToLower(member)
+			memberships[key] = appendUnique(memberships[key], strings.ToLower(g.Name))
+		}
+	}
+	protected := map[string]bool{}
+	for _, name := range cfg.ProtectedUsers {
+		protected[strings.ToLower(name)] = true
+	}
+	existingByName := make(map[string]User, len(existing))
+	for _, u := range existing {
+		existingByName[strings.ToLower(u.Username)] = u
+	}
+
+	plan := &DirectorySyncPlan{}
+	synced := map[string]bool{}
+	for _, du := range dir.Users {
+		key := strings.ToLower(du.Username)
+		if du.Username == "" || synced[key] {
+			continue
+		}
+		var desiredGroups []string
+		for _, m := range mappings {
+			if slices.Contains(memberships[key], m.directoryGroup) {
+				desiredGroups = appendUnique(desiredGroups, m.groupID)
+			}
+		}
+		if len(desiredGroups) == 0 && defaultID != "" {
+			desiredGroups = []string{defaultID}
+		}
+		if len(desiredGroups) == 0 {
+			plan.Unmapped = append(plan.Unmapped, du.Username)
+			continue
+		}
+		synced[key] = true
+		if protected[key] {
+			continue
+		}
+
+		current, exists := existingByName[key]
+		if !exists {
+			role := cfg.Role
+			if role == "" {
+				role = "MEMBER"
+			}
+			plan.Creates = append(plan.Creates, User{
+				Username:          du.Username,
+				Email:             du.Email,
+				FirstName:         du.FirstName,
+				LastName:          du.LastName,
+				Role:              role,
+				AuthType:          cfg.AuthType,
+				GroupID:           desiredGroups[0],
+				SecondaryGroupIDs: desiredGroups[1:],
+			})
+			continue
+		}
+
+		desired := current
+		desired.Devices = nil
+		desired.GroupID = desiredGroups[0]
+		desired.SecondaryGroupIDs = slices.Clone(desiredGroups[1:])
+		for _, id := range append([]string{current.GroupID}, current.SecondaryGroupIDs...) {
+			if id != "" && !managed[id] {
+				desired.SecondaryGroupIDs = appendUnique(desired.SecondaryGroupIDs, id)
+			}
+		}
+		for _, f := range []struct {
+			dst *string
+			src string
+		}{{&desired.Email, du.Email}, {&desired.FirstName, du.FirstName}, {&desired.LastName, du.LastName}} {
+			if f.src != "" {
+				*f.dst = f.src
+			}
+		}
+
+		changed := false
+		if desired.GroupID != current.GroupID || !slices.Equal(sortedCopy(desired.SecondaryGroupIDs), sortedCopy(current.SecondaryGroupIDs)) ||
+			desired.Email != current.Email || desired.FirstName != current.FirstName || desired.LastName != current.LastName {
+			plan.Updates = append(plan.Updates, DirectoryUserUpdate{Current: current, Desired: desired})
+			changed = true
+		}
+		if current.Status == UserStatusSuspended {
+			plan.Activates = append(plan.Activates, current)
+			changed = true
+		}
+		if !changed {
+			plan.Unchanged++
+		}
+	}
+
+	activeManaged := 0
+	for _, u := range existing {
+		key := strings.ToLower(u.Username)
+		inManagedGroup := managed[u.GroupID] || slices.ContainsFunc(u.SecondaryGroupIDs, func(id string) bool { return managed[id] })
+		if u.Status == UserStatusSuspended || (!inManagedGroup && !synced[key]) {
+			continue
+		}
+		activeManaged++
+		if !synced[key] && !protected[key] {
+			plan.Suspends = append(plan.Suspends, u)
+		}
+	}
+
+	suspends := len(plan.Suspends)
+	if cfg.MaxSuspend > 0 && suspends > cfg.MaxSuspend {
+		return plan, fmt.Errorf("%w: %d users would be suspended, limit is %d", ErrDirectorySyncAborted, suspends, cfg.MaxSuspend)
+	}
+	if cfg.MaxSuspendPercent > 0 && activeManaged > 0 {
+		if percent := 100 * float64(suspends) / float64(activeManaged); percent > cfg.MaxSuspendPercent {
+			return plan, fmt.Errorf("%w: %d of %d active managed users (%.1f%%) would be suspended, limit is %.1f%%",
+				ErrDirectorySyncAborted, suspends, activeManaged, percent, cfg.MaxSuspendPercent)
+		}
+	}
+	return plan, nil
+}
+
+// SyncDirectory makes users match dir. It lists users and user groups,
+// computes a plan with PlanDirectorySync and, unless cfg.DryRun is set or a
+// safety threshold is exceeded, applies creations, updates, activations and
+// finally suspensions. The plan is returned in either case; if an operation
+// fails, the error identifies it and later operations are not attempted.
+func (c *UsersService) SyncDirectory(dir *Directory, cfg DirectorySyncConfig) (*DirectorySyncPlan, error) {
+	existing, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := c.client.UserGroups.List()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := PlanDirectorySync(existing, groups, dir, cfg)
+	if err != nil || cfg.DryRun {
+		return plan, err
+	}
+
+	for i, user := range plan.Creates {
+		created, err := c.Create(user)
+		if err != nil {
+			return plan, fmt.Errorf("creating %s: %w", user.Username, err)
+		}
+		plan.Creates[i] = *created
+	}
+	for _, update := range plan.Updates {
+		if err := c.Update(update.Desired); err != nil {
+			return plan, fmt.Errorf("updating %s: %w", update.Desired.Username, err)
+		}
+	}
+	for _, user := range plan.Activates {
+		if err := c.Activate(user.ID); err != nil {
+			return plan, fmt.Errorf("activating %s: %w", user.Username, err)
+		}
+	}
+	for _, user := range plan.Suspends {
+		if err := c.Suspend(user.ID); err != nil {
+			return plan, fmt.Errorf("suspending %s: %w", user.Username, err)
+		}
+	}
+	return plan, nil
+}
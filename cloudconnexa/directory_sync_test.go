@@ -0,0 +1,210 @@
+package cloudconnexa
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testLDIF = `version: 1
+
+# Groups may precede their members.
+dn: cn=vpn-admins,ou=groups,dc=example,dc=com
+objectClass: groupOfNames
+cn: vpn-admins
+member: uid=alice,ou=people,dc=example,dc=com
+member: uid=nobody,ou=people,dc=example,dc=com
+
+dn: cn=VPN Users,ou=groups,dc=example,dc=com
+objectClass: posixGroup
+cn: VPN Users
+memberUid: bob
+
+dn: uid=alice,ou=people,dc=example,dc=com
+objectClass: top
+objectClass: inetOrgPerson
+uid: alice
+mail: alice@example.com
+givenName: Alice
+sn: Smith
+memberOf: CN=VPN Users, OU=groups, DC=example, DC=com
+
+dn: uid=bob,ou=people,dc=example,dc=com
+objectClass: inetOrgPerson
+uid: bob
+mail: bob@exam
+ ple.com
+sn:: QsO2aG0=
+
+dn: ou=people,dc=example,dc=com
+objectClass: organizationalUnit
+ou: people
+`
+
+func TestReadLDIF(t *testing.T) {
+	dir, err := ReadLDIF(strings.NewReader(testLDIF))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := &Directory{
+		Users: []DirectoryUser{
+			{Username: "alice", Email: "alice@example.com", FirstName: "Alice", LastName: "Smith", Groups: []string{"VPN Users"}},
+			{Username: "bob", Email: "bob@example.com", LastName: "Böhm"},
+		},
+		Groups: []DirectoryGroup{
+			{Name: "vpn-admins", Members: []string{"alice"}},
+			{Name: "VPN Users", Members: []string{"bob"}},
+		},
+	}
+	if !reflect.DeepEqual(dir, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, dir)
+	}
+
+	if _, err := ReadLDIF(strings.NewReader("dn: cn=x\nobjectClass person\n")); !errors.Is(err, ErrInvalidLDIF) {
+		t.Errorf("Expected ErrInvalidLDIF, got %v", err)
+	}
+}
+
+func TestLoadDirectorySyncConfig(t *testing.T) {
+	cfg, err := LoadDirectorySyncConfig(strings.NewReader(`{"groups":[{"directoryGroup":"vpn","userGroup":"Employees"}],"maxSuspend":5}`))
+	if err != nil || len(cfg.Groups) != 1 || cfg.MaxSuspend != 5 {
+		t.Errorf("Unexpected config %+v, error %v", cfg, err)
+	}
+	if _, err := LoadDirectorySyncConfig(strings.NewReader(`{"group":[]}`)); !errors.Is(err, ErrInvalidDirectorySyncConfig) {
+		t.Errorf("Expected ErrInvalidDirectorySyncConfig, got %v", err)
+	}
+}
+
+var testSyncGroups = []UserGroup{{ID: "ug-admins", Name: "Admins"}, {ID: "ug-emp", Name: "Employees"}, {ID: "ug-other", Name: "Contractors"}}
+
+var testSyncConfig = DirectorySyncConfig{
+	Groups: []DirectoryGroupMapping{
+		{DirectoryGroup: "vpn-admins", UserGroup: "Admins"},
+		{DirectoryGroup: "cn=VPN Users,ou=groups,dc=example,dc=com", UserGroup: "Employees"},
+	},
+	ProtectedUsers: []string{"owner"},
+}
+
+func testSyncUsers() []User {
+	return []User{
+		{ID: "u-alice", Username: "alice", Email: "alice@example.com", FirstName: "Alice", LastName: "Smith", GroupID: "ug-other", SecondaryGroupIDs: []string{"ug-emp"}, Status: UserStatusActive},
+		{ID: "u-carol", Username: "carol", GroupID: "ug-emp", Status: UserStatusActive},
+		{ID: "u-dave", Username: "dave", GroupID: "ug-emp", Status: UserStatusSuspended},
+		{ID: "u-erin", Username: "Erin", GroupID: "ug-emp", Status: UserStatusSuspended},
+		{ID: "u-owner", Username: "owner", GroupID: "ug-admins", Status: UserStatusActive},
+		{ID: "u-frank", Username: "frank", GroupID: "ug-other", Status: UserStatusActive},
+	}
+}
+
+func TestPlanDirectorySync(t *testing.T) {
+	dir, _ := ReadLDIF(strings.NewReader(testLDIF))
+	dir.Users = append(dir.Users, DirectoryUser{Username: "erin", Groups: []string{"VPN Users"}}, DirectoryUser{Username: "grace"})
+
+	plan, err := PlanDirectorySync(testSyncUsers(), testSyncGroups, dir, testSyncConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(plan.Creates) != 1 || plan.Creates[0].Username != "bob" || plan.Creates[0].GroupID != "ug-emp" || plan.Creates[0].Role != "MEMBER" {
+		t.Errorf("Unexpected creates %+v", plan.Creates)
+	}
+	if len(plan.Updates) != 1 {
+		t.Fatalf("Expected one update, got %+v", plan.Updates)
+	}
+	alice := plan.Updates[0].Desired
+	if alice.GroupID != "ug-admins" || !reflect.DeepEqual(alice.SecondaryGroupIDs, []string{"ug-emp", "ug-other"}) {
+		t.Errorf("Expected alice to be moved to Admins keeping Contractors, got %+v", alice)
+	}
+	if len(plan.Activates) != 1 || plan.Activates[0].ID != "u-erin" {
+		t.Errorf("Unexpected activates %+v", plan.Activates)
+	}
+	if len(plan.Suspends) != 1 || plan.Suspends[0].ID != "u-carol" {
+		t.Errorf("Unexpected suspends %+v", plan.Suspends)
+	}
+	if !reflect.DeepEqual(plan.Unmapped, []string{"grace"}) || plan.Unchanged != 0 {
+		t.Errorf("Unexpected unmapped %v, unchanged %d", plan.Unmapped, plan.Unchanged)
+	}
+}
+
+func TestPlanDirectorySync_Thresholds(t *testing.T) {
+	dir := &Directory{Users: []DirectoryUser{{Username: "alice", Groups: []string{"vpn-admins"}}}}
+
+	cfg := testSyncConfig
+	cfg.MaxSuspend = 1
+	plan, err := PlanDirectorySync(testSyncUsers(), testSyncGroups, dir, cfg)
+	if err != nil || len(plan.Suspends) != 1 {
+		t.Errorf("Expected no abort for one suspension, got %v", err)
+	}
+
+	// alice, carol and owner are active managed users; suspending carol is 33%.
+	cfg = testSyncConfig
+	cfg.MaxSuspendPercent = 30
+	if _, err := PlanDirectorySync(testSyncUsers(), testSyncGroups, dir, cfg); !errors.Is(err, ErrDirectorySyncAborted) {
+		t.Errorf("Expected percentage abort, got %v", err)
+	}
+	cfg.MaxSuspendPercent = 50
+	if _, err := PlanDirectorySync(testSyncUsers(), testSyncGroups, dir, cfg); err != nil {
+		t.Errorf("Expected no abort, got %v", err)
+	}
+
+	if _, err := PlanDirectorySync(testSyncUsers(), testSyncGroups, &Directory{}, testSyncConfig); !errors.Is(err, ErrDirectorySyncAborted) {
+		t.Errorf("Expected empty directory abort, got %v", err)
+	}
+	cfg = testSyncConfig
+	cfg.Groups = append(cfg.Groups, DirectoryGroupMapping{DirectoryGroup: "x", UserGroup: "Missing"})
+	if _, err := PlanDirectorySync(testSyncUsers(), testSyncGroups, dir, cfg); !errors.Is(err, ErrInvalidDirectorySyncConfig) {
+		t.Errorf("Expected ErrInvalidDirectorySyncConfig, got %v", err)
+	}
+}
+
+func TestUsersService_SyncDirectory(t *testing.T) {
+	var mu sync.Mutex
+	var writes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/users":
+			_ = json.NewEncoder(w).Encode(UserPageResponse{Content: testSyncUsers(), TotalPages: 1})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/user-groups":
+			_ = json.NewEncoder(w).Encode(UserGroupPageResponse{Content: testSyncGroups, TotalPages: 1})
+		case r.Method == http.MethodPost:
+			var u User
+			_ = json.NewDecoder(r.Body).Decode(&u)
+			writes = append(writes, "POST "+u.Username)
+			u.ID = "u-" + u.Username
+			_ = json.NewEncoder(w).Encode(u)
+		default:
+			writes = append(writes, r.Method+" "+r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client := createTestClientWithServices(server)
+
+	dir, _ := ReadLDIF(strings.NewReader(testLDIF))
+	dir.Users = append(dir.Users, DirectoryUser{Username: "erin", Groups: []string{"VPN Users"}})
+
+	cfg := testSyncConfig
+	cfg.DryRun = true
+	if _, err := client.Users.SyncDirectory(dir, cfg); err != nil || len(writes) != 0 {
+		t.Fatalf("Expected a dry run without writes, got %v, %v", writes, err)
+	}
+
+	cfg.DryRun = false
+	cfg.MaxSuspend = 1
+	plan, err := client.Users.SyncDirectory(dir, cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := "POST bob,PUT /api/v1/users/u-alice,PUT /api/v1/users/u-erin/activate,PUT /api/v1/users/u-carol/suspend"
+	if got := strings.Join(writes, ","); got != expected {
+		t.Errorf("Expected writes %s, got %s", expected, got)
+	}
+	if plan.Creates[0].ID != "u-bob" {
+		t.Errorf("Expected created user ID to be recorded, got %+v", plan.Creates[0])
+	}
+}
@@ -0,0 +1,291 @@
+package cloudconnexa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DNSRecordSource provides the authoritative set of DNS records for DNSRecordsService.Reconcile.
+type DNSRecordSource interface {
+	DNSRecords() ([]DNSRecord, error)
+}
+
+// DNSRecordSourceFunc adapts a function to a DNSRecordSource.
+type DNSRecordSourceFunc func() ([]DNSRecord, error)
+
+// DNSRecords calls f.
+func (f DNSRecordSourceFunc) DNSRecords() ([]DNSRecord, error) {
+	return f()
+}
+
+// DNSRecordMap is a DNSRecordSource mapping domains to IPv4 and IPv6 addresses.
+type DNSRecordMap map[string][]string
+
+// DNSRecords returns one record per domain with its addresses split by family.
+func (m DNSRecordMap) DNSRecords() ([]DNSRecord, error) {
+	records := make([]DNSRecord, 0, len(m))
+	for domain, addresses := range m {
+		record := DNSRecord{Domain: domain}
+		for _, address := range addresses {
+			addr, err := netip.ParseAddr(address)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid address %q", domain, address)
+			}
+			if addr.Is4() {
+				record.IPV4Addresses = append(record.IPV4Addresses, addr.String())
+			} else {
+				record.IPV6Addresses = append(record.IPV6Addresses, addr.String())
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// DNSRecordFile is a DNSRecordSource reading a file: a JSON array of DNSRecord
+// if the path ends in .json, a zone file otherwise.
+type DNSRecordFile struct {
+	Path string
+	// Origin resolves relative names in zone files.
+	Origin string
+}
+
+// DNSRecords reads and parses the file.
+func (f DNSRecordFile) DNSRecords() ([]DNSRecord, error) {
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	if strings.EqualFold(filepath.Ext(f.Path), ".json") {
+		var records []DNSRecord
+		if err := json.NewDecoder(file).Decode(&records); err != nil {
+			return nil, err
+		}
+		return records, nil
+	}
+	zone, err := ParseZoneFile(file, f.Origin)
+	if err != nil {
+		return nil, err
+	}
+	return zone.Records, nil
+}
+
+// DNSReconcileOptions configures DNS record reconciliation.
+type DNSReconcileOptions struct {
+	// ManagedPrefix limits reconciliation to records whose domain starts with it.
+	// Other existing records are never modified and other source records are skipped.
+	ManagedPrefix string
+	// ProtectTag protects existing records whose description contains it from being updated or deleted.
+	ProtectTag string
+	// Description is set on created records.
+	Description string
+	// DryRun computes the plan without applying it.
+	DryRun bool
+}
+
+// DNSRecordUpdate is a planned change of an existing record's addresses.
+type DNSRecordUpdate struct {
+	Current DNSRecord `json:"current"`
+	Desired DNSRecord `json:"desired"`
+}
+
+// DNSReconcilePlan lists the operations needed to make the existing records match the source.
+type DNSReconcilePlan struct {
+	Creates []DNSRecord       `json:"creates"`
+	Updates []DNSRecordUpdate `json:"updates"`
+	Deletes []DNSRecord       `json:"deletes"`
+	// Protected lists existing records that differ from the source but are left
+	// alone because of ProtectTag or ManagedPrefix.
+	Protected []DNSRecord `json:"protected"`
+	// Skipped lists source records outside ManagedPrefix.
+	Skipped   []DNSRecord `json:"skipped"`
+	Unchanged int         `json:"unchanged"`
+}
+
+// HasChanges reports whether applying the plan would modify any record.
+func (p *DNSReconcilePlan) HasChanges() bool {
+	return len(p.Creates) > 0 || len(p.Updates) > 0 || len(p.Deletes) > 0
+}
+
+// PlanDNSReconcile computes the operations that make existing match desired.
+// Records are matched by case-insensitive domain. Desired records with the same
+// domain are merged, and surplus existing records with a duplicated domain are deleted.
+func PlanDNSReconcile(existing, desired []DNSRecord, opts DNSReconcileOptions) *DNSReconcilePlan {
+	plan := &DNSReconcilePlan{
+		Creates:   []DNSRecord{},
+		Updates:   []DNSRecordUpdate{},
+		Deletes:   []DNSRecord{},
+		Protected: []DNSRecord{},
+		Skipped:   []DNSRecord{},
+	}
+	managed := func(domain string) bool {
+		return strings.HasPrefix(domain, strings.ToLower(opts.ManagedPrefix))
+	}
+	protected := func(record DNSRecord) bool {
+		return !managed(normalizeDNSDomain(record.Domain)) ||
+			(opts.ProtectTag != "" && strings.Contains(record.Description, opts.ProtectTag))
+	}
+
+	wanted := map[string]*DNSRecord{}
+	var domains []string
+	for _, record := range desired {
+		domain := normalizeDNSDomain(record.Domain)
+		if !managed(domain) {
+			plan.Skipped = append(plan.Skipped, record)
+			continue
+		}
+		merged, ok := wanted[domain]
+		if !ok {
+			merged = &DNSRecord{Domain: domain, IPV4Addresses: []string{}, IPV6Addresses: []string{}}
+			wanted[domain] = merged
+			domains = append(domains, domain)
+		}
+		merged.IPV4Addresses = mergeAddresses(merged.IPV4Addresses, record.IPV4Addresses)
+		merged.IPV6Addresses = mergeAddresses(merged.IPV6Addresses, record.IPV6Addresses)
+	}
+
+	matched := map[string]bool{}
+	for _, current := range existing {
+		domain := normalizeDNSDomain(current.Domain)
+		target, ok := wanted[domain]
+		switch {
+		case !ok || matched[domain]:
+			// Not in the source, or a duplicate of a record already matched.
+			if protected(current) {
+				if managed(domain) {
+					plan.Protected = append(plan.Protected, current)
+				}
+				continue
+			}
+			plan.Deletes = append(plan.Deletes, current)
+		case sameAddresses(current.IPV4Addresses, target.IPV4Addresses) && sameAddresses(current.IPV6Addresses, target.IPV6Addresses):
+			matched[domain] = true
+			plan.Unchanged++
+		default:
+			matched[domain] = true
+			if protected(current) {
+				plan.Protected = append(plan.Protected, current)
+				continue
+			}
+			updated := current
+			updated.IPV4Addresses = target.IPV4Addresses
+			updated.IPV6Addresses = target.IPV6Addresses
+			plan.Updates = append(plan.Updates, DNSRecordUpdate{Current: current, Desired: updated})
+		}
+	}
+
+	sort.Strings(domains)
+	for _, domain := range domains {
+		if !matched[domain] {
+			record := *wanted[domain]
+			record.Description = opts.Description
+			plan.Creates = append(plan.Creates, record)
+		}
+	}
+	return plan
+}
+
+// Reconcile makes the DNS records match source. It lists the existing records,
+// computes a plan with PlanDNSReconcile and, unless opts.DryRun is set, applies
+// creations, then updates, then deletions. The plan is returned in either case;
+// if an operation fails, the error identifies it and later operations are not attempted.
+func (c *DNSRecordsService) Reconcile(source DNSRecordSource, opts DNSReconcileOptions) (*DNSReconcilePlan, error) {
+	desired, err := source.DNSRecords()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := PlanDNSReconcile(existing, desired, opts)
+	if opts.DryRun {
+		return plan, nil
+	}
+	for i, record := range plan.Creates {
+		created, err := c.Create(record)
+		if err != nil {
+			return plan, fmt.Errorf("creating %s: %w", record.Domain, err)
+		}
+		plan.Creates[i] = *created
+	}
+	for _, update := range plan.Updates {
+		if err := c.Update(update.Desired); err != nil {
+			return plan, fmt.Errorf("updating %s: %w", update.Desired.Domain, err)
+		}
+	}
+	for _, record := range plan.Deletes {
+		if err := c.Delete(record.ID); err != nil {
+			return plan, fmt.Errorf("deleting %s: %w", record.Domain, err)
+		}
+	}
+	return plan, nil
+}
+
+// GetByDomain returns the first DNS record with the given domain, compared case-insensitively.
+func (c *DNSRecordsService) GetByDomain(domain string) (*DNSRecord, error) {
+	records, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	domain = normalizeDNSDomain(domain)
+	for _, record := range records {
+		if normalizeDNSDomain(record.Domain) == domain {
+			return &record, nil
+		}
+	}
+	return nil, ErrDNSRecordNotFound
+}
+
+// UpsertByDomain updates the addresses of the record with record.Domain, or creates
+// it if there is none, so that callers do not need to know the record ID. An update
+// keeps the other fields of the existing record, such as its description, and an
+// address list left nil keeps its current addresses; pass an empty slice to clear it.
+func (c *DNSRecordsService) UpsertByDomain(record DNSRecord) (*DNSRecord, error) {
+	current, err := c.GetByDomain(record.Domain)
+	if errors.Is(err, ErrDNSRecordNotFound) {
+		return c.Create(record)
+	}
+	if err != nil {
+		return nil, err
+	}
+	updated := *current
+	if record.IPV4Addresses != nil {
+		updated.IPV4Addresses = record.IPV4Addresses
+	}
+	if record.IPV6Addresses != nil {
+		updated.IPV6Addresses = record.IPV6Addresses
+	}
+	if err := c.Update(updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// normalizeDNSDomain returns domain in lower case without a trailing dot.
+func normalizeDNSDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, "."))
+}
+
+// mergeAddresses appends the addresses of add missing from addresses.
+func mergeAddresses(addresses, add []string) []string {
+	for _, address := range add {
+		if addr, err := netip.ParseAddr(address); err == nil {
+			address = addr.String()
+		}
+		if !slices.Contains(addresses, address) {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,158 @@
+package cloudconnexa
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanDNSReconcile(t *testing.T) {
+	existing := []DNSRecord{
+		{ID: "1", Domain: "app.corp.example", IPV4Addresses: []string{"10.0.0.1"}},
+		{ID: "2", Domain: "db.corp.example", IPV4Addresses: []string{"10.0.0.2"}},
+		{ID: "3", Domain: "old.corp.example", IPV4Addresses: []string{"10.0.0.3"}},
+		{ID: "4", Domain: "vpn.corp.example", Description: "[keep]", IPV4Addresses: []string{"10.0.0.4"}},
+		{ID: "5", Domain: "APP.corp.example.", IPV4Addresses: []string{"10.0.0.1"}},
+		{ID: "6", Domain: "mail.other.example", IPV4Addresses: []string{"10.0.0.6"}},
+	}
+	desired := []DNSRecord{
+		{Domain: "app.corp.example", IPV4Addresses: []string{"10.0.0.1"}},
+		{Domain: "db.corp.example", IPV4Addresses: []string{"10.0.0.20"}},
+		{Domain: "db.corp.example", IPV6Addresses: []string{"fd00::20"}},
+		{Domain: "new.corp.example", IPV4Addresses: []string{"10.0.0.7"}},
+		{Domain: "vpn.corp.example", IPV4Addresses: []string{"10.0.0.40"}},
+		{Domain: "www.other.example", IPV4Addresses: []string{"10.0.0.8"}},
+	}
+
+	plan := PlanDNSReconcile(existing, desired, DNSReconcileOptions{ManagedPrefix: "", ProtectTag: "[keep]", Description: "synced"})
+
+	if plan.Unchanged != 1 {
+		t.Errorf("Expected 1 unchanged record, got %d", plan.Unchanged)
+	}
+	if len(plan.Updates) != 1 || plan.Updates[0].Desired.ID != "2" ||
+		plan.Updates[0].Desired.IPV4Addresses[0] != "10.0.0.20" || plan.Updates[0].Desired.IPV6Addresses[0] != "fd00::20" {
+		t.Errorf("Expected merged update of db, got %+v", plan.Updates)
+	}
+	var deleted []string
+	for _, record := range plan.Deletes {
+		deleted = append(deleted, record.ID)
+	}
+	if strings.Join(deleted, ",") != "3,5,6" {
+		t.Errorf("Expected deletion of old, duplicate app and mail, got %v", deleted)
+	}
+	if len(plan.Protected) != 1 || plan.Protected[0].ID != "4" {
+		t.Errorf("Expected vpn to be protected, got %+v", plan.Protected)
+	}
+	if len(plan.Creates) != 2 || plan.Creates[0].Domain != "new.corp.example" || plan.Creates[0].Description != "synced" {
+		t.Errorf("Unexpected creates %+v", plan.Creates)
+	}
+}
+
+func TestPlanDNSReconcile_ManagedPrefix(t *testing.T) {
+	existing := []DNSRecord{
+		{ID: "1", Domain: "k8s-api.corp.example", IPV4Addresses: []string{"10.0.0.1"}},
+		{ID: "2", Domain: "printer.corp.example", IPV4Addresses: []string{"10.0.0.2"}},
+	}
+	desired := []DNSRecord{
+		{Domain: "k8s-web.corp.example", IPV4Addresses: []string{"10.0.0.3"}},
+		{Domain: "printer.corp.example", IPV4Addresses: []string{"10.0.0.9"}},
+	}
+
+	plan := PlanDNSReconcile(existing, desired, DNSReconcileOptions{ManagedPrefix: "k8s-"})
+
+	if len(plan.Deletes) != 1 || plan.Deletes[0].ID != "1" {
+		t.Errorf("Expected only the managed record to be deleted, got %+v", plan.Deletes)
+	}
+	if len(plan.Creates) != 1 || len(plan.Updates) != 0 || len(plan.Skipped) != 1 {
+		t.Errorf("Unexpected plan %+v", plan)
+	}
+}
+
+func TestDNSRecordsService_Reconcile(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(DNSRecordPageResponse{
+				Content: []DNSRecord{
+					{ID: "1", Domain: "a.example", IPV4Addresses: []string{"10.0.0.1"}},
+					{ID: "2", Domain: "b.example", IPV4Addresses: []string{"10.0.0.2"}},
+				},
+				TotalPages: 1,
+			})
+		case http.MethodPost:
+			_, _ = w.Write([]byte(`{"id":"3","domain":"c.example"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := createTestDNSClient(server)
+	source := DNSRecordMap{"a.example": {"10.0.0.10"}, "c.example": {"10.0.0.3", "fd00::3"}}
+
+	plan, err := client.DNSRecords.Reconcile(source, DNSReconcileOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !plan.HasChanges() || len(requests) != 1 {
+		t.Fatalf("Expected a plan without writes, got %+v after %v", plan, requests)
+	}
+
+	requests = nil
+	plan, err = client.DNSRecords.Reconcile(source, DNSReconcileOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "GET /api/v1/dns-records,POST /api/v1/dns-records,PUT /api/v1/dns-records/1,DELETE /api/v1/dns-records/2"
+	if strings.Join(requests, ",") != want {
+		t.Errorf("Expected requests %s, got %v", want, requests)
+	}
+	if plan.Creates[0].ID != "3" {
+		t.Errorf("Expected created record ID to be recorded, got %+v", plan.Creates[0])
+	}
+}
+
+func TestDNSRecordFile_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.json")
+	if err := os.WriteFile(path, []byte(`[{"domain":"a.example","ipv4Addresses":["10.0.0.1"]}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	records, err := DNSRecordFile{Path: path}.DNSRecords()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(records) != 1 || records[0].IPV4Addresses[0] != "10.0.0.1" {
+		t.Errorf("Unexpected records %+v", records)
+	}
+}
+
+func TestDNSRecordsService_UpsertByDomain(t *testing.T) {
+	var updated DNSRecord
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			_ = json.NewDecoder(r.Body).Decode(&updated)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(DNSRecordPageResponse{
+			Content:    []DNSRecord{{ID: "rec-1", Domain: "a.example", Description: "keep", IPV6Addresses: []string{"fd00::1"}}},
+			TotalPages: 1,
+		})
+	}))
+	defer server.Close()
+
+	client := createTestDNSClient(server)
+	record, err := client.DNSRecords.UpsertByDomain(DNSRecord{Domain: "A.example.", IPV4Addresses: []string{"10.0.0.1"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if record.ID != "rec-1" || updated.ID != "rec-1" {
+		t.Errorf("Expected update of rec-1, got %+v", updated)
+	}
+	if updated.Description != "keep" || len(updated.IPV6Addresses) != 1 || len(updated.IPV4Addresses) != 1 {
+		t.Errorf("Expected the description and IPv6 addresses to be kept, got %+v", updated)
+	}
+}
@@ -0,0 +1,376 @@
+package cloudconnexa
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidZoneFile is returned when a zone file cannot be parsed.
+var ErrInvalidZoneFile = errors.New("invalid zone file")
+
+// ZoneRecordSkip describes a zone file entry that could not be imported.
+type ZoneRecordSkip struct {
+	Line   int    `json:"line"`
+	Domain string `json:"domain,omitempty"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// ZoneFile is the result of parsing a zone file: A and AAAA records merged by
+// domain, and every entry that was skipped.
+type ZoneFile struct {
+	Records []DNSRecord      `json:"records"`
+	Skipped []ZoneRecordSkip `json:"skipped"`
+}
+
+// zoneLine is a logical zone file line: parentheses are joined and comments removed.
+type zoneLine struct {
+	number        int
+	tokens        []string
+	inheritsOwner bool
+}
+
+var zoneTTLPattern = regexp.MustCompile(`^[0-9]+([smhdwSMHDW]([0-9]+[smhdwSMHDW])*)?$`)
+
+var zoneClasses = map[string]bool{"IN": true, "CH": true, "HS": true, "CS": true}
+
+// ParseZoneFile parses an RFC 1035 master file. Relative names are resolved
+// against origin, which may be overridden by $ORIGIN directives. Domains are
+// returned lower-case without the trailing dot.
+//
+// Only A and AAAA records are imported. Other record types, unsupported
+// directives and records with invalid addresses are reported in Skipped.
+func ParseZoneFile(r io.Reader, origin string) (*ZoneFile, error) {
+	lines, err := readZoneLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	origin = strings.ToLower(strings.TrimSuffix(origin, "."))
+	zone := &ZoneFile{Records: []DNSRecord{}, Skipped: []ZoneRecordSkip{}}
+	byDomain := map[string]*DNSRecord{}
+	var order []string
+	owner := ""
+
+	for _, line := range lines {
+		tokens := line.tokens
+		if strings.HasPrefix(tokens[0], "$") && !line.inheritsOwner {
+			directive := strings.ToUpper(tokens[0])
+			switch directive {
+			case "$ORIGIN":
+				if len(tokens) < 2 {
+					return nil, fmt.Errorf("%w: line %d: $ORIGIN requires a domain", ErrInvalidZoneFile, line.number)
+				}
+				origin = resolveZoneName(tokens[1], origin)
+			case "$TTL":
+				// CloudConnexa records have no TTL.
+			default:
+				zone.Skipped = append(zone.Skipped, ZoneRecordSkip{Line: line.number, Type: directive, Reason: "unsupported directive"})
+			}
+			continue
+		}
+
+		if !line.inheritsOwner {
+			owner = resolveZoneName(tokens[0], origin)
+			tokens = tokens[1:]
+		} else if owner == "" {
+			return nil, fmt.Errorf("%w: line %d: record without owner name", ErrInvalidZoneFile, line.number)
+		}
+
+		// TTL and class may appear in either order before the type.
+		for len(tokens) > 0 && (zoneTTLPattern.MatchString(tokens[0]) || zoneClasses[strings.ToUpper(tokens[0])]) {
+			tokens = tokens[1:]
+		}
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("%w: line %d: missing record type", ErrInvalidZoneFile, line.number)
+		}
+		recordType := strings.ToUpper(tokens[0])
+		rdata := tokens[1:]
+
+		skip := func(reason string) {
+			zone.Skipped = append(zone.Skipped, ZoneRecordSkip{Line: line.number, Domain: owner, Type: recordType, Reason: reason})
+		}
+		if recordType != "A" && recordType != "AAAA" {
+			skip("unsupported record type")
+			continue
+		}
+		if len(rdata) != 1 {
+			skip("expected exactly one address")
+			continue
+		}
+		addr, err := netip.ParseAddr(rdata[0])
+		if err != nil || (recordType == "A") != addr.Is4() {
+			skip(fmt.Sprintf("invalid %s address %q", recordType, rdata[0]))
+			continue
+		}
+
+		record, ok := byDomain[owner]
+		if !ok {
+			record = &DNSRecord{Domain: owner, IPV4Addresses: []string{}, IPV6Addresses: []string{}}
+			byDomain[owner] = record
+			order = append(order, owner)
+		}
+		if recordType == "A" {
+			if !slices.Contains(record.IPV4Addresses, addr.String()) {
+				record.IPV4Addresses = append(record.IPV4Addresses, addr.String())
+			}
+		} else if !slices.Contains(record.IPV6Addresses, addr.String()) {
+			record.IPV6Addresses = append(record.IPV6Addresses, addr.String())
+		}
+	}
+
+	for _, domain := range order {
+		zone.Records = append(zone.Records, *byDomain[domain])
+	}
+	return zone, nil
+}
+
+// readZoneLines splits a zone file into logical lines, removing comments and
+// joining lines continued with parentheses. Quoted strings are kept as single tokens.
+func readZoneLines(r io.Reader) ([]zoneLine, error) {
+	var lines []zoneLine
+	var current *zoneLine
+	depth := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := scanner.Text()
+		if depth == 0 {
+			if current != nil && len(current.tokens) > 0 {
+				lines = append(lines, *current)
+			}
+			current = &zoneLine{number: number, inheritsOwner: text != "" && (text[0] == ' ' || text[0] == '\t')}
+		}
+
+		var token strings.Builder
+		inToken, quoted := false, false
+		flush := func() {
+			if inToken {
+				current.tokens = append(current.tokens, token.String())
+				token.Reset()
+				inToken = false
+			}
+		}
+	scan:
+		for i := 0; i < len(text); i++ {
+			ch := text[i]
+			switch {
+			case ch == '\\' && i+1 < len(text):
+				token.WriteByte(ch)
+				token.WriteByte(text[i+1])
+				inToken = true
+				i++
+			case quoted:
+				token.WriteByte(ch)
+				if ch == '"' {
+					quoted = false
+				}
+			case ch == '"':
+				token.WriteByte(ch)
+				inToken, quoted = true, true
+			case ch == ';':
+				break scan
+			case ch == '(':
+				flush()
+				depth++
+			case ch == ')':
+				flush()
+				if depth == 0 {
+					return nil, fmt.Errorf("%w: line %d: unbalanced parenthesis", ErrInvalidZoneFile, number)
+				}
+				depth--
+			case ch == ' ' || ch == '\t':
+				flush()
+			default:
+				token.WriteByte(ch)
+				inToken = true
+			}
+		}
+		if quoted {
+			return nil, fmt.Errorf("%w: line %d: unterminated string", ErrInvalidZoneFile, number)
+		}
+		flush()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("%w: unbalanced parenthesis at end of file", ErrInvalidZoneFile)
+	}
+	if current != nil && len(current.tokens) > 0 {
+		lines = append(lines, *current)
+	}
+	return lines, nil
+}
+
+// resolveZoneName returns the absolute, lower-case form of name without the trailing dot.
+func resolveZoneName(name, origin string) string {
+	name = strings.ToLower(name)
+	switch {
+	case name == "@":
+		return origin
+	case strings.HasSuffix(name, "."):
+		return strings.TrimSuffix(name, ".")
+	case origin == "":
+		return name
+	default:
+		return name + "." + origin
+	}
+}
+
+// ZoneFileOptions configures WriteZoneFile.
+type ZoneFileOptions struct {
+	// Origin is written as $ORIGIN, and names below it are written relative to it.
+	// If empty, all names are written fully qualified.
+	Origin string
+	// TTL is written as $TTL when positive.
+	TTL int
+}
+
+// WriteZoneFile writes records as A and AAAA entries of an RFC 1035 master file,
+// sorted by domain. Record descriptions are written as comments.
+func WriteZoneFile(w io.Writer, records []DNSRecord, opts ZoneFileOptions) error {
+	origin := strings.ToLower(strings.TrimSuffix(opts.Origin, "."))
+	bw := bufio.NewWriter(w)
+	if origin != "" {
+		fmt.Fprintf(bw, "$ORIGIN %s.\n", origin)
+	}
+	if opts.TTL > 0 {
+		fmt.Fprintf(bw, "$TTL %d\n", opts.TTL)
+	}
+
+	sorted := slices.Clone(records)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Domain < sorted[j].Domain })
+	for _, record := range sorted {
+		name := zoneOwnerName(record.Domain, origin)
+		if record.Description != "" {
+			fmt.Fprintf(bw, "; %s\n", strings.Join(strings.Fields(record.Description), " "))
+		}
+		for _, ip := range record.IPV4Addresses {
+			fmt.Fprintf(bw, "%s\tIN\tA\t%s\n", name, ip)
+		}
+		for _, ip := range record.IPV6Addresses {
+			fmt.Fprintf(bw, "%s\tIN\tAAAA\t%s\n", name, ip)
+		}
+	}
+	return bw.Flush()
+}
+
+func zoneOwnerName(domain, origin string) string {
+	domain = normalizeDNSDomain(domain)
+	switch {
+	case origin == "":
+		return domain + "."
+	case domain == origin:
+		return "@"
+	case strings.HasSuffix(domain, "."+origin):
+		return strings.TrimSuffix(domain, "."+origin)
+	default:
+		return domain + "."
+	}
+}
+
+// ZoneImportOptions configures DNSRecordsService.ImportZoneFile.
+type ZoneImportOptions struct {
+	// Origin resolves relative names until the file sets $ORIGIN.
+	Origin string
+	// Description is set on created records.
+	Description string
+	// DryRun computes the result without creating or updating records.
+	DryRun bool
+}
+
+// ZoneImportResult describes the outcome of DNSRecordsService.ImportZoneFile.
+type ZoneImportResult struct {
+	Created   []DNSRecord      `json:"created"`
+	Updated   []DNSRecord      `json:"updated"`
+	Unchanged []DNSRecord      `json:"unchanged"`
+	Skipped   []ZoneRecordSkip `json:"skipped"`
+}
+
+// ImportZoneFile parses a zone file and creates a DNS record for every domain it
+// defines. Existing records with the same domain are updated to the addresses in
+// the file, keeping their description. Unsupported entries are skipped and reported.
+// On an API error the records processed so far are returned together with the error.
+func (c *DNSRecordsService) ImportZoneFile(r io.Reader, opts ZoneImportOptions) (*ZoneImportResult, error) {
+	zone, err := ParseZoneFile(r, opts.Origin)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	byDomain := map[string]DNSRecord{}
+	for _, record := range existing {
+		byDomain[normalizeDNSDomain(record.Domain)] = record
+	}
+
+	result := &ZoneImportResult{Skipped: zone.Skipped}
+	for _, record := range zone.Records {
+		current, ok := byDomain[record.Domain]
+		if !ok {
+			record.Description = opts.Description
+			if !opts.DryRun {
+				created, err := c.Create(record)
+				if err != nil {
+					return result, fmt.Errorf("creating %s: %w", record.Domain, err)
+				}
+				record = *created
+			}
+			result.Created = append(result.Created, record)
+			continue
+		}
+
+		if sameAddresses(current.IPV4Addresses, record.IPV4Addresses) && sameAddresses(current.IPV6Addresses, record.IPV6Addresses) {
+			result.Unchanged = append(result.Unchanged, current)
+			continue
+		}
+		current.IPV4Addresses = record.IPV4Addresses
+		current.IPV6Addresses = record.IPV6Addresses
+		if !opts.DryRun {
+			if err := c.Update(current); err != nil {
+				return result, fmt.Errorf("updating %s: %w", record.Domain, err)
+			}
+		}
+		result.Updated = append(result.Updated, current)
+	}
+	return result, nil
+}
+
+// ExportZoneFile writes all DNS records to w in zone file format.
+func (c *DNSRecordsService) ExportZoneFile(w io.Writer, opts ZoneFileOptions) error {
+	records, err := c.List()
+	if err != nil {
+		return err
+	}
+	return WriteZoneFile(w, records, opts)
+}
+
+// sameAddresses reports whether a and b contain the same addresses, ignoring order and formatting.
+func sameAddresses(a, b []string) bool {
+	normalize := func(addresses []string) []string {
+		result := make([]string, 0, len(addresses))
+		for _, address := range addresses {
+			if addr, err := netip.ParseAddr(address); err == nil {
+				address = addr.String()
+			}
+			if !slices.Contains(result, address) {
+				result = append(result, address)
+			}
+		}
+		sort.Strings(result)
+		return result
+	}
+	return slices.Equal(normalize(a), normalize(b))
+}
@@ -0,0 +1,163 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testZoneFile = `$ORIGIN example.com.
+$TTL 3600
+@       IN  SOA ns1.example.com. admin.example.com. (
+                2024010101 ; serial
+                7200 3600 1209600 3600 )
+@       IN  NS   ns1
+@       IN  A    192.0.2.1
+www     300 IN A 192.0.2.10 ; web
+        IN  A    192.0.2.11
+        IN  AAAA 2001:DB8::10
+mail    IN  MX   10 mail.example.com.
+txt     IN  TXT  "v=spf1 ; not a comment"
+bad     IN  A    2001:db8::1
+ext.other.org. IN A 198.51.100.7
+$INCLUDE other.zone
+`
+
+func TestParseZoneFile(t *testing.T) {
+	zone, err := ParseZoneFile(strings.NewReader(testZoneFile), "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(zone.Records) != 3 {
+		t.Fatalf("Expected 3 records, got %+v", zone.Records)
+	}
+	www := zone.Records[1]
+	if www.Domain != "www.example.com" || len(www.IPV4Addresses) != 2 || www.IPV6Addresses[0] != "2001:db8::10" {
+		t.Errorf("Unexpected record %+v", www)
+	}
+	if zone.Records[0].Domain != "example.com" || zone.Records[2].Domain != "ext.other.org" {
+		t.Errorf("Unexpected domains %+v", zone.Records)
+	}
+
+	var skipped []string
+	for _, s := range zone.Skipped {
+		skipped = append(skipped, s.Type)
+	}
+	if strings.Join(skipped, ",") != "SOA,NS,MX,TXT,A,$INCLUDE" {
+		t.Errorf("Unexpected skipped entries %+v", zone.Skipped)
+	}
+	if zone.Skipped[4].Line != 13 || zone.Skipped[4].Domain != "bad.example.com" {
+		t.Errorf("Unexpected skip %+v", zone.Skipped[4])
+	}
+}
+
+func TestParseZoneFile_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unbalanced": "@ IN SOA ns1 admin ( 1 2 3\n",
+		"no owner":   "  IN A 192.0.2.1\n",
+		"no type":    "www 300 IN\n",
+	}
+	for name, zone := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseZoneFile(strings.NewReader(zone), "example.com"); !errors.Is(err, ErrInvalidZoneFile) {
+				t.Errorf("Expected ErrInvalidZoneFile, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWriteZoneFile_RoundTrip(t *testing.T) {
+	records := []DNSRecord{
+		{Domain: "www.example.com", Description: "web\nservers", IPV4Addresses: []string{"192.0.2.10"}, IPV6Addresses: []string{"2001:db8::10"}},
+		{Domain: "example.com", IPV4Addresses: []string{"192.0.2.1"}},
+		{Domain: "ext.other.org", IPV4Addresses: []string{"198.51.100.7"}},
+	}
+	var buf bytes.Buffer
+	if err := WriteZoneFile(&buf, records, ZoneFileOptions{Origin: "example.com.", TTL: 300}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"$ORIGIN example.com.\n", "$TTL 300\n", "@\tIN\tA\t192.0.2.1\n", "; web servers\nwww\tIN\tA", "ext.other.org.\tIN\tA"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	zone, err := ParseZoneFile(&buf, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(zone.Records) != 3 || len(zone.Skipped) != 0 {
+		t.Errorf("Unexpected round trip %+v", zone)
+	}
+}
+
+func TestDNSRecordsService_ImportZoneFile(t *testing.T) {
+	var created, updated []DNSRecord
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(DNSRecordPageResponse{
+				Content: []DNSRecord{
+					{ID: "r-1", Domain: "example.com", Description: "apex", IPV4Addresses: []string{"192.0.2.1"}},
+					{ID: "r-2", Domain: "www.example.com", IPV4Addresses: []string{"192.0.2.99"}},
+				},
+				TotalPages: 1,
+			})
+		case http.MethodPost:
+			var record DNSRecord
+			_ = json.NewDecoder(r.Body).Decode(&record)
+			created = append(created, record)
+			record.ID = "r-new"
+			_ = json.NewEncoder(w).Encode(record)
+		case http.MethodPut:
+			var record DNSRecord
+			_ = json.NewDecoder(r.Body).Decode(&record)
+			updated = append(updated, record)
+		}
+	}))
+	defer server.Close()
+
+	client := createTestDNSClient(server)
+	result, err := client.DNSRecords.ImportZoneFile(strings.NewReader(testZoneFile), ZoneImportOptions{Description: "imported"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.Unchanged) != 1 || result.Unchanged[0].ID != "r-1" {
+		t.Errorf("Expected apex to be unchanged, got %+v", result.Unchanged)
+	}
+	if len(updated) != 1 || updated[0].ID != "r-2" || len(updated[0].IPV4Addresses) != 2 {
+		t.Errorf("Expected www to be updated, got %+v", updated)
+	}
+	if len(created) != 1 || created[0].Domain != "ext.other.org" || created[0].Description != "imported" {
+		t.Errorf("Expected ext.other.org to be created, got %+v", created)
+	}
+	if result.Created[0].ID != "r-new" || len(result.Skipped) != 6 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestDNSRecordsService_ImportZoneFile_DryRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected no %s request in dry run", r.Method)
+		}
+		_ = json.NewEncoder(w).Encode(DNSRecordPageResponse{TotalPages: 1})
+	}))
+	defer server.Close()
+
+	client := createTestDNSClient(server)
+	result, err := client.DNSRecords.ImportZoneFile(strings.NewReader(testZoneFile), ZoneImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Created) != 3 {
+		t.Errorf("Expected 3 planned creations, got %+v", result.Created)
+	}
+}
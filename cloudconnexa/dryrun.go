@@ -0,0 +1,132 @@
+package cloudconnexa
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// dryRunIDPrefix prefixes the IDs of resources created in dry-run mode.
+const dryRunIDPrefix = "dry-run-"
+
+// DryRunStep is one write recorded by a DryRunPlan.
+type DryRunStep struct {
+	// Operation is "create", "update" or "delete", or the action of action
+	// endpoints such as "activate".
+	Operation string `json:"operation"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Resource  string `json:"resource"`
+	// ResourceID is the ID in the path or, for creates, the synthetic ID
+	// returned to the caller.
+	ResourceID string `json:"resourceId,omitempty"`
+	// Body is the request body with AuditRedactedFields redacted.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// DryRunPlan collects the writes of a Client in dry-run mode. The zero value
+// is an empty plan ready to use.
+//
+// Writes are answered with synthetic responses so that callers continue:
+// the request body is echoed back, with the path ID, or for creates an ID
+// of the form "dry-run-N", set as "id" of JSON objects. Fields the API
+// would fill in or that the request spells differently stay empty, and
+// created resources are not visible to later reads.
+type DryRunPlan struct {
+	mu      sync.Mutex
+	steps   []DryRunStep
+	created int
+}
+
+// Steps returns the recorded writes in the order they were made.
+func (p *DryRunPlan) Steps() []DryRunStep {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]DryRunStep(nil), p.steps...)
+}
+
+// Reset clears the plan and restarts the numbering of synthetic IDs.
+func (p *DryRunPlan) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.steps, p.created = nil, 0
+}
+
+// WriteText writes one line per step: the method, path and body, followed by
+// the synthetic ID for creates. The output is deterministic, so plans of
+// repeated runs can be compared with diff.
+func (p *DryRunPlan) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, step := range p.Steps() {
+		fmt.Fprintf(bw, "%s %s", step.Method, step.Path)
+		if len(step.Body) > 0 {
+			fmt.Fprintf(bw, " %s", step.Body)
+		}
+		if step.Operation == "create" && step.ResourceID != "" {
+			fmt.Fprintf(bw, " -> %s", step.ResourceID)
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+// WriteJSON writes the steps as an indented JSON array.
+func (p *DryRunPlan) WriteJSON(w io.Writer) error {
+	steps := p.Steps()
+	if steps == nil {
+		steps = []DryRunStep{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(steps)
+}
+
+// record adds the write req to the plan and returns its synthetic response.
+func (p *DryRunPlan) record(req *http.Request) []byte {
+	resource, id, action := auditTarget(req.URL.Path)
+	body := peekRequestBody(req)
+	step := DryRunStep{
+		Operation:  auditOperation(req.Method, action),
+		Method:     req.Method,
+		Path:       req.URL.RequestURI(),
+		Resource:   resource,
+		ResourceID: id,
+	}
+	if len(body) > 0 {
+		step.Body = dryRunBody(body)
+	}
+
+	p.mu.Lock()
+	if step.Operation == "create" && id == "" {
+		p.created++
+		step.ResourceID = fmt.Sprintf("%s%d", dryRunIDPrefix, p.created)
+	}
+	p.steps = append(p.steps, step)
+	p.mu.Unlock()
+
+	if req.Method == http.MethodDelete {
+		return nil
+	}
+	if action == "" && step.ResourceID != "" {
+		var object map[string]any
+		if json.Unmarshal(body, &object) == nil && object != nil {
+			object["id"] = step.ResourceID
+			if data, err := json.Marshal(object); err == nil {
+				return data
+			}
+		}
+	}
+	return body
+}
+
+// dryRunBody returns a JSON body redacted, or any other body as a JSON string.
+func dryRunBody(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return redactAuditBody(body)
+	}
+	data, _ := json.Marshal(string(body))
+	return data
+}
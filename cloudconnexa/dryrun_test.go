@@ -0,0 +1,101 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_DryRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected %s %s in dry-run mode", r.Method, r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(NetworkPageResponse{Content: []Network{{ID: "n-1", Name: "prod"}}, TotalPages: 1})
+	}))
+	defer server.Close()
+	client := createTestClientWithServices(server)
+	plan := &DryRunPlan{}
+	client.DryRun = plan
+
+	networks, err := client.Networks.List()
+	if err != nil || len(networks) != 1 {
+		t.Fatalf("Expected reads to reach the API, got %v, %v", networks, err)
+	}
+	network, err := client.Networks.Create(Network{Name: "office"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if network.ID != "dry-run-1" || network.Name != "office" {
+		t.Errorf("Expected a synthetic network, got %+v", network)
+	}
+	route, err := client.Routes.Create(network.ID, Route{Type: "IP_V4", Subnet: "10.0.0.0/24"})
+	if err != nil || route.ID != "dry-run-2" {
+		t.Errorf("Expected a synthetic route, got %+v, %v", route, err)
+	}
+	if err := client.Routes.Delete("r-7"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := client.Users.Activate("u-1"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	steps := plan.Steps()
+	if len(steps) != 4 {
+		t.Fatalf("Expected 4 steps, got %d", len(steps))
+	}
+	if s := steps[0]; s.Operation != "create" || s.Resource != "networks" || s.ResourceID != "dry-run-1" {
+		t.Errorf("Unexpected step %+v", s)
+	}
+	if s := steps[3]; s.Operation != "activate" || s.Resource != "users" || s.ResourceID != "u-1" || s.Method != http.MethodPut {
+		t.Errorf("Unexpected step %+v", s)
+	}
+
+	var text bytes.Buffer
+	if err := plan.WriteText(&text); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(text.String(), "\n"), "\n")
+	want := []string{
+		`POST /api/v1/networks/routes?networkId=dry-run-1 {"description":"","value":"10.0.0.0/24"} -> dry-run-2`,
+		`DELETE /api/v1/networks/routes/r-7`,
+		`PUT /api/v1/users/u-1/activate`,
+	}
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "POST /api/v1/networks {") || !strings.HasSuffix(lines[0], "-> dry-run-1") {
+		t.Fatalf("Unexpected plan:\n%s", text.String())
+	}
+	for i, line := range want {
+		if lines[i+1] != line {
+			t.Errorf("Expected %q, got %q", line, lines[i+1])
+		}
+	}
+
+	var decoded []DryRunStep
+	var out bytes.Buffer
+	if err := plan.WriteJSON(&out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded) != 4 {
+		t.Errorf("Expected the steps as JSON, got %v, %v", decoded, err)
+	}
+
+	plan.Reset()
+	if len(plan.Steps()) != 0 {
+		t.Error("Expected an empty plan after Reset")
+	}
+}
+
+func TestDryRunPlan_RedactsSecrets(t *testing.T) {
+	plan := &DryRunPlan{}
+	req, _ := http.NewRequest(http.MethodPut, "https://example.com/api/v1/networks/connectors/nc-1/ipsec",
+		strings.NewReader(`{"preSharedKey":"hunter2","name":"office"}`))
+	if body := plan.record(req); !strings.Contains(string(body), "hunter2") {
+		t.Errorf("Expected the response to echo the request, got %s", body)
+	}
+	if steps := plan.Steps(); strings.Contains(string(steps[0].Body), "hunter2") {
+		t.Errorf("Expected the pre-shared key to be redacted, got %s", steps[0].Body)
+	}
+}
@@ -0,0 +1,84 @@
+package cloudconnexa
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// ConnectAuthNoAuth lets users connect without re-authenticating.
+	ConnectAuthNoAuth = "NO_AUTH"
+	// ConnectAuthOnPriorAuth requires authentication only if the previous one has expired.
+	ConnectAuthOnPriorAuth = "ON_PRIOR_AUTH"
+	// ConnectAuthEveryTime requires authentication on every connection.
+	ConnectAuthEveryTime = "EVERY_TIME"
+)
+
+const (
+	// TopologyFullMesh allows traffic between all networks, hosts and users.
+	TopologyFullMesh = "FULL_MESH"
+	// TopologyCustom restricts traffic to what access groups allow.
+	TopologyCustom = "CUSTOM"
+)
+
+const (
+	// DeviceEnforcementOff does not restrict which devices may connect.
+	DeviceEnforcementOff = "OFF"
+	// DeviceEnforcementLearnAndEnforce registers new devices automatically and enforces them afterwards.
+	DeviceEnforcementLearnAndEnforce = "LEARN_AND_ENFORCE"
+	// DeviceEnforcementEnforce only allows devices that are already registered.
+	DeviceEnforcementEnforce = "ENFORCE"
+)
+
+const (
+	// ProfileDistributionAutomatic distributes profiles to users automatically.
+	ProfileDistributionAutomatic = "AUTOMATIC"
+	// ProfileDistributionManual requires an administrator to distribute profiles.
+	ProfileDistributionManual = "MANUAL"
+)
+
+const (
+	// IPAllocationModeDynamic assigns VPN addresses from the pool on every connection.
+	IPAllocationModeDynamic = "DYNAMIC"
+	// IPAllocationModeStatic keeps a fixed VPN address per device.
+	IPAllocationModeStatic = "STATIC"
+)
+
+const (
+	// RouteTypeIPV4 is a route to an IPv4 subnet.
+	RouteTypeIPV4 = "IP_V4"
+	// RouteTypeIPV6 is a route to an IPv6 subnet.
+	RouteTypeIPV6 = "IP_V6"
+	// RouteTypeDomain is a route to a domain.
+	RouteTypeDomain = "DOMAIN"
+)
+
+const (
+	// TunnelingProtocolOpenVPN connects a network with OpenVPN.
+	TunnelingProtocolOpenVPN = "OPENVPN"
+	// TunnelingProtocolIPSec connects a network with IPsec.
+	TunnelingProtocolIPSec = "IPSEC"
+)
+
+// Known values of the string enums, used for client-side validation.
+var (
+	connectAuthValues         = []string{ConnectAuthNoAuth, ConnectAuthOnPriorAuth, ConnectAuthEveryTime}
+	internetAccessValues      = []string{InternetAccessSplitTunnelOn, InternetAccessSplitTunnelOff, InternetAccessRestrictedInternet}
+	topologyValues            = []string{TopologyFullMesh, TopologyCustom}
+	deviceEnforcementValues   = []string{DeviceEnforcementOff, DeviceEnforcementLearnAndEnforce, DeviceEnforcementEnforce}
+	profileDistributionValues = []string{ProfileDistributionAutomatic, ProfileDistributionManual}
+	ipAllocationModeValues    = []string{IPAllocationModeDynamic, IPAllocationModeStatic}
+	routeTypeValues           = []string{RouteTypeIPV4, RouteTypeIPV6, RouteTypeDomain}
+	tunnelingProtocolValues   = []string{TunnelingProtocolOpenVPN, TunnelingProtocolIPSec}
+)
+
+// validateEnum returns ErrInvalidEnumValue if value is not one of allowed.
+// Empty values are left for the API to default or reject, and validation is
+// skipped entirely when the client allows unknown enum values.
+func (c *Client) validateEnum(field, value string, allowed []string) error {
+	if value == "" || c.AllowUnknownEnumValues || slices.Contains(allowed, value) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s %q, expected one of %s", ErrInvalidEnumValue, field, value, strings.Join(allowed, ", "))
+}
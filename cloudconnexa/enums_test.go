@@ -0,0 +1,56 @@
+package cloudconnexa
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSettingsService_SetTopology_RejectsUnknownValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("Expected no HTTP call for an invalid value")
+	}))
+	defer server.Close()
+
+	client := createTestSettingsClient(server)
+	if _, err := client.Settings.SetTopology("FULL-MESH"); !errors.Is(err, ErrInvalidEnumValue) {
+		t.Errorf("Expected ErrInvalidEnumValue, got %v", err)
+	}
+}
+
+func TestSettingsService_SetTopology_AllowUnknownEnumValues(t *testing.T) {
+	server := httptest.NewServer(newSettingsAPI())
+	defer server.Close()
+
+	client := createTestSettingsClient(server)
+	client.AllowUnknownEnumValues = true
+	value, err := client.Settings.SetTopology("HUB_AND_SPOKE")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value != "HUB_AND_SPOKE" {
+		t.Errorf("Expected HUB_AND_SPOKE, got %s", value)
+	}
+}
+
+func TestNetworksService_Create_RejectsUnknownEnumValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("Expected no HTTP call for an invalid value")
+	}))
+	defer server.Close()
+
+	client := createTestClientWithServices(server)
+	tests := map[string]Network{
+		"internet access":    {InternetAccess: "SPLIT_TUNNEL"},
+		"tunneling protocol": {InternetAccess: InternetAccessSplitTunnelOn, TunnelingProtocol: "wireguard"},
+		"route type":         {Routes: []Route{{Type: "IPV4"}}},
+	}
+	for name, network := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := client.Networks.Create(network); !errors.Is(err, ErrInvalidEnumValue) {
+				t.Errorf("Expected ErrInvalidEnumValue, got %v", err)
+			}
+		})
+	}
+}
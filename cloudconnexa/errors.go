@@ -16,3 +16,17 @@ var ErrInvalidBaseURL = errors.New("invalid base URL")
 
 // ErrHTTPSRequired is returned when HTTP is used but HTTPS is required for security.
 var ErrHTTPSRequired = errors.New("HTTPS required: HTTP is not allowed for OAuth credentials")
+
+// ErrInvalidEnumValue is returned when a field is set to a value the client does not know.
+// Set Client.AllowUnknownEnumValues to send such values anyway.
+var ErrInvalidEnumValue = errors.New("invalid enum value")
+
+// ErrInvalidLocationContext is returned when a location context contains an
+// invalid IP address, CIDR block or country code.
+var ErrInvalidLocationContext = errors.New("invalid location context")
+
+// ErrVPNRegionNotAllowed is returned when a VPN region is not available to a user group.
+var ErrVPNRegionNotAllowed = errors.New("VPN region not allowed for user group")
+
+// ErrNoVPNRegion is returned when no VPN region satisfies a selection.
+var ErrNoVPNRegion = errors.New("no VPN region available")
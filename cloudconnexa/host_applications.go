@@ -8,6 +8,11 @@ import (
 	"net/http"
 )
 
+var (
+	// ErrHostApplicationNotFound is returned when a host application cannot be found
+	ErrHostApplicationNotFound = errors.New("host application not found")
+)
+
 // ApplicationRoute represents a route configuration for a host application.
 // Mirrors the API HostApplicationRouteRequest schema.
 type ApplicationRoute struct {
@@ -150,7 +155,7 @@ func (c *HostApplicationsService) GetByName(name string) (*ApplicationResponse,
 	if len(filtered) == 1 {
 		return &filtered[0], nil
 	}
-	return nil, errors.New("host application not found")
+	return nil, ErrHostApplicationNotFound
 }
 
 // Create creates a new host application.
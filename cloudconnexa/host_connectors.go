@@ -10,6 +10,11 @@ import (
 	"strconv"
 )
 
+var (
+	// ErrHostConnectorNotFound is returned when a host connector cannot be found
+	ErrHostConnectorNotFound = errors.New("host connector not found")
+)
+
 // HostConnector represents a host connector in CloudConnexa.
 type HostConnector struct {
 	ID               string `json:"id,omitempty"`
@@ -154,24 +159,28 @@ func (c *HostConnectorsService) GetByID(id string) (*HostConnector, error) {
 // name: The name of the connector to retrieve
 // Returns the connector and any error that occurred
 func (c *HostConnectorsService) GetByName(name string) (*HostConnector, error) {
-	items, err := c.List()
+	items, err := findByKey(c.client, "hosts/connectors", c.List, c.pages, nameKey[HostConnector], name, 2)
 	if err != nil {
 		return nil, err
 	}
-
-	filtered := make([]HostConnector, 0)
-	for _, item := range items {
-		if item.Name == name {
-			filtered = append(filtered, item)
-		}
-	}
-	if len(filtered) > 1 {
+	if len(items) > 1 {
 		return nil, errors.New("different host connectors found with name: " + name)
 	}
-	if len(filtered) == 1 {
-		return &filtered[0], nil
+	if len(items) == 0 {
+		return nil, ErrHostConnectorNotFound
 	}
-	return nil, errors.New("host connector not found")
+	return &items[0], nil
+}
+
+// Query returns the host connectors matching all preds, e.g. Query(NamePrefix[HostConnector]("prod-")).
+func (c *HostConnectorsService) Query(preds ...Predicate[HostConnector]) ([]HostConnector, error) {
+	return queryCollection(c.client, "hosts/connectors", c.List, c.pages, nameKey[HostConnector], preds)
+}
+
+// pages adapts GetByPage to a pageLister.
+func (c *HostConnectorsService) pages(page, size int) ([]HostConnector, int, error) {
+	response, err := c.GetByPage(page, size)
+	return response.Content, response.TotalPages, err
 }
 
 // GetProfile retrieves the profile configuration for a host connector.
@@ -8,6 +8,11 @@ import (
 	"net/http"
 )
 
+var (
+	// ErrHostIPServiceNotFound is returned when a host IP service cannot be found
+	ErrHostIPServiceNotFound = errors.New("host IP service not found")
+)
+
 // Range represents a range of values with lower and upper bounds, or a single value.
 type Range struct {
 	LowerValue int `json:"lowerValue"`
@@ -160,7 +165,7 @@ func (c *HostIPServicesService) GetByName(name string) (*HostIPServiceResponse,
 	if len(filtered) == 1 {
 		return &filtered[0], nil
 	}
-	return nil, errors.New("host IP service not found")
+	return nil, ErrHostIPServiceNotFound
 }
 
 // Create creates a new IP service.
@@ -8,6 +8,11 @@ import (
 	"net/http"
 )
 
+var (
+	// ErrHostNotFound is returned when a host cannot be found
+	ErrHostNotFound = errors.New("host not found")
+)
+
 // Host represents a host in CloudConnexa.
 type Host struct {
 	ID             string          `json:"id,omitempty"`
@@ -103,21 +108,32 @@ func (c *HostsService) Get(id string) (*Host, error) {
 // name: The name of the host to retrieve
 // Returns the host and any error that occurred
 func (c *HostsService) GetByName(name string) (*Host, error) {
-	items, err := c.List()
+	items, err := findByKey(c.client, "hosts", c.List, c.pages, nameKey[Host], name, 1)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, item := range items {
-		if item.Name == name {
-			return &item, nil
-		}
+	if len(items) == 0 {
+		return nil, ErrHostNotFound
 	}
-	return nil, errors.New("host not found")
+	return &items[0], nil
+}
+
+// Query returns the hosts matching all preds, e.g. Query(NamePrefix[Host]("prod-")).
+func (c *HostsService) Query(preds ...Predicate[Host]) ([]Host, error) {
+	return queryCollection(c.client, "hosts", c.List, c.pages, nameKey[Host], preds)
+}
+
+// pages adapts GetHostsByPage to a pageLister.
+func (c *HostsService) pages(page, size int) ([]Host, int, error) {
+	response, err := c.GetHostsByPage(page, size)
+	return response.Content, response.TotalPages, err
 }
 
 // Create creates a new host.
 func (c *HostsService) Create(host Host) (*Host, error) {
+	if err := c.client.validateEnum("internetAccess", host.InternetAccess, internetAccessValues); err != nil {
+		return nil, err
+	}
 	hostJSON, err := json.Marshal(host)
 	if err != nil {
 		return nil, err
@@ -146,6 +162,9 @@ func (c *HostsService) Update(host Host) error {
 	if err := validateID(host.ID); err != nil {
 		return err
 	}
+	if err := c.client.validateEnum("internetAccess", host.InternetAccess, internetAccessValues); err != nil {
+		return err
+	}
 	hostJSON, err := json.Marshal(host)
 	if err != nil {
 		return err
@@ -0,0 +1,275 @@
+package cloudconnexa
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+const defaultInformerInterval = 30 * time.Second
+
+// ErrInformerRunning is returned by Informer.Run if the informer is already running.
+var ErrInformerRunning = errors.New("informer is already running")
+
+// ResourceEventHandler receives the events of an Informer. Nil callbacks are skipped.
+type ResourceEventHandler[T any] struct {
+	OnAdd    func(obj T)
+	OnUpdate func(oldObj, newObj T)
+	OnDelete func(obj T)
+}
+
+// InformerOptions configures an Informer.
+type InformerOptions struct {
+	// Interval is the wait between re-lists. Defaults to 30s.
+	Interval time.Duration
+	// ResyncPeriod, if positive, redelivers every cached object to OnUpdate
+	// handlers with identical old and new objects at this period, so that
+	// controllers can reconcile objects whose events they failed to handle.
+	ResyncPeriod time.Duration
+	// OnError is called with every failed re-list. The informer keeps its
+	// mirror unchanged and retries at the next interval.
+	OnError func(error)
+}
+
+// Informer keeps a local mirror of a resource type by periodically
+// re-listing it, and delivers add, update and delete events, computed by ID,
+// to its handlers. Handlers are called sequentially: from the goroutine
+// running Run or Sync, and for the objects already mirrored when a handler is
+// added, from the goroutine calling AddEventHandler. Handlers must not call
+// AddEventHandler or Sync themselves.
+type Informer[T any] struct {
+	list func() ([]T, error)
+	id   func(T) string
+	opts InformerOptions
+
+	syncMu     sync.Mutex // serializes Sync and AddEventHandler so that events are delivered in order
+	mu         sync.RWMutex
+	items      map[string]T
+	synced     bool
+	lastResync time.Time
+	handlers   []ResourceEventHandler[T]
+	running    bool
+}
+
+// NewInformer creates an informer mirroring the objects returned by list,
+// identified by id.
+func NewInformer[T any](list func() ([]T, error), id func(T) string, opts InformerOptions) *Informer[T] {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInformerInterval
+	}
+	return &Informer[T]{list: list, id: id, opts: opts, items: map[string]T{}}
+}
+
+// AddEventHandler registers h. Objects already in the mirror are delivered
+// to h.OnAdd so that a late handler sees the complete state. A concurrent
+// Sync waits until they have been delivered.
+func (inf *Informer[T]) AddEventHandler(h ResourceEventHandler[T]) {
+	inf.syncMu.Lock()
+	defer inf.syncMu.Unlock()
+	inf.mu.Lock()
+	inf.handlers = append(inf.handlers, h)
+	existing := inf.sortedLocked()
+	inf.mu.Unlock()
+	if h.OnAdd != nil {
+		for _, obj := range existing {
+			h.OnAdd(obj)
+		}
+	}
+}
+
+// HasSynced reports whether the mirror has been filled by a successful list.
+func (inf *Informer[T]) HasSynced() bool {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	return inf.synced
+}
+
+// Lister returns a read-only view of the mirror.
+func (inf *Informer[T]) Lister() Lister[T] {
+	return Lister[T]{informer: inf}
+}
+
+// Run re-lists the resource at the configured interval until ctx is
+// cancelled, then returns ctx.Err(). The first list happens immediately.
+func (inf *Informer[T]) Run(ctx context.Context) error {
+	inf.mu.Lock()
+	if inf.running {
+		inf.mu.Unlock()
+		return ErrInformerRunning
+	}
+	inf.running = true
+	inf.mu.Unlock()
+	defer func() {
+		inf.mu.Lock()
+		inf.running = false
+		inf.mu.Unlock()
+	}()
+
+	for {
+		if err := inf.Sync(); err != nil && inf.opts.OnError != nil {
+			inf.opts.OnError(err)
+		}
+		if !sleepContext(ctx, inf.opts.Interval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// Sync re-lists the resource once, updates the mirror and delivers the
+// resulting events. On error the mirror is left unchanged.
+func (inf *Informer[T]) Sync() error {
+	inf.syncMu.Lock()
+	defer inf.syncMu.Unlock()
+	objs, err := inf.list()
+	if err != nil {
+		return err
+	}
+
+	type update struct{ oldObj, newObj T }
+	var added, deleted []T
+	var updated []update
+
+	inf.mu.Lock()
+	items := make(map[string]T, len(objs))
+	for _, obj := range objs {
+		items[inf.id(obj)] = obj
+	}
+	resync := inf.opts.ResyncPeriod > 0 && inf.synced && time.Since(inf.lastResync) >= inf.opts.ResyncPeriod
+	for _, id := range sortedKeys(items) {
+		obj := items[id]
+		old, ok := inf.items[id]
+		switch {
+		case !ok:
+			added = append(added, obj)
+		case resync || !reflect.DeepEqual(old, obj):
+			updated = append(updated, update{old, obj})
+		}
+	}
+	for _, id := range sortedKeys(inf.items) {
+		if _, ok := items[id]; !ok {
+			deleted = append(deleted, inf.items[id])
+		}
+	}
+	if resync || !inf.synced {
+		inf.lastResync = time.Now()
+	}
+	inf.items, inf.synced = items, true
+	handlers := slices.Clone(inf.handlers)
+	inf.mu.Unlock()
+
+	for _, h := range handlers {
+		for _, obj := range added {
+			if h.OnAdd != nil {
+				h.OnAdd(obj)
+			}
+		}
+		for _, u := range updated {
+			if h.OnUpdate != nil {
+				h.OnUpdate(u.oldObj, u.newObj)
+			}
+		}
+		for _, obj := range deleted {
+			if h.OnDelete != nil {
+				h.OnDelete(obj)
+			}
+		}
+	}
+	return nil
+}
+
+// sortedLocked returns the mirrored objects ordered by ID. inf.mu must be held.
+func (inf *Informer[T]) sortedLocked() []T {
+	objs := make([]T, 0, len(inf.items))
+	for _, id := range sortedKeys(inf.items) {
+		objs = append(objs, inf.items[id])
+	}
+	return objs
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Lister gives thread-safe read access to the mirror of an Informer.
+type Lister[T any] struct {
+	informer *Informer[T]
+}
+
+// List returns the mirrored objects ordered by ID.
+func (l Lister[T]) List() []T {
+	l.informer.mu.RLock()
+	defer l.informer.mu.RUnlock()
+	return l.informer.sortedLocked()
+}
+
+// Get returns the mirrored object with the given ID.
+func (l Lister[T]) Get(id string) (T, bool) {
+	l.informer.mu.RLock()
+	defer l.informer.mu.RUnlock()
+	obj, ok := l.informer.items[id]
+	return obj, ok
+}
+
+// Filter returns the mirrored objects matching all preds, ordered by ID.
+func (l Lister[T]) Filter(preds ...Predicate[T]) []T {
+	var matched []T
+	for _, obj := range l.List() {
+		if matchesAll(obj, preds) {
+			matched = append(matched, obj)
+		}
+	}
+	return matched
+}
+
+// Informer returns an informer mirroring all networks.
+func (c *NetworksService) Informer(opts InformerOptions) *Informer[Network] {
+	return NewInformer(c.uncached().List, func(n Network) string { return n.ID }, opts)
+}
+
+// Informer returns an informer mirroring all hosts.
+func (c *HostsService) Informer(opts InformerOptions) *Informer[Host] {
+	return NewInformer(c.uncached().List, func(h Host) string { return h.ID }, opts)
+}
+
+// Informer returns an informer mirroring all host connectors. Changes of
+// ConnectionStatus are delivered as updates.
+func (c *HostConnectorsService) Informer(opts InformerOptions) *Informer[HostConnector] {
+	return NewInformer(c.uncached().List, func(hc HostConnector) string { return hc.ID }, opts)
+}
+
+// Informer returns an informer mirroring all network connectors. Changes of
+// ConnectionStatus are delivered as updates.
+func (c *NetworkConnectorsService) Informer(opts InformerOptions) *Informer[NetworkConnector] {
+	return NewInformer(c.uncached().List, func(nc NetworkConnector) string { return nc.ID }, opts)
+}
+
+// Informer returns an informer mirroring all users.
+func (c *UsersService) Informer(opts InformerOptions) *Informer[User] {
+	return NewInformer(c.uncached().List, func(u User) string { return u.ID }, opts)
+}
+
+// uncached returns the service of a client that bypasses the response cache,
+// so that every informer re-list observes the current state.
+// uncached returns the service of a client that bypasses the response cache,
+// so that every informer re-list observes the current state.
+func (c *NetworksService) uncached() *NetworksService { return c.client.WithoutCache().Networks }
+
+func (c *HostsService) uncached() *HostsService { return c.client.WithoutCache().Hosts }
+
+func (c *HostConnectorsService) uncached() *HostConnectorsService {
+	return c.client.WithoutCache().HostConnectors
+}
+
+func (c *NetworkConnectorsService) uncached() *NetworkConnectorsService {
+	return c.client.WithoutCache().NetworkConnectors
+}
+
+func (c *UsersService) uncached() *UsersService { return c.client.WithoutCache().Users }
@@ -0,0 +1,174 @@
+package cloudconnexa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInformer_Events(t *testing.T) {
+	listed := []HostConnector{
+		{ID: "c-1", Name: "one", ConnectionStatus: "OFFLINE"},
+		{ID: "c-2", Name: "two", ConnectionStatus: "ONLINE"},
+	}
+	var listErr error
+	inf := NewInformer(func() ([]HostConnector, error) { return listed, listErr }, func(c HostConnector) string { return c.ID }, InformerOptions{})
+
+	var events []string
+	inf.AddEventHandler(ResourceEventHandler[HostConnector]{
+		OnAdd: func(c HostConnector) { events = append(events, "add "+c.ID) },
+		OnUpdate: func(old, c HostConnector) {
+			events = append(events, fmt.Sprintf("update %s %s->%s", c.ID, old.ConnectionStatus, c.ConnectionStatus))
+		},
+		OnDelete: func(c HostConnector) { events = append(events, "delete "+c.ID) },
+	})
+
+	if inf.HasSynced() {
+		t.Error("Expected the informer not to be synced before the first list")
+	}
+	if err := inf.Sync(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	listed = []HostConnector{
+		{ID: "c-1", Name: "one", ConnectionStatus: "ONLINE"},
+		{ID: "c-3", Name: "three"},
+	}
+	if err := inf.Sync(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := "add c-1,add c-2,add c-3,update c-1 OFFLINE->ONLINE,delete c-2"
+	if got := strings.Join(events, ","); got != expected {
+		t.Errorf("Expected events %s, got %s", expected, got)
+	}
+
+	listErr = errors.New("boom")
+	if err := inf.Sync(); err == nil {
+		t.Error("Expected the list error")
+	}
+	lister := inf.Lister()
+	if c, ok := lister.Get("c-1"); !ok || c.ConnectionStatus != "ONLINE" || len(lister.List()) != 2 {
+		t.Errorf("Expected the mirror to be kept after a failed list, got %+v", lister.List())
+	}
+	if online := lister.Filter(FieldEquals[HostConnector]("connectionStatus", "ONLINE")); len(online) != 1 {
+		t.Errorf("Expected one online connector, got %+v", online)
+	}
+
+	var late []string
+	inf.AddEventHandler(ResourceEventHandler[HostConnector]{OnAdd: func(c HostConnector) { late = append(late, c.ID) }})
+	if strings.Join(late, ",") != "c-1,c-3" {
+		t.Errorf("Expected a late handler to receive the mirror, got %v", late)
+	}
+}
+
+func TestInformer_AddEventHandler_ConcurrentSync(t *testing.T) {
+	listed := []Network{{ID: "n-1"}}
+	inf := NewInformer(func() ([]Network, error) { return listed, nil }, func(n Network) string { return n.ID }, InformerOptions{})
+	if err := inf.Sync(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	listed = nil
+
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}
+	adding, release := make(chan struct{}), make(chan struct{})
+	added := make(chan struct{})
+	go func() {
+		defer close(added)
+		inf.AddEventHandler(ResourceEventHandler[Network]{
+			OnAdd: func(n Network) {
+				close(adding)
+				<-release
+				record("add " + n.ID)
+			},
+			OnDelete: func(n Network) { record("delete " + n.ID) },
+		})
+	}()
+	<-adding
+	synced := make(chan error)
+	go func() { synced <- inf.Sync() }()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	<-added
+	if err := <-synced; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := strings.Join(events, ","); got != "add n-1,delete n-1" {
+		t.Errorf("Expected the initial add before the concurrent delete, got %s", got)
+	}
+}
+
+func TestInformer_Resync(t *testing.T) {
+	inf := NewInformer(func() ([]Network, error) { return []Network{{ID: "n-1"}}, nil }, func(n Network) string { return n.ID }, InformerOptions{ResyncPeriod: time.Nanosecond})
+	updates := 0
+	inf.AddEventHandler(ResourceEventHandler[Network]{OnUpdate: func(_, _ Network) { updates++ }})
+	_ = inf.Sync()
+	time.Sleep(time.Millisecond)
+	_ = inf.Sync()
+	if updates != 1 {
+		t.Errorf("Expected a resync update, got %d", updates)
+	}
+}
+
+func TestInformer_Run(t *testing.T) {
+	var mu sync.Mutex
+	lists := 0
+	inf := NewInformer(func() ([]User, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		lists++
+		if lists == 1 {
+			return nil, errors.New("unavailable")
+		}
+		return []User{{ID: "u-1", Username: "alice"}}, nil
+	}, func(u User) string { return u.ID }, InformerOptions{Interval: time.Millisecond, OnError: func(error) {}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- inf.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for !inf.HasSynced() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := inf.Run(ctx); !errors.Is(err, ErrInformerRunning) {
+		t.Errorf("Expected ErrInformerRunning, got %v", err)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if u, ok := inf.Lister().Get("u-1"); !ok || u.Username != "alice" {
+		t.Errorf("Expected alice in the mirror, got %+v", u)
+	}
+}
+
+func TestNetworksService_Informer_BypassesCache(t *testing.T) {
+	api := &queryNetworksAPI{names: []string{"a", "b"}}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+	client.EnableCache(CacheOptions{DefaultTTL: time.Hour})
+
+	inf := client.Networks.Informer(InformerOptions{})
+	var added []string
+	inf.AddEventHandler(ResourceEventHandler[Network]{OnAdd: func(n Network) { added = append(added, n.Name) }})
+	_ = inf.Sync()
+	api.mu.Lock()
+	api.names = append(api.names, "c")
+	api.mu.Unlock()
+	if err := inf.Sync(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Join(added, ",") != "a,b,c" {
+		t.Errorf("Expected the new network to be observed, got %v", added)
+	}
+}
@@ -0,0 +1,166 @@
+package cloudconnexa
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// Location check rules reported by EvaluateLocationContexts.
+const (
+	LocationRuleIP      = "IP"
+	LocationRuleCountry = "COUNTRY"
+	LocationRuleDefault = "DEFAULT"
+	// LocationRuleNone means no location context applies to the user group.
+	LocationRuleNone = "NONE"
+)
+
+// LocationQuery describes a connection attempt to evaluate against location contexts.
+type LocationQuery struct {
+	UserGroupID string `json:"userGroupId"`
+	// IP is the public IP address of the client.
+	IP string `json:"ip"`
+	// Country is the ISO 3166-1 alpha-2 code of the client's country.
+	Country string `json:"country"`
+}
+
+// LocationContextMatch is the verdict of one location context.
+type LocationContextMatch struct {
+	LocationContextID   string `json:"locationContextId"`
+	LocationContextName string `json:"locationContextName"`
+	Allowed             bool   `json:"allowed"`
+	// Rule is the check that decided the verdict: LocationRuleIP, LocationRuleCountry or LocationRuleDefault.
+	Rule string `json:"rule"`
+	// Matched is the IP entry or country code that matched, if any.
+	Matched string `json:"matched,omitempty"`
+}
+
+// LocationDecision is the result of evaluating a LocationQuery.
+type LocationDecision struct {
+	Allowed bool `json:"allowed"`
+	// Rule is the rule of the deciding match, or LocationRuleNone.
+	Rule string `json:"rule"`
+	// Decisive is the match that decided the verdict, nil if no context applies.
+	Decisive *LocationContextMatch `json:"decisive,omitempty"`
+	// Matches lists the verdict of every location context applying to the user group.
+	Matches []LocationContextMatch `json:"matches"`
+}
+
+// EvaluateLocationContexts simulates the location checks applied when a user of
+// query.UserGroupID connects. Within a context the IP check is consulted first,
+// then the country check, then the default check. Every context assigned to the
+// user group must allow the connection; the first denying context is decisive.
+// A user group without location contexts is allowed.
+func EvaluateLocationContexts(contexts []LocationContext, query LocationQuery) (*LocationDecision, error) {
+	var addr netip.Addr
+	if query.IP != "" {
+		var err error
+		if addr, err = netip.ParseAddr(query.IP); err != nil {
+			return nil, fmt.Errorf("invalid client IP %q: %w", query.IP, err)
+		}
+		addr = addr.Unmap()
+	}
+	country := strings.ToUpper(strings.TrimSpace(query.Country))
+
+	decision := &LocationDecision{Allowed: true, Rule: LocationRuleNone, Matches: []LocationContextMatch{}}
+	for _, lc := range contexts {
+		if !slices.Contains(lc.UserGroupsIDs, query.UserGroupID) {
+			continue
+		}
+		decision.Matches = append(decision.Matches, evaluateLocationContext(lc, addr, country))
+	}
+	for i := range decision.Matches {
+		match := &decision.Matches[i]
+		if decision.Decisive == nil || (!match.Allowed && decision.Decisive.Allowed) {
+			decision.Decisive = match
+		}
+	}
+	if decision.Decisive != nil {
+		decision.Allowed = decision.Decisive.Allowed
+		decision.Rule = decision.Decisive.Rule
+	}
+	return decision, nil
+}
+
+// Simulate lists location contexts and evaluates query against them.
+func (c *LocationContextsService) Simulate(query LocationQuery) (*LocationDecision, error) {
+	contexts, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	return EvaluateLocationContexts(contexts, query)
+}
+
+func evaluateLocationContext(lc LocationContext, addr netip.Addr, country string) LocationContextMatch {
+	match := LocationContextMatch{LocationContextID: lc.ID, LocationContextName: lc.Name, Allowed: true, Rule: LocationRuleDefault}
+	if lc.IPCheck != nil && addr.IsValid() {
+		for _, ip := range lc.IPCheck.Ips {
+			if ipEntryContains(ip.IP, addr) {
+				match.Allowed, match.Rule, match.Matched = lc.IPCheck.Allowed, LocationRuleIP, ip.IP
+				return match
+			}
+		}
+	}
+	if lc.CountryCheck != nil && country != "" {
+		for _, c := range lc.CountryCheck.Countries {
+			if strings.EqualFold(c, country) {
+				match.Allowed, match.Rule, match.Matched = lc.CountryCheck.Allowed, LocationRuleCountry, c
+				return match
+			}
+		}
+	}
+	if lc.DefaultCheck != nil {
+		match.Allowed = lc.DefaultCheck.Allowed
+	}
+	return match
+}
+
+// ipEntryContains reports whether the IP address or CIDR block entry contains addr.
+func ipEntryContains(entry string, addr netip.Addr) bool {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		return err == nil && prefix.Contains(addr)
+	}
+	ip, err := netip.ParseAddr(entry)
+	return err == nil && ip.Unmap() == addr
+}
+
+// Validate checks that the IP check lists valid IP addresses or CIDR blocks and
+// that the country check lists ISO 3166-1 alpha-2 country codes. A nil location
+// context is invalid.
+func (lc *LocationContext) Validate() error {
+	if lc == nil {
+		return fmt.Errorf("%w: nil location context", ErrInvalidLocationContext)
+	}
+	if lc.IPCheck != nil {
+		for _, ip := range lc.IPCheck.Ips {
+			entry := strings.TrimSpace(ip.IP)
+			var err error
+			if strings.Contains(entry, "/") {
+				_, err = netip.ParsePrefix(entry)
+			} else {
+				_, err = netip.ParseAddr(entry)
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %q is not an IP address or CIDR block", ErrInvalidLocationContext, ip.IP)
+			}
+		}
+	}
+	if lc.CountryCheck != nil {
+		for _, country := range lc.CountryCheck.Countries {
+			if !IsCountryCode(country) {
+				return fmt.Errorf("%w: %q is not an ISO 3166-1 alpha-2 country code", ErrInvalidLocationContext, country)
+			}
+		}
+	}
+	return nil
+}
+
+// IsCountryCode reports whether code is an officially assigned ISO 3166-1 alpha-2 code.
+// The codes are those of the embedded country centroid dataset.
+func IsCountryCode(code string) bool {
+	_, ok := loadCountryCentroids()[strings.ToUpper(code)]
+	return ok
+}
@@ -0,0 +1,120 @@
+package cloudconnexa
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testLocationContexts() []LocationContext {
+	return []LocationContext{
+		{
+			ID:            "lc-office",
+			Name:          "Office",
+			UserGroupsIDs: []string{"ug-eng", "ug-sales"},
+			IPCheck:       &IPCheck{Allowed: true, Ips: []IP{{IP: "203.0.113.0/24"}, {IP: "2001:db8::1"}}},
+			CountryCheck:  &CountryCheck{Allowed: false, Countries: []string{"KP", "IR"}},
+			DefaultCheck:  &DefaultCheck{Allowed: true},
+		},
+		{
+			ID:            "lc-eu",
+			Name:          "EU only",
+			UserGroupsIDs: []string{"ug-sales"},
+			CountryCheck:  &CountryCheck{Allowed: true, Countries: []string{"DE", "FR"}},
+			DefaultCheck:  &DefaultCheck{Allowed: false},
+		},
+	}
+}
+
+func TestEvaluateLocationContexts(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    LocationQuery
+		allowed  bool
+		rule     string
+		decisive string
+	}{
+		{"allowed IP overrides country", LocationQuery{UserGroupID: "ug-eng", IP: "203.0.113.7", Country: "KP"}, true, LocationRuleIP, "lc-office"},
+		{"denied country", LocationQuery{UserGroupID: "ug-eng", IP: "198.51.100.1", Country: "kp"}, false, LocationRuleCountry, "lc-office"},
+		{"default", LocationQuery{UserGroupID: "ug-eng", IP: "198.51.100.1", Country: "US"}, true, LocationRuleDefault, "lc-office"},
+		{"IPv6 address", LocationQuery{UserGroupID: "ug-eng", IP: "2001:db8::1", Country: "IR"}, true, LocationRuleIP, "lc-office"},
+		{"deny overrides allow", LocationQuery{UserGroupID: "ug-sales", IP: "203.0.113.7", Country: "US"}, false, LocationRuleDefault, "lc-eu"},
+		{"allowed by all contexts", LocationQuery{UserGroupID: "ug-sales", IP: "198.51.100.1", Country: "DE"}, true, LocationRuleDefault, "lc-office"},
+		{"no contexts", LocationQuery{UserGroupID: "ug-other", IP: "198.51.100.1", Country: "KP"}, true, LocationRuleNone, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := EvaluateLocationContexts(testLocationContexts(), tt.query)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			decisive := ""
+			if decision.Decisive != nil {
+				decisive = decision.Decisive.LocationContextID
+			}
+			if decision.Allowed != tt.allowed || decision.Rule != tt.rule || decisive != tt.decisive {
+				t.Errorf("Expected allowed=%v rule=%s decisive=%s, got %+v", tt.allowed, tt.rule, tt.decisive, decision)
+			}
+		})
+	}
+}
+
+func TestEvaluateLocationContexts_InvalidIP(t *testing.T) {
+	if _, err := EvaluateLocationContexts(testLocationContexts(), LocationQuery{IP: "not-an-ip"}); err == nil {
+		t.Error("Expected an error for an invalid client IP")
+	}
+}
+
+func TestLocationContext_Validate(t *testing.T) {
+	if err := testLocationContexts()[0].Validate(); err != nil {
+		t.Errorf("Expected valid location context, got %v", err)
+	}
+
+	invalid := []LocationContext{
+		{IPCheck: &IPCheck{Ips: []IP{{IP: "10.0.0.0/33"}}}},
+		{IPCheck: &IPCheck{Ips: []IP{{IP: "10.0.0"}}}},
+		{CountryCheck: &CountryCheck{Countries: []string{"XX"}}},
+		{CountryCheck: &CountryCheck{Countries: []string{"USA"}}},
+	}
+	for _, lc := range invalid {
+		if err := lc.Validate(); !errors.Is(err, ErrInvalidLocationContext) {
+			t.Errorf("Expected ErrInvalidLocationContext for %+v, got %v", lc, err)
+		}
+	}
+}
+
+func TestIsCountryCode(t *testing.T) {
+	if n := len(loadCountryCentroids()); n != 249 {
+		t.Errorf("Expected 249 country codes, got %d", n)
+	}
+	for _, code := range []string{"AD", "de", "ZW"} {
+		if !IsCountryCode(code) {
+			t.Errorf("Expected %q to be a country code", code)
+		}
+	}
+	for _, code := range []string{"", "D", "E ", "UK", "EU"} {
+		if IsCountryCode(code) {
+			t.Errorf("Expected %q not to be a country code", code)
+		}
+	}
+}
+
+func TestLocationContextsService_CreateValidates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("Expected no request for an invalid location context")
+	}))
+	defer server.Close()
+
+	client := createTestClientWithServices(server)
+	_, err := client.LocationContexts.Create(&LocationContext{CountryCheck: &CountryCheck{Countries: []string{"XX"}}})
+	if !errors.Is(err, ErrInvalidLocationContext) {
+		t.Errorf("Expected ErrInvalidLocationContext, got %v", err)
+	}
+	if _, err := client.LocationContexts.Create(nil); !errors.Is(err, ErrInvalidLocationContext) {
+		t.Errorf("Expected ErrInvalidLocationContext for nil, got %v", err)
+	}
+	if _, err := client.LocationContexts.Update("lc-1", nil); !errors.Is(err, ErrInvalidLocationContext) {
+		t.Errorf("Expected ErrInvalidLocationContext for nil, got %v", err)
+	}
+}
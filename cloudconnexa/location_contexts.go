@@ -8,6 +8,11 @@ import (
 	"net/http"
 )
 
+var (
+	// ErrLocationContextNotFound is returned when a location context cannot be found
+	ErrLocationContextNotFound = errors.New("location context not found")
+)
+
 // LocationContext represents a location context in CloudConnexa with its associated checks and user groups.
 type LocationContext struct {
 	ID            string        `json:"id"`
@@ -137,11 +142,14 @@ func (c *LocationContextsService) GetByName(name string) (*LocationContext, erro
 			return &item, nil
 		}
 	}
-	return nil, errors.New("location context not found")
+	return nil, ErrLocationContextNotFound
 }
 
-// Create creates a new location context.
+// Create creates a new location context after validating its IP and country checks.
 func (c *LocationContextsService) Create(locationContext *LocationContext) (*LocationContext, error) {
+	if err := locationContext.Validate(); err != nil {
+		return nil, err
+	}
 	locationContextJSON, err := json.Marshal(locationContext)
 	if err != nil {
 		return nil, err
@@ -166,11 +174,14 @@ func (c *LocationContextsService) Create(locationContext *LocationContext) (*Loc
 	return &s, nil
 }
 
-// Update updates an existing location context by its ID.
+// Update updates an existing location context by its ID after validating its IP and country checks.
 func (c *LocationContextsService) Update(id string, locationContext *LocationContext) (*LocationContext, error) {
 	if err := validateID(id); err != nil {
 		return nil, err
 	}
+	if err := locationContext.Validate(); err != nil {
+		return nil, err
+	}
 	locationContextJSON, err := json.Marshal(locationContext)
 	if err != nil {
 		return nil, err
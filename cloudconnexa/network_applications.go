@@ -8,6 +8,11 @@ import (
 	"net/http"
 )
 
+var (
+	// ErrNetworkApplicationNotFound is returned when a network application cannot be found
+	ErrNetworkApplicationNotFound = errors.New("network application not found")
+)
+
 // NetworkApplicationRoute represents a route configuration for a network application.
 // Mirrors the API NetworkApplicationRouteRequest schema. ExactMatch is supported
 // only for network application routes; the host application equivalent
@@ -151,7 +156,7 @@ func (c *NetworkApplicationsService) GetByName(name string) (*NetworkApplication
 	if len(filtered) == 1 {
 		return &filtered[0], nil
 	}
-	return nil, errors.New("network application not found")
+	return nil, ErrNetworkApplicationNotFound
 }
 
 // Create creates a new network application.
@@ -10,6 +10,11 @@ import (
 	"strconv"
 )
 
+var (
+	// ErrNetworkConnectorNotFound is returned when a network connector cannot be found
+	ErrNetworkConnectorNotFound = errors.New("network connector not found")
+)
+
 // NetworkConnector represents a network connector configuration.
 type NetworkConnector struct {
 	ID                string       `json:"id,omitempty"`
@@ -224,24 +229,28 @@ func (c *NetworkConnectorsService) GetByID(id string) (*NetworkConnector, error)
 // name: The name of the network connector to retrieve
 // Returns the network connector and any error that occurred
 func (c *NetworkConnectorsService) GetByName(name string) (*NetworkConnector, error) {
-	items, err := c.List()
+	items, err := findByKey(c.client, "networks/connectors", c.List, c.pages, nameKey[NetworkConnector], name, 2)
 	if err != nil {
 		return nil, err
 	}
-
-	filtered := make([]NetworkConnector, 0)
-	for _, item := range items {
-		if item.Name == name {
-			filtered = append(filtered, item)
-		}
-	}
-	if len(filtered) > 1 {
+	if len(items) > 1 {
 		return nil, errors.New("different network connectors found with name: " + name)
 	}
-	if len(filtered) == 1 {
-		return &filtered[0], nil
+	if len(items) == 0 {
+		return nil, ErrNetworkConnectorNotFound
 	}
-	return nil, errors.New("network connector not found")
+	return &items[0], nil
+}
+
+// Query returns the network connectors matching all preds, e.g. Query(NamePrefix[NetworkConnector]("prod-")).
+func (c *NetworkConnectorsService) Query(preds ...Predicate[NetworkConnector]) ([]NetworkConnector, error) {
+	return queryCollection(c.client, "networks/connectors", c.List, c.pages, nameKey[NetworkConnector], preds)
+}
+
+// pages adapts GetByPage to a pageLister.
+func (c *NetworkConnectorsService) pages(page, size int) ([]NetworkConnector, int, error) {
+	response, err := c.GetByPage(page, size)
+	return response.Content, response.TotalPages, err
 }
 
 // GetProfile retrieves the profile configuration for a specific network connector.
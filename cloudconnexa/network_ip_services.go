@@ -8,6 +8,11 @@ import (
 	"net/http"
 )
 
+var (
+	// ErrNetworkIPServiceNotFound is returned when a network IP service cannot be found
+	ErrNetworkIPServiceNotFound = errors.New("network IP service not found")
+)
+
 // NetworkIPServiceResponse represents the response structure for Network IP service operations.
 type NetworkIPServiceResponse struct {
 	Name            string           `json:"name"`
@@ -128,7 +133,7 @@ func (c *NetworkIPServicesService) GetByName(name string) (*NetworkIPServiceResp
 	if len(filtered) == 1 {
 		return &filtered[0], nil
 	}
-	return nil, errors.New("network IP service not found")
+	return nil, ErrNetworkIPServiceNotFound
 }
 
 // Create creates a new IP service
@@ -8,6 +8,11 @@ import (
 	"net/http"
 )
 
+var (
+	// ErrNetworkNotFound is returned when a network cannot be found
+	ErrNetworkNotFound = errors.New("network not found")
+)
+
 const (
 	// InternetAccessSplitTunnelOn enables split tunneling for internet access.
 	InternetAccessSplitTunnelOn = "SPLIT_TUNNEL_ON"
@@ -121,23 +126,34 @@ func (c *NetworksService) Get(id string) (*Network, error) {
 // name: The name of the network to retrieve
 // Returns the network and any error that occurred
 func (c *NetworksService) GetByName(name string) (*Network, error) {
-	items, err := c.List()
+	items, err := findByKey(c.client, "networks", c.List, c.pages, nameKey[Network], name, 1)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, item := range items {
-		if item.Name == name {
-			return &item, nil
-		}
+	if len(items) == 0 {
+		return nil, ErrNetworkNotFound
 	}
-	return nil, errors.New("network not found")
+	return &items[0], nil
+}
+
+// Query returns the networks matching all preds, e.g. Query(NamePrefix[Network]("prod-")).
+func (c *NetworksService) Query(preds ...Predicate[Network]) ([]Network, error) {
+	return queryCollection(c.client, "networks", c.List, c.pages, nameKey[Network], preds)
+}
+
+// pages adapts GetByPage to a pageLister.
+func (c *NetworksService) pages(page, size int) ([]Network, int, error) {
+	response, err := c.GetByPage(page, size)
+	return response.Content, response.TotalPages, err
 }
 
 // Create creates a new network.
 // network: The network configuration to create
 // Returns the created network and any error that occurred
 func (c *NetworksService) Create(network Network) (*Network, error) {
+	if err := c.validateEnums(network); err != nil {
+		return nil, err
+	}
 	networkJSON, err := json.Marshal(network)
 	if err != nil {
 		return nil, err
@@ -168,6 +184,9 @@ func (c *NetworksService) Update(network Network) error {
 	if err := validateID(network.ID); err != nil {
 		return err
 	}
+	if err := c.validateEnums(network); err != nil {
+		return err
+	}
 	networkJSON, err := json.Marshal(network)
 	if err != nil {
 		return err
@@ -197,3 +216,19 @@ func (c *NetworksService) Delete(networkID string) error {
 	_, err = c.client.DoRequest(req)
 	return err
 }
+
+// validateEnums checks the enum fields of network, including those of its routes.
+func (c *NetworksService) validateEnums(network Network) error {
+	if err := c.client.validateEnum("internetAccess", network.InternetAccess, internetAccessValues); err != nil {
+		return err
+	}
+	if err := c.client.validateEnum("tunnelingProtocol", network.TunnelingProtocol, tunnelingProtocolValues); err != nil {
+		return err
+	}
+	for _, route := range network.Routes {
+		if err := c.client.validateEnum("route type", route.Type, routeTypeValues); err != nil {
+			return err
+		}
+	}
+	return nil
+}
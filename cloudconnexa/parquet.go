@@ -0,0 +1,353 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// This file implements the small subset of the Apache Parquet format needed to
+// export flat records: required INT64 and UTF8 BYTE_ARRAY columns, PLAIN
+// encoding, no compression, one data page per column chunk. Metadata is
+// serialized with the Thrift compact protocol as mandated by the format.
+
+const parquetMagic = "PAR1"
+
+// parquetDefaultRowGroupSize is the number of rows buffered before a row group is flushed.
+const parquetDefaultRowGroupSize = 10000
+
+type parquetColumnKind int
+
+const (
+	parquetString parquetColumnKind = iota
+	parquetInt64
+	parquetTimestampMillis
+)
+
+// Parquet physical types, converted types and other enum values used below.
+const (
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMillis = 9
+
+	parquetRepetitionRequired = 0
+	parquetEncodingPlain      = 0
+	parquetEncodingRLE        = 3
+	parquetCodecUncompressed  = 0
+	parquetPageTypeData       = 0
+)
+
+type parquetColumn struct {
+	name string
+	kind parquetColumnKind
+}
+
+type parquetColumnChunkMeta struct {
+	offset int64
+	size   int64
+	values int64
+}
+
+type parquetRowGroupMeta struct {
+	rows    int64
+	size    int64
+	columns []parquetColumnChunkMeta
+}
+
+// parquetWriter writes rows of a fixed flat schema to a Parquet file.
+// Values passed to WriteRow must be string for string columns and int64 or
+// time.Time for integer and timestamp columns respectively.
+type parquetWriter struct {
+	w            io.Writer
+	columns      []parquetColumn
+	buffers      []bytes.Buffer
+	rows         int64
+	totalRows    int64
+	offset       int64
+	rowGroups    []parquetRowGroupMeta
+	rowGroupSize int64
+	started      bool
+	closed       bool
+}
+
+func newParquetWriter(w io.Writer, columns []parquetColumn) *parquetWriter {
+	return &parquetWriter{
+		w:            w,
+		columns:      columns,
+		buffers:      make([]bytes.Buffer, len(columns)),
+		rowGroupSize: parquetDefaultRowGroupSize,
+	}
+}
+
+func (p *parquetWriter) WriteRow(values ...any) error {
+	if p.closed {
+		return errors.New("parquet: write after close")
+	}
+	if len(values) != len(p.columns) {
+		return errors.New("parquet: value count does not match schema")
+	}
+	var scratch [8]byte
+	for i, v := range values {
+		buf := &p.buffers[i]
+		switch p.columns[i].kind {
+		case parquetString:
+			s, ok := v.(string)
+			if !ok {
+				return errors.New("parquet: column " + p.columns[i].name + " expects a string")
+			}
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(len(s))) //nolint:gosec // lengths are bounded by the response size limit
+			buf.Write(scratch[:4])
+			buf.WriteString(s)
+		case parquetInt64, parquetTimestampMillis:
+			var n int64
+			switch x := v.(type) {
+			case int64:
+				n = x
+			case time.Time:
+				n = x.UnixMilli()
+			default:
+				return errors.New("parquet: column " + p.columns[i].name + " expects an int64 or time.Time")
+			}
+			binary.LittleEndian.PutUint64(scratch[:], uint64(n)) //nolint:gosec // two's complement encoding is intended
+			buf.Write(scratch[:])
+		}
+	}
+	p.rows++
+	if p.rows >= p.rowGroupSize {
+		return p.flushRowGroup()
+	}
+	return nil
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	return err
+}
+
+func (p *parquetWriter) start() error {
+	if p.started {
+		return nil
+	}
+	p.started = true
+	return p.write([]byte(parquetMagic))
+}
+
+func (p *parquetWriter) flushRowGroup() error {
+	if err := p.start(); err != nil {
+		return err
+	}
+	if p.rows == 0 {
+		return nil
+	}
+	group := parquetRowGroupMeta{rows: p.rows}
+	for i := range p.columns {
+		data := p.buffers[i].Bytes()
+		var header thriftCompactWriter
+		header.fieldI32(1, parquetPageTypeData)
+		header.fieldI32(2, int32(len(data))) //nolint:gosec // page size bounded by row group size
+		header.fieldI32(3, int32(len(data))) //nolint:gosec // page size bounded by row group size
+		header.fieldStructBegin(5)
+		header.fieldI32(1, int32(p.rows)) //nolint:gosec // bounded by row group size
+		header.fieldI32(2, parquetEncodingPlain)
+		header.fieldI32(3, parquetEncodingRLE)
+		header.fieldI32(4, parquetEncodingRLE)
+		header.structEnd()
+		header.structEnd()
+
+		chunk := parquetColumnChunkMeta{offset: p.offset, values: p.rows}
+		if err := p.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(data); err != nil {
+			return err
+		}
+		chunk.size = p.offset - chunk.offset
+		group.size += chunk.size
+		group.columns = append(group.columns, chunk)
+		p.buffers[i].Reset()
+	}
+	p.rowGroups = append(p.rowGroups, group)
+	p.totalRows += p.rows
+	p.rows = 0
+	return nil
+}
+
+// Close flushes buffered rows and writes the file footer. It does not close the underlying writer.
+func (p *parquetWriter) Close() error {
+	if p.closed {
+		return nil
+	}
+	if err := p.flushRowGroup(); err != nil {
+		return err
+	}
+	p.closed = true
+
+	var meta thriftCompactWriter
+	meta.fieldI32(1, 1)
+	meta.fieldListBegin(2, thriftTypeStruct, len(p.columns)+1)
+	meta.structBegin()
+	meta.fieldString(4, "schema")
+	meta.fieldI32(5, int32(len(p.columns))) //nolint:gosec // schema is a small fixed list
+	meta.structEnd()
+	for _, c := range p.columns {
+		physical, converted := parquetTypes(c.kind)
+		meta.structBegin()
+		meta.fieldI32(1, physical)
+		meta.fieldI32(3, parquetRepetitionRequired)
+		meta.fieldString(4, c.name)
+		if converted >= 0 {
+			meta.fieldI32(6, converted)
+		}
+		meta.structEnd()
+	}
+	meta.fieldI64(3, p.totalRows)
+	meta.fieldListBegin(4, thriftTypeStruct, len(p.rowGroups))
+	for _, g := range p.rowGroups {
+		meta.structBegin()
+		meta.fieldListBegin(1, thriftTypeStruct, len(g.columns))
+		for i, chunk := range g.columns {
+			physical, _ := parquetTypes(p.columns[i].kind)
+			meta.structBegin()
+			meta.fieldI64(2, chunk.offset)
+			meta.fieldStructBegin(3)
+			meta.fieldI32(1, physical)
+			meta.fieldListBegin(2, thriftTypeI32, 1)
+			meta.i32(parquetEncodingPlain)
+			meta.fieldListBegin(3, thriftTypeBinary, 1)
+			meta.binary(p.columns[i].name)
+			meta.fieldI32(4, parquetCodecUncompressed)
+			meta.fieldI64(5, chunk.values)
+			meta.fieldI64(6, chunk.size)
+			meta.fieldI64(7, chunk.size)
+			meta.fieldI64(9, chunk.offset)
+			meta.structEnd()
+			meta.structEnd()
+		}
+		meta.fieldI64(2, g.size)
+		meta.fieldI64(3, g.rows)
+		meta.structEnd()
+	}
+	meta.fieldString(6, userAgent)
+	meta.structEnd()
+
+	if err := p.write(meta.buf.Bytes()); err != nil {
+		return err
+	}
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(meta.buf.Len())) //nolint:gosec // footer size is small
+	if err := p.write(length[:]); err != nil {
+		return err
+	}
+	return p.write([]byte(parquetMagic))
+}
+
+func parquetTypes(kind parquetColumnKind) (physical int32, converted int32) {
+	switch kind {
+	case parquetInt64:
+		// Plain signed 64-bit integers need no converted type.
+		return parquetTypeInt64, -1
+	case parquetTimestampMillis:
+		return parquetTypeInt64, parquetConvertedTimestampMillis
+	default:
+		return parquetTypeByteArray, parquetConvertedUTF8
+	}
+}
+
+// Thrift compact protocol type identifiers.
+const (
+	thriftTypeI32    = 5
+	thriftTypeI64    = 6
+	thriftTypeBinary = 8
+	thriftTypeList   = 9
+	thriftTypeStruct = 12
+)
+
+// thriftCompactWriter serializes Thrift structs using the compact protocol.
+// The writer starts inside the top-level struct. Nested structs are opened
+// with fieldStructBegin, or structBegin for list elements, and every struct,
+// including the top-level one, is closed with structEnd.
+type thriftCompactWriter struct {
+	buf       bytes.Buffer
+	lastField []int16
+	current   int16
+}
+
+func (t *thriftCompactWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.current; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(zigzag(int64(id)))
+	}
+	t.current = id
+}
+
+func (t *thriftCompactWriter) varint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	t.buf.Write(tmp[:n])
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63)) //nolint:gosec // zigzag encoding is defined on the bit pattern
+}
+
+func (t *thriftCompactWriter) i32(v int32) {
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftCompactWriter) binary(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftCompactWriter) fieldI32(id int16, v int32) {
+	t.fieldHeader(id, thriftTypeI32)
+	t.i32(v)
+}
+
+func (t *thriftCompactWriter) fieldI64(id int16, v int64) {
+	t.fieldHeader(id, thriftTypeI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftCompactWriter) fieldString(id int16, s string) {
+	t.fieldHeader(id, thriftTypeBinary)
+	t.binary(s)
+}
+
+func (t *thriftCompactWriter) fieldStructBegin(id int16) {
+	t.fieldHeader(id, thriftTypeStruct)
+	t.structBegin()
+}
+
+// fieldListBegin writes a list field header. The caller then writes size elements.
+func (t *thriftCompactWriter) fieldListBegin(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftTypeList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		t.varint(uint64(size))
+	}
+}
+
+// structBegin starts a nested struct.
+func (t *thriftCompactWriter) structBegin() {
+	t.lastField = append(t.lastField, t.current)
+	t.current = 0
+}
+
+// structEnd writes the stop field of the current struct.
+func (t *thriftCompactWriter) structEnd() {
+	t.buf.WriteByte(0)
+	if n := len(t.lastField); n > 0 {
+		t.current = t.lastField[n-1]
+		t.lastField = t.lastField[:n-1]
+	}
+}
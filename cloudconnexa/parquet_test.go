@@ -0,0 +1,213 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// thriftCompactReader decodes Thrift compact structs into maps keyed by field ID,
+// enough to check the metadata written by parquetWriter.
+type thriftCompactReader struct {
+	r *bytes.Reader
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1) //nolint:gosec // zigzag decoding is defined on the bit pattern
+}
+
+func (t *thriftCompactReader) readStruct() (map[int16]any, error) {
+	fields := map[int16]any{}
+	var last int16
+	for {
+		b, err := t.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0 {
+			return fields, nil
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := binary.ReadUvarint(t.r)
+			if err != nil {
+				return nil, err
+			}
+			id = int16(unzigzag(v)) //nolint:gosec // field IDs are small
+		}
+		last = id
+		value, err := t.readValue(b & 0x0f)
+		if err != nil {
+			return nil, err
+		}
+		fields[id] = value
+	}
+}
+
+func (t *thriftCompactReader) readValue(typ byte) (any, error) {
+	switch typ {
+	case thriftTypeI32, thriftTypeI64:
+		v, err := binary.ReadUvarint(t.r)
+		return unzigzag(v), err
+	case thriftTypeBinary:
+		n, err := binary.ReadUvarint(t.r)
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, n)
+		_, err = t.r.Read(data)
+		return string(data), err
+	case thriftTypeList:
+		header, err := t.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			if size, err = binary.ReadUvarint(t.r); err != nil {
+				return nil, err
+			}
+		}
+		list := make([]any, 0, size)
+		for range size {
+			v, err := t.readValue(header & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case thriftTypeStruct:
+		return t.readStruct()
+	default:
+		return nil, fmt.Errorf("unsupported thrift type %d", typ)
+	}
+}
+
+// readParquetFooter checks the magic bytes and footer length of a Parquet
+// file and decodes its FileMetaData.
+func readParquetFooter(t *testing.T, data []byte) map[int16]any {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte(parquetMagic)) || !bytes.HasSuffix(data, []byte(parquetMagic)) {
+		t.Fatal("Expected Parquet magic at start and end of file")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerLen
+	if footerStart < len(parquetMagic) {
+		t.Fatalf("Invalid footer length %d", footerLen)
+	}
+	footer := &thriftCompactReader{r: bytes.NewReader(data[footerStart : len(data)-8])}
+	meta, err := footer.readStruct()
+	if err != nil {
+		t.Fatalf("Decoding FileMetaData failed: %v", err)
+	}
+	if footer.r.Len() != 0 {
+		t.Errorf("Expected the footer length to cover FileMetaData exactly, %d bytes left", footer.r.Len())
+	}
+	return meta
+}
+
+func TestParquetWriter_RoundTrip(t *testing.T) {
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	rows := [][]any{
+		{"alice", int64(1), base},
+		{"bob, jr", int64(-2), base.Add(time.Second)},
+		{"", int64(3), base.Add(time.Minute)},
+		{"carol", int64(1 << 40), base.Add(time.Hour)},
+		{"dave", int64(0), base.Add(24 * time.Hour)},
+	}
+	columns := []parquetColumn{{"name", parquetString}, {"count", parquetInt64}, {"start", parquetTimestampMillis}}
+
+	var buf bytes.Buffer
+	w := newParquetWriter(&buf, columns)
+	w.rowGroupSize = 2
+	for _, row := range rows {
+		if err := w.WriteRow(row...); err != nil {
+			t.Fatalf("WriteRow failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data := buf.Bytes()
+	meta := readParquetFooter(t, data)
+	if meta[1] != int64(1) || meta[3] != int64(len(rows)) || meta[6] != userAgent {
+		t.Errorf("Unexpected version, num_rows or created_by: %v, %v, %v", meta[1], meta[3], meta[6])
+	}
+	schema := meta[2].([]any)
+	if len(schema) != len(columns)+1 || schema[0].(map[int16]any)[5] != int64(len(columns)) {
+		t.Fatalf("Expected a root schema element with %d children, got %v", len(columns), schema)
+	}
+	for i, c := range columns {
+		element := schema[i+1].(map[int16]any)
+		physical, _ := parquetTypes(c.kind)
+		if element[4] != c.name || element[1] != int64(physical) {
+			t.Errorf("Unexpected schema element %v for column %s", element, c.name)
+		}
+	}
+
+	groups := meta[4].([]any)
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 row groups, got %d", len(groups))
+	}
+	row := 0
+	for g, group := range groups {
+		group := group.(map[int16]any)
+		groupRows := int(group[3].(int64))
+		if want := min(2, len(rows)-row); groupRows != want {
+			t.Errorf("Row group %d: expected %d rows, got %d", g, want, groupRows)
+		}
+		var chunkBytes int64
+		for c, chunk := range group[1].([]any) {
+			chunkMeta := chunk.(map[int16]any)[3].(map[int16]any)
+			offset, size := chunkMeta[9].(int64), chunkMeta[7].(int64)
+			chunkBytes += size
+			if chunkMeta[5] != int64(groupRows) {
+				t.Errorf("Row group %d column %d: expected %d values, got %v", g, c, groupRows, chunkMeta[5])
+			}
+
+			page := &thriftCompactReader{r: bytes.NewReader(data[offset : offset+size])}
+			header, err := page.readStruct()
+			if err != nil {
+				t.Fatalf("Decoding page header failed: %v", err)
+			}
+			if header[5].(map[int16]any)[1] != int64(groupRows) || int(header[3].(int64)) != page.r.Len() {
+				t.Fatalf("Row group %d column %d: unexpected page header %v", g, c, header)
+			}
+			values := data[offset+size-int64(page.r.Len()) : offset+size]
+			for r := range groupRows {
+				want := rows[row+r][c]
+				switch columns[c].kind {
+				case parquetString:
+					n := binary.LittleEndian.Uint32(values)
+					if got := string(values[4 : 4+n]); got != want {
+						t.Errorf("Row %d column %d: expected %q, got %q", row+r, c, want, got)
+					}
+					values = values[4+n:]
+				default:
+					got := int64(binary.LittleEndian.Uint64(values)) //nolint:gosec // two's complement decoding is intended
+					if ts, ok := want.(time.Time); ok {
+						want = ts.UnixMilli()
+					}
+					if got != want {
+						t.Errorf("Row %d column %d: expected %v, got %d", row+r, c, want, got)
+					}
+					values = values[8:]
+				}
+			}
+			if len(values) != 0 {
+				t.Errorf("Row group %d column %d: %d trailing bytes", g, c, len(values))
+			}
+		}
+		if group[2] != chunkBytes {
+			t.Errorf("Row group %d: expected total size %d, got %v", g, chunkBytes, group[2])
+		}
+		row += groupRows
+	}
+	if row != len(rows) {
+		t.Errorf("Expected %d rows across row groups, got %d", len(rows), row)
+	}
+}
@@ -0,0 +1,292 @@
+package cloudconnexa
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Predicate reports whether an item matches a query.
+type Predicate[T any] func(item T) bool
+
+// Where matches items whose field, as returned by field, equals value.
+func Where[T any, V comparable](field func(T) V, value V) Predicate[T] {
+	return func(item T) bool { return field(item) == value }
+}
+
+// NameEquals matches items whose Name, or Username for users, equals name.
+func NameEquals[T any](name string) Predicate[T] {
+	return func(item T) bool { return nameOf(item) == name }
+}
+
+// NamePrefix matches items whose Name, or Username for users, starts with prefix.
+func NamePrefix[T any](prefix string) Predicate[T] {
+	return func(item T) bool { return strings.HasPrefix(nameOf(item), prefix) }
+}
+
+// NameMatches matches items whose Name, or Username for users, matches re.
+func NameMatches[T any](re *regexp.Regexp) Predicate[T] {
+	return func(item T) bool { return re.MatchString(nameOf(item)) }
+}
+
+// FieldEquals matches items with a top-level field equal to value. The field
+// is identified by its Go name or JSON name, e.g. "GroupID" or "groupId", and
+// value may be of any type convertible to the field's type. Items without the
+// field never match.
+func FieldEquals[T any](field string, value any) Predicate[T] {
+	want := reflect.ValueOf(value)
+	return func(item T) bool {
+		got, ok := fieldByName(reflect.ValueOf(item), field)
+		if !ok || !want.IsValid() {
+			return false
+		}
+		if want.Type().ConvertibleTo(got.Type()) {
+			return reflect.DeepEqual(got.Interface(), want.Convert(got.Type()).Interface())
+		}
+		return reflect.DeepEqual(got.Interface(), value)
+	}
+}
+
+// And matches items matching all of preds.
+func And[T any](preds ...Predicate[T]) Predicate[T] {
+	return func(item T) bool { return matchesAll(item, preds) }
+}
+
+// Or matches items matching any of preds.
+func Or[T any](preds ...Predicate[T]) Predicate[T] {
+	return func(item T) bool {
+		for _, p := range preds {
+			if p(item) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches items not matching pred.
+func Not[T any](pred Predicate[T]) Predicate[T] {
+	return func(item T) bool { return !pred(item) }
+}
+
+func matchesAll[T any](item T, preds []Predicate[T]) bool {
+	for _, p := range preds {
+		if p != nil && !p(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// nameOf returns the Name field of v, or its Username field if it has no Name.
+func nameOf(v any) string {
+	rv := reflect.ValueOf(v)
+	for _, name := range []string{"Name", "Username"} {
+		if f, ok := fieldByName(rv, name); ok && f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ""
+}
+
+// fieldByName returns the field of struct v with the given Go or JSON name.
+func fieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	if f := v.FieldByName(name); f.IsValid() && f.CanInterface() {
+		return f, true
+	}
+	t := v.Type()
+	for i := range t.NumField() {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if tag == name && t.Field(i).IsExported() {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// pageLister fetches one page of a listing and reports the total number of pages.
+type pageLister[T any] func(page, size int) (items []T, totalPages int, err error)
+
+// query pages through a listing and returns the items matching all preds.
+// If limit is positive, it stops fetching pages once limit items matched.
+func query[T any](list pageLister[T], limit int, preds []Predicate[T]) ([]T, error) {
+	var matched []T
+	for page := 0; ; page++ {
+		items, totalPages, err := list(page, defaultPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if !matchesAll(item, preds) {
+				continue
+			}
+			matched = append(matched, item)
+			if limit > 0 && len(matched) >= limit {
+				return matched, nil
+			}
+		}
+		if page+1 >= totalPages {
+			return matched, nil
+		}
+	}
+}
+
+// Index is an in-memory index of a collection keyed by a string field such as
+// a name. The collection is loaded on first use and reloaded once the TTL has
+// passed or after Invalidate; a TTL of zero or less never expires.
+// An Index is safe for concurrent use.
+type Index[T any] struct {
+	load func() ([]T, error)
+	key  func(T) string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu       sync.Mutex
+	items    []T
+	byKey    map[string][]int
+	loadedAt time.Time
+}
+
+// NewIndex creates an index of the items returned by load, keyed by key.
+func NewIndex[T any](load func() ([]T, error), key func(T) string, ttl time.Duration) *Index[T] {
+	return &Index[T]{load: load, key: key, ttl: ttl, now: time.Now}
+}
+
+// refresh loads the collection if it is not loaded or has expired. ix.mu must be held.
+func (ix *Index[T]) refresh() error {
+	if ix.byKey != nil && (ix.ttl <= 0 || ix.now().Sub(ix.loadedAt) < ix.ttl) {
+		return nil
+	}
+	items, err := ix.load()
+	if err != nil {
+		return err
+	}
+	byKey := make(map[string][]int, len(items))
+	for i, item := range items {
+		k := ix.key(item)
+		byKey[k] = append(byKey[k], i)
+	}
+	ix.items, ix.byKey, ix.loadedAt = items, byKey, ix.now()
+	return nil
+}
+
+// Lookup returns the items whose key equals key, in listing order.
+func (ix *Index[T]) Lookup(key string) ([]T, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if err := ix.refresh(); err != nil {
+		return nil, err
+	}
+	matched := make([]T, 0, len(ix.byKey[key]))
+	for _, i := range ix.byKey[key] {
+		matched = append(matched, ix.items[i])
+	}
+	return matched, nil
+}
+
+// Query returns the indexed items matching all preds.
+func (ix *Index[T]) Query(preds ...Predicate[T]) ([]T, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if err := ix.refresh(); err != nil {
+		return nil, err
+	}
+	var matched []T
+	for _, item := range ix.items {
+		if matchesAll(item, preds) {
+			matched = append(matched, item)
+		}
+	}
+	return matched, nil
+}
+
+// Invalidate discards the indexed items so that the next lookup reloads them.
+func (ix *Index[T]) Invalidate() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.items, ix.byKey = nil, nil
+}
+
+// lookupIndex returns the client's index for collection, creating it on first
+// use, or nil if Client.LookupIndexTTL is not set.
+func lookupIndex[T any](c *Client, collection string, load func() ([]T, error), key func(T) string) *Index[T] {
+	if c.LookupIndexTTL <= 0 {
+		return nil
+	}
+	if ix, ok := c.lookupIndexes.Load(collection); ok {
+		return ix.(*Index[T])
+	}
+	ix, _ := c.lookupIndexes.LoadOrStore(collection, NewIndex(load, key, c.LookupIndexTTL))
+	return ix.(*Index[T])
+}
+
+// InvalidateLookupIndexes discards all lookup indexes of the client. Writes
+// made through the client invalidate the indexes of the affected collection
+// automatically; this is needed only after changes made elsewhere.
+func (c *Client) InvalidateLookupIndexes() {
+	c.lookupIndexes.Range(func(_, ix any) bool {
+		ix.(interface{ Invalidate() }).Invalidate()
+		return true
+	})
+}
+
+// invalidateLookupIndexes discards the lookup indexes of the collection a
+// write to path affects, e.g. "networks" and "networks/connectors" for a
+// write to /api/v1/networks/{id}/routes, in this client and in the client it
+// was derived from with WithoutCache.
+func (c *Client) invalidateLookupIndexes(path string) {
+	resource := apiResource(path)
+	for ; c != nil; c = c.indexParent {
+		c.lookupIndexes.Range(func(collection, ix any) bool {
+			if name, _, _ := strings.Cut(collection.(string), "/"); name == resource {
+				ix.(interface{ Invalidate() }).Invalidate()
+			}
+			return true
+		})
+	}
+}
+
+// queryCollection returns the items of a collection matching all preds, from
+// the client's lookup index for collection when enabled.
+func queryCollection[T any](c *Client, collection string, load func() ([]T, error), list pageLister[T], key func(T) string, preds []Predicate[T]) ([]T, error) {
+	if ix := lookupIndex(c, collection, load, key); ix != nil {
+		return ix.Query(preds...)
+	}
+	return query(list, 0, preds)
+}
+
+// findByKey returns up to limit items whose key equals value and that match
+// all preds. It uses the client's lookup index for collection when enabled
+// and otherwise stops paging once limit items matched.
+func findByKey[T any](c *Client, collection string, load func() ([]T, error), list pageLister[T], key func(T) string, value string, limit int, preds ...Predicate[T]) ([]T, error) {
+	if ix := lookupIndex(c, collection, load, key); ix != nil {
+		items, err := ix.Lookup(value)
+		if err != nil {
+			return nil, err
+		}
+		var matched []T
+		for _, item := range items {
+			if matchesAll(item, preds) && (limit <= 0 || len(matched) < limit) {
+				matched = append(matched, item)
+			}
+		}
+		return matched, nil
+	}
+	return query(list, limit, append([]Predicate[T]{Where(key, value)}, preds...))
+}
+
+// nameKey is the index key of items looked up by GetByName and GetByUsername.
+func nameKey[T any](item T) string {
+	return nameOf(item)
+}
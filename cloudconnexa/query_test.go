@@ -0,0 +1,181 @@
+package cloudconnexa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPredicates(t *testing.T) {
+	users := []User{
+		{Username: "alice", Role: "ADMIN", GroupID: "ug-1"},
+		{Username: "bob", Role: "MEMBER", GroupID: "ug-1"},
+		{Username: "carol", Role: "MEMBER", GroupID: "ug-2"},
+	}
+	tests := []struct {
+		name     string
+		pred     Predicate[User]
+		expected string
+	}{
+		{"name", NameEquals[User]("bob"), "bob"},
+		{"prefix", NamePrefix[User]("ca"), "carol"},
+		{"regexp", NameMatches[User](regexp.MustCompile(`^(alice|carol)$`)), "alice,carol"},
+		{"json field", FieldEquals[User]("groupId", "ug-1"), "alice,bob"},
+		{"go field", FieldEquals[User]("Role", "ADMIN"), "alice"},
+		{"missing field", FieldEquals[User]("nope", "x"), ""},
+		{"where", Where(func(u User) string { return u.GroupID }, "ug-2"), "carol"},
+		{"and", And(FieldEquals[User]("role", "MEMBER"), Not(NameEquals[User]("bob"))), "carol"},
+		{"or", Or(NameEquals[User]("alice"), NameEquals[User]("carol")), "alice,carol"},
+	}
+	for _, tt := range tests {
+		var names []string
+		for _, u := range users {
+			if tt.pred(u) {
+				names = append(names, u.Username)
+			}
+		}
+		if got := fmt.Sprint(names); got != fmt.Sprint(splitNonEmpty(tt.expected)) {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+	if !NameEquals[*Network]("net")(&Network{Name: "net"}) {
+		t.Error("Expected NameEquals to match a pointer")
+	}
+}
+
+func splitNonEmpty(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func TestIndex_TTL(t *testing.T) {
+	loads := 0
+	now := time.Unix(0, 0)
+	ix := NewIndex(func() ([]Network, error) {
+		loads++
+		return []Network{{ID: "n-" + strconv.Itoa(loads), Name: "net"}, {ID: "n-x", Name: "other"}}, nil
+	}, nameKey[Network], time.Minute)
+	ix.now = func() time.Time { return now }
+
+	for range 3 {
+		if items, err := ix.Lookup("net"); err != nil || len(items) != 1 || items[0].ID != "n-1" {
+			t.Fatalf("Unexpected lookup %+v, %v", items, err)
+		}
+	}
+	now = now.Add(time.Minute)
+	if items, _ := ix.Lookup("net"); items[0].ID != "n-2" {
+		t.Errorf("Expected a reload after the TTL, got %+v", items)
+	}
+	ix.Invalidate()
+	if items, _ := ix.Query(NamePrefix[Network]("o")); len(items) != 1 || loads != 3 {
+		t.Errorf("Expected a reload after Invalidate, got %+v after %d loads", items, loads)
+	}
+}
+
+type queryNetworksAPI struct {
+	mu    sync.Mutex
+	pages []int
+	names []string
+}
+
+func (a *queryNetworksAPI) server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if r.Method == http.MethodPost {
+			var n Network
+			_ = json.NewDecoder(r.Body).Decode(&n)
+			a.names = append(a.names, n.Name)
+			_ = json.NewEncoder(w).Encode(n)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		a.pages = append(a.pages, page)
+		var content []Network
+		for i, name := range a.names {
+			if i/2 == page {
+				content = append(content, Network{ID: "n-" + name, Name: name})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(NetworkPageResponse{Content: content, TotalPages: (len(a.names) + 1) / 2})
+	}))
+}
+
+func (a *queryNetworksAPI) requests() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.pages)
+}
+
+func TestNetworksService_GetByName_StopsEarly(t *testing.T) {
+	// Two networks per page and a page size the fake server ignores.
+	api := &queryNetworksAPI{names: []string{"a", "b", "c", "d", "e"}}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+
+	network, err := client.Networks.GetByName("c")
+	if err != nil || network.ID != "n-c" {
+		t.Fatalf("Unexpected network %+v, %v", network, err)
+	}
+	if api.requests() != 2 {
+		t.Errorf("Expected paging to stop after the match, got pages %v", api.pages)
+	}
+	if _, err := client.Networks.GetByName("z"); !errors.Is(err, ErrNetworkNotFound) {
+		t.Errorf("Expected ErrNetworkNotFound, got %v", err)
+	}
+	networks, err := client.Networks.Query(Or(NameEquals[Network]("a"), NameEquals[Network]("e")))
+	if err != nil || len(networks) != 2 {
+		t.Errorf("Unexpected query result %+v, %v", networks, err)
+	}
+}
+
+func TestNetworksService_GetByName_LookupIndex(t *testing.T) {
+	api := &queryNetworksAPI{names: []string{"a", "b", "c"}}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+	client.LookupIndexTTL = time.Hour
+
+	for _, name := range []string{"a", "b", "c", "a"} {
+		if network, err := client.Networks.GetByName(name); err != nil || network.Name != name {
+			t.Fatalf("Unexpected network %+v, %v", network, err)
+		}
+	}
+	if _, err := client.Networks.GetByName("d"); !errors.Is(err, ErrNetworkNotFound) {
+		t.Errorf("Expected ErrNetworkNotFound, got %v", err)
+	}
+	if api.requests() != 2 {
+		t.Errorf("Expected a single listing, got pages %v", api.pages)
+	}
+
+	if _, err := client.Networks.Create(Network{Name: "d"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if network, err := client.Networks.GetByName("d"); err != nil || network.Name != "d" {
+		t.Errorf("Expected the index to be reloaded after a write, got %+v, %v", network, err)
+	}
+	if api.requests() != 4 {
+		t.Errorf("Expected a second listing, got pages %v", api.pages)
+	}
+
+	if _, err := client.WithoutCache().Networks.Create(Network{Name: "e"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if network, err := client.Networks.GetByName("e"); err != nil || network.Name != "e" {
+		t.Errorf("Expected a write through WithoutCache to reload the index, got %+v, %v", network, err)
+	}
+}
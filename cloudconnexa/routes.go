@@ -136,6 +136,9 @@ func (c *RoutesService) Create(networkID string, route Route) (*Route, error) {
 	if err := validateID(networkID); err != nil {
 		return nil, err
 	}
+	if err := c.client.validateEnum("route type", route.Type, routeTypeValues); err != nil {
+		return nil, err
+	}
 	type newRoute struct {
 		Description string `json:"description"`
 		Value       string `json:"value"`
@@ -181,6 +184,9 @@ func (c *RoutesService) Update(route Route) error {
 	if err := validateID(route.ID); err != nil {
 		return err
 	}
+	if err := c.client.validateEnum("route type", route.Type, routeTypeValues); err != nil {
+		return err
+	}
 	type updatedRoute struct {
 		Description string `json:"description"`
 		Value       string `json:"value"`
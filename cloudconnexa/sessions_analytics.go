@@ -0,0 +1,203 @@
+package cloudconnexa
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Percentiles holds nearest-rank percentiles of a distribution.
+type Percentiles struct {
+	P50 int64 `json:"p50"`
+	P90 int64 `json:"p90"`
+	P95 int64 `json:"p95"`
+	P99 int64 `json:"p99"`
+	Max int64 `json:"max"`
+}
+
+// SessionStats aggregates the sessions of one group.
+type SessionStats struct {
+	// Key identifies the group: a user or region name (falling back to its ID),
+	// a network name, a device ID, or the RFC 3339 start of an hour or day bucket.
+	Key string `json:"key"`
+	// Name is the device name of device groups, for display only.
+	Name      string `json:"name,omitempty"`
+	Sessions  int    `json:"sessions"`
+	Active    int    `json:"active"`
+	Completed int    `json:"completed"`
+	Failed    int    `json:"failed"`
+	BytesIn   int64  `json:"bytesIn"`
+	BytesOut  int64  `json:"bytesOut"`
+	// BytesTotal is BytesIn + BytesOut.
+	BytesTotal int64 `json:"bytesTotal"`
+	// BytesPerSession is the distribution of BytesIn+BytesOut over the group's sessions.
+	BytesPerSession Percentiles `json:"bytesPerSession"`
+	// FailureRatio is Failed / (Failed + Completed), or 0 when neither occurred.
+	FailureRatio float64 `json:"failureRatio"`
+}
+
+// SessionAnalytics is the result of AnalyzeSessions. Every grouping is sorted by Key.
+// ByNetwork leaves out sessions without a network name, as sessions carry no
+// network ID to group them by.
+type SessionAnalytics struct {
+	Total     SessionStats   `json:"total"`
+	ByUser    []SessionStats `json:"byUser"`
+	ByRegion  []SessionStats `json:"byRegion"`
+	ByNetwork []SessionStats `json:"byNetwork"`
+	ByDevice  []SessionStats `json:"byDevice"`
+	ByHour    []SessionStats `json:"byHour"`
+	ByDay     []SessionStats `json:"byDay"`
+	// SessionsPerUser is the distribution of session counts over users.
+	SessionsPerUser Percentiles `json:"sessionsPerUser"`
+}
+
+// SessionAnalyticsOptions configures AnalyzeSessions.
+type SessionAnalyticsOptions struct {
+	// Location is the time zone used for hour and day buckets. Defaults to UTC.
+	Location *time.Location
+}
+
+// Analyze retrieves all sessions matching options and aggregates them with AnalyzeSessions.
+func (s *SessionsService) Analyze(options SessionsListOptions, analyticsOptions *SessionAnalyticsOptions) (*SessionAnalytics, error) {
+	sessions, err := s.ListAll(options)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeSessions(sessions, analyticsOptions), nil
+}
+
+// AnalyzeSessions computes traffic totals, percentiles and status counts for sessions,
+// grouped by user, region, network, device, hour and day of StartDateTime.
+func AnalyzeSessions(sessions []Session, opts *SessionAnalyticsOptions) *SessionAnalytics {
+	loc := time.UTC
+	if opts != nil && opts.Location != nil {
+		loc = opts.Location
+	}
+
+	total := newSessionAccumulator("")
+	groupings := map[string]map[string]*sessionAccumulator{}
+	add := func(grouping, key, name string, s Session) {
+		groups, ok := groupings[grouping]
+		if !ok {
+			groups = map[string]*sessionAccumulator{}
+			groupings[grouping] = groups
+		}
+		acc, ok := groups[key]
+		if !ok {
+			acc = newSessionAccumulator(key)
+			acc.Name = name
+			groups[key] = acc
+		}
+		acc.add(s)
+	}
+
+	for _, s := range sessions {
+		total.add(s)
+		start := s.StartDateTime.In(loc)
+		add("user", firstNonEmpty(s.UserName, s.UserID), "", s)
+		add("region", firstNonEmpty(s.RegionName, s.RegionID), "", s)
+		if s.NetworkName != "" {
+			add("network", s.NetworkName, "", s)
+		}
+		add("device", sessionDeviceKey(s), s.DeviceName, s)
+		add("hour", time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), 0, 0, 0, loc).Format(time.RFC3339), "", s)
+		add("day", time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc).Format(time.RFC3339), "", s)
+	}
+
+	result := &SessionAnalytics{
+		Total:     total.stats(),
+		ByUser:    sortedSessionStats(groupings["user"]),
+		ByRegion:  sortedSessionStats(groupings["region"]),
+		ByNetwork: sortedSessionStats(groupings["network"]),
+		ByDevice:  sortedSessionStats(groupings["device"]),
+		ByHour:    sortedSessionStats(groupings["hour"]),
+		ByDay:     sortedSessionStats(groupings["day"]),
+	}
+	perUser := make([]int64, len(result.ByUser))
+	for i, u := range result.ByUser {
+		perUser[i] = int64(u.Sessions)
+	}
+	result.SessionsPerUser = computePercentiles(perUser)
+	return result
+}
+
+// sessionDeviceKey identifies the device of s by ID. Device names are only
+// unique per user, so sessions without a device ID fall back to the user and
+// device name.
+func sessionDeviceKey(s Session) string {
+	if s.DeviceID != "" {
+		return s.DeviceID
+	}
+	return firstNonEmpty(s.UserID, s.UserName) + "/" + s.DeviceName
+}
+
+type sessionAccumulator struct {
+	SessionStats
+	traffic []int64
+}
+
+func newSessionAccumulator(key string) *sessionAccumulator {
+	return &sessionAccumulator{SessionStats: SessionStats{Key: key}}
+}
+
+func (a *sessionAccumulator) add(s Session) {
+	a.Sessions++
+	a.BytesIn += s.BytesIn
+	a.BytesOut += s.BytesOut
+	a.traffic = append(a.traffic, s.BytesIn+s.BytesOut)
+	switch SessionStatus(s.ConnectionStatus) {
+	case SessionStatusActive:
+		a.Active++
+	case SessionStatusCompleted:
+		a.Completed++
+	case SessionStatusFailed:
+		a.Failed++
+	}
+}
+
+func (a *sessionAccumulator) stats() SessionStats {
+	stats := a.SessionStats
+	stats.BytesTotal = stats.BytesIn + stats.BytesOut
+	stats.BytesPerSession = computePercentiles(a.traffic)
+	if finished := stats.Failed + stats.Completed; finished > 0 {
+		stats.FailureRatio = float64(stats.Failed) / float64(finished)
+	}
+	return stats
+}
+
+func sortedSessionStats(groups map[string]*sessionAccumulator) []SessionStats {
+	result := make([]SessionStats, 0, len(groups))
+	for _, acc := range groups {
+		result = append(result, acc.stats())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// computePercentiles returns nearest-rank percentiles of values. values is sorted in place.
+func computePercentiles(values []int64) Percentiles {
+	if len(values) == 0 {
+		return Percentiles{}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	rank := func(p float64) int64 {
+		i := int(math.Ceil(p/100*float64(len(values)))) - 1
+		return values[max(i, 0)]
+	}
+	return Percentiles{
+		P50: rank(50),
+		P90: rank(90),
+		P95: rank(95),
+		P99: rank(99),
+		Max: values[len(values)-1],
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
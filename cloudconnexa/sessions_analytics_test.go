@@ -0,0 +1,93 @@
+package cloudconnexa
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnalyzeSessions(t *testing.T) {
+	base := time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)
+	sessions := []Session{
+		{UserName: "alice", RegionName: "eu", NetworkName: "office", DeviceName: "laptop", BytesIn: 100, BytesOut: 50, StartDateTime: base, ConnectionStatus: "COMPLETED"},
+		{UserName: "alice", RegionName: "eu", NetworkName: "office", DeviceName: "laptop", BytesIn: 300, BytesOut: 0, StartDateTime: base.Add(30 * time.Minute), ConnectionStatus: "FAILED"},
+		{UserName: "alice", RegionName: "us", DeviceName: "phone", BytesIn: 10, BytesOut: 10, StartDateTime: base.Add(2 * time.Hour), ConnectionStatus: "ACTIVE"},
+		{UserID: "user-bob", RegionID: "region-us", DeviceID: "device-1", BytesIn: 1000, BytesOut: 1000, StartDateTime: base.Add(24 * time.Hour), ConnectionStatus: "COMPLETED"},
+	}
+
+	result := AnalyzeSessions(sessions, nil)
+
+	if result.Total.Sessions != 4 || result.Total.BytesTotal != 2470 {
+		t.Errorf("Unexpected totals: %+v", result.Total)
+	}
+	if result.Total.Active != 1 || result.Total.Completed != 2 || result.Total.Failed != 1 {
+		t.Errorf("Unexpected status counts: %+v", result.Total)
+	}
+	if got := result.Total.FailureRatio; got < 0.33 || got > 0.34 {
+		t.Errorf("Expected failure ratio 1/3, got %f", got)
+	}
+	if p := result.Total.BytesPerSession; p.P50 != 150 || p.Max != 2000 {
+		t.Errorf("Unexpected traffic percentiles: %+v", p)
+	}
+
+	if len(result.ByUser) != 2 || result.ByUser[0].Key != "alice" || result.ByUser[1].Key != "user-bob" {
+		t.Fatalf("Unexpected user groups: %+v", result.ByUser)
+	}
+	if result.ByUser[0].Sessions != 3 || result.ByUser[0].BytesIn != 410 {
+		t.Errorf("Unexpected stats for alice: %+v", result.ByUser[0])
+	}
+	if len(result.ByRegion) != 3 {
+		t.Errorf("Expected 3 regions, got %+v", result.ByRegion)
+	}
+	if len(result.ByNetwork) != 1 || result.ByNetwork[0].Key != "office" || result.ByNetwork[0].Sessions != 2 {
+		t.Errorf("Expected only sessions with a network name in network groups, got %+v", result.ByNetwork)
+	}
+	if len(result.ByHour) != 3 || result.ByHour[0].Key != "2026-03-01T10:00:00Z" || result.ByHour[0].Sessions != 2 {
+		t.Errorf("Unexpected hour buckets: %+v", result.ByHour)
+	}
+	if len(result.ByDay) != 2 || result.ByDay[1].Key != "2026-03-02T00:00:00Z" {
+		t.Errorf("Unexpected day buckets: %+v", result.ByDay)
+	}
+	if len(result.ByDevice) != 3 || result.ByDevice[0].Key != "alice/laptop" || result.ByDevice[0].Name != "laptop" || result.ByDevice[2].Key != "device-1" {
+		t.Errorf("Unexpected device groups: %+v", result.ByDevice)
+	}
+	if result.SessionsPerUser.Max != 3 || result.SessionsPerUser.P50 != 1 {
+		t.Errorf("Unexpected sessions per user: %+v", result.SessionsPerUser)
+	}
+}
+
+func TestAnalyzeSessions_DevicesWithSharedNames(t *testing.T) {
+	sessions := []Session{
+		{UserID: "u-1", DeviceID: "d-1", DeviceName: "laptop", BytesIn: 10},
+		{UserID: "u-2", DeviceID: "d-2", DeviceName: "laptop", BytesIn: 20},
+		{UserID: "u-2", DeviceID: "d-2", DeviceName: "laptop", BytesIn: 30},
+	}
+
+	result := AnalyzeSessions(sessions, nil)
+
+	if len(result.ByDevice) != 2 {
+		t.Fatalf("Expected one group per device, got %+v", result.ByDevice)
+	}
+	if d := result.ByDevice[1]; d.Key != "d-2" || d.Name != "laptop" || d.Sessions != 2 || d.BytesIn != 50 {
+		t.Errorf("Unexpected stats for d-2: %+v", d)
+	}
+}
+
+func TestAnalyzeSessions_Location(t *testing.T) {
+	loc := time.FixedZone("IST", 5*3600+1800)
+	sessions := []Session{{StartDateTime: time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)}}
+
+	result := AnalyzeSessions(sessions, &SessionAnalyticsOptions{Location: loc})
+
+	if result.ByHour[0].Key != "2026-03-02T01:00:00+05:30" {
+		t.Errorf("Unexpected hour bucket %s", result.ByHour[0].Key)
+	}
+	if result.ByDay[0].Key != "2026-03-02T00:00:00+05:30" {
+		t.Errorf("Unexpected day bucket %s", result.ByDay[0].Key)
+	}
+}
+
+func TestComputePercentiles_Empty(t *testing.T) {
+	if p := computePercentiles(nil); p != (Percentiles{}) {
+		t.Errorf("Expected zero percentiles, got %+v", p)
+	}
+}
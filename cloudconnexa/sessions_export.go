@@ -0,0 +1,334 @@
+package cloudconnexa
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SessionExportFormat is the file format produced by SessionsService.Export.
+type SessionExportFormat string
+
+const (
+	// SessionExportCSV writes RFC 4180 CSV with a header row.
+	SessionExportCSV SessionExportFormat = "csv"
+	// SessionExportNDJSON writes one JSON object per line.
+	SessionExportNDJSON SessionExportFormat = "ndjson"
+	// SessionExportParquet writes an Apache Parquet file.
+	SessionExportParquet SessionExportFormat = "parquet"
+)
+
+// SessionExportColumns is the stable column schema shared by all export formats.
+// New columns are only ever appended.
+var SessionExportColumns = []string{
+	"session_id",
+	"start_time",
+	"user_id",
+	"user_name",
+	"device_id",
+	"device_name",
+	"region_id",
+	"region_name",
+	"network_name",
+	"connector_name",
+	"client_ip",
+	"vpn_ipv4",
+	"connection_status",
+	"bytes_in",
+	"bytes_out",
+}
+
+// sessionExportRecord is the NDJSON representation of a session; field order matches SessionExportColumns.
+type sessionExportRecord struct {
+	SessionID        string `json:"session_id"`
+	StartTime        string `json:"start_time"`
+	UserID           string `json:"user_id"`
+	UserName         string `json:"user_name"`
+	DeviceID         string `json:"device_id"`
+	DeviceName       string `json:"device_name"`
+	RegionID         string `json:"region_id"`
+	RegionName       string `json:"region_name"`
+	NetworkName      string `json:"network_name"`
+	ConnectorName    string `json:"connector_name"`
+	ClientIP         string `json:"client_ip"`
+	VpnIPv4          string `json:"vpn_ipv4"`
+	ConnectionStatus string `json:"connection_status"`
+	BytesIn          int64  `json:"bytes_in"`
+	BytesOut         int64  `json:"bytes_out"`
+}
+
+func newSessionExportRecord(s Session) sessionExportRecord {
+	return sessionExportRecord{
+		SessionID:        s.SessionID,
+		StartTime:        s.StartDateTime.UTC().Format(time.RFC3339),
+		UserID:           s.UserID,
+		UserName:         s.UserName,
+		DeviceID:         s.DeviceID,
+		DeviceName:       s.DeviceName,
+		RegionID:         s.RegionID,
+		RegionName:       s.RegionName,
+		NetworkName:      s.NetworkName,
+		ConnectorName:    s.ConnectorName,
+		ClientIP:         s.ClientIP,
+		VpnIPv4:          s.VpnIPv4,
+		ConnectionStatus: s.ConnectionStatus,
+		BytesIn:          s.BytesIn,
+		BytesOut:         s.BytesOut,
+	}
+}
+
+// SessionRecordWriter writes sessions in one of the export formats.
+// Close must be called to flush buffered output; it does not close the underlying writer.
+type SessionRecordWriter interface {
+	WriteSession(s Session) error
+	Close() error
+}
+
+// NewSessionRecordWriter returns a SessionRecordWriter for format writing to w.
+// When header is false the CSV header row is omitted, which allows appending to an existing file.
+func NewSessionRecordWriter(w io.Writer, format SessionExportFormat, header bool) (SessionRecordWriter, error) {
+	switch format {
+	case SessionExportCSV:
+		cw := csv.NewWriter(w)
+		if header {
+			if err := cw.Write(SessionExportColumns); err != nil {
+				return nil, err
+			}
+		}
+		return &csvSessionWriter{w: cw}, nil
+	case SessionExportNDJSON:
+		return &ndjsonSessionWriter{enc: json.NewEncoder(w)}, nil
+	case SessionExportParquet:
+		columns := make([]parquetColumn, len(SessionExportColumns))
+		for i, name := range SessionExportColumns {
+			columns[i] = parquetColumn{name: name, kind: parquetString}
+		}
+		columns[1].kind = parquetTimestampMillis
+		columns[len(columns)-2].kind = parquetInt64
+		columns[len(columns)-1].kind = parquetInt64
+		return &parquetSessionWriter{pw: newParquetWriter(w, columns)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported session export format %q", format)
+	}
+}
+
+type csvSessionWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvSessionWriter) WriteSession(s Session) error {
+	r := newSessionExportRecord(s)
+	return c.w.Write([]string{
+		r.SessionID, r.StartTime, r.UserID, r.UserName, r.DeviceID, r.DeviceName,
+		r.RegionID, r.RegionName, r.NetworkName, r.ConnectorName, r.ClientIP, r.VpnIPv4,
+		r.ConnectionStatus, strconv.FormatInt(r.BytesIn, 10), strconv.FormatInt(r.BytesOut, 10),
+	})
+}
+
+// Flush writes the buffered rows to the underlying writer.
+func (c *csvSessionWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvSessionWriter) Close() error {
+	return c.Flush()
+}
+
+type ndjsonSessionWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonSessionWriter) WriteSession(s Session) error {
+	return n.enc.Encode(newSessionExportRecord(s))
+}
+
+func (n *ndjsonSessionWriter) Close() error {
+	return nil
+}
+
+type parquetSessionWriter struct {
+	pw *parquetWriter
+}
+
+func (p *parquetSessionWriter) WriteSession(s Session) error {
+	r := newSessionExportRecord(s)
+	return p.pw.WriteRow(
+		r.SessionID, s.StartDateTime.UTC(), r.UserID, r.UserName, r.DeviceID, r.DeviceName,
+		r.RegionID, r.RegionName, r.NetworkName, r.ConnectorName, r.ClientIP, r.VpnIPv4,
+		r.ConnectionStatus, r.BytesIn, r.BytesOut,
+	)
+}
+
+func (p *parquetSessionWriter) Close() error {
+	return p.pw.Close()
+}
+
+// SessionExportOptions configures SessionsService.Export.
+type SessionExportOptions struct {
+	Format SessionExportFormat
+	// StartDate and EndDate restrict the export to a date range.
+	// In incremental mode they only apply to the first run, before a cursor was saved.
+	StartDate *time.Time
+	EndDate   *time.Time
+	Status    SessionStatus
+	// CursorStore enables incremental mode: the export resumes from the saved cursor,
+	// requests only new sessions and saves the last cursor once the output was flushed.
+	CursorStore SessionCursorStore
+	// OmitHeader suppresses the CSV header row.
+	OmitHeader bool
+}
+
+// ErrIncrementalParquetExport is returned by SessionsService.ExportFile for a Parquet
+// export with a CursorStore: a Parquet file cannot be appended to, so an incremental
+// run would replace the sessions exported by earlier runs.
+var ErrIncrementalParquetExport = errors.New("incremental export cannot write to a single Parquet file")
+
+// SessionExportResult describes an export. When Export fails it describes the
+// sessions written before the failure.
+type SessionExportResult struct {
+	Sessions int    `json:"sessions"`
+	Cursor   string `json:"cursor,omitempty"`
+}
+
+// Export streams sessions page by page to w in the requested format.
+// Timestamps are written in UTC.
+//
+// CSV and NDJSON output is flushed after every page. In incremental mode the
+// cursor is saved after each flushed page, so a failed export can be resumed
+// without writing the same sessions twice. Parquet output is only complete once
+// the footer was written, so its cursor is saved at the end of the export.
+// On error Export flushes what was written and returns the partial result.
+func (s *SessionsService) Export(w io.Writer, opts SessionExportOptions) (*SessionExportResult, error) {
+	writer, err := NewSessionRecordWriter(w, opts.Format, !opts.OmitHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	listOptions := SessionsListOptions{
+		StartDate: opts.StartDate,
+		EndDate:   opts.EndDate,
+		Status:    opts.Status,
+		Size:      100,
+	}
+	if opts.CursorStore != nil {
+		listOptions.ReturnOnlyNew = true
+		listOptions.Cursor, err = opts.CursorStore.LoadCursor()
+		if err != nil {
+			return nil, err
+		}
+		if listOptions.Cursor != "" {
+			listOptions.StartDate, listOptions.EndDate = nil, nil
+		}
+	}
+	// Parquet pages cannot be flushed on their own, so only other formats checkpoint per page.
+	checkpoint := opts.CursorStore != nil && opts.Format != SessionExportParquet
+
+	result := &SessionExportResult{Cursor: listOptions.Cursor}
+	flush := func() error {
+		if f, ok := writer.(interface{ Flush() error }); ok {
+			return f.Flush()
+		}
+		return nil
+	}
+	fail := func(err error) (*SessionExportResult, error) {
+		return result, errors.Join(err, flush())
+	}
+	for {
+		response, err := s.List(listOptions)
+		if err != nil {
+			return fail(err)
+		}
+		for _, session := range response.Sessions {
+			if err := writer.WriteSession(session); err != nil {
+				return fail(err)
+			}
+			result.Sessions++
+		}
+		if err := flush(); err != nil {
+			return result, err
+		}
+		if response.NextCursor != "" && response.NextCursor != result.Cursor {
+			if checkpoint {
+				if err := opts.CursorStore.SaveCursor(response.NextCursor); err != nil {
+					return result, err
+				}
+			}
+			result.Cursor = response.NextCursor
+		}
+
+		// Without ReturnOnlyNew the last page has no next cursor; with it the API keeps
+		// returning a cursor, so stop once a page is no longer full.
+		if response.NextCursor == "" || (listOptions.ReturnOnlyNew && len(response.Sessions) < listOptions.Size) {
+			break
+		}
+		listOptions.Cursor = response.NextCursor
+	}
+
+	if err := writer.Close(); err != nil {
+		return result, err
+	}
+	if opts.CursorStore != nil && !checkpoint && result.Cursor != "" {
+		if err := opts.CursorStore.SaveCursor(result.Cursor); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// ExportFile exports sessions to the file at path. CSV and NDJSON exports are appended
+// to an existing file, with the CSV header only written to an empty file, so nightly
+// incremental jobs can keep a single growing file. Parquet files cannot be appended
+// to: they are written to a temporary file that replaces path only once the export
+// succeeded, and incremental Parquet exports are rejected with ErrIncrementalParquetExport.
+func (s *SessionsService) ExportFile(path string, opts SessionExportOptions) (*SessionExportResult, error) {
+	if opts.Format == SessionExportParquet {
+		return s.exportParquetFile(path, opts)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // path is chosen by the caller
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if info.Size() > 0 {
+		opts.OmitHeader = true
+	}
+
+	result, err := s.Export(f, opts)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return result, err
+}
+
+func (s *SessionsService) exportParquetFile(path string, opts SessionExportOptions) (*SessionExportResult, error) {
+	if opts.CursorStore != nil {
+		return nil, ErrIncrementalParquetExport
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.Export(tmp, opts)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,286 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSessionExportServer(t *testing.T, cursors *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		*cursors = append(*cursors, cursor)
+		var response SessionsResponse
+		switch cursor {
+		case "":
+			response = SessionsResponse{
+				Sessions: []Session{{
+					SessionID:     "s-1",
+					UserName:      "alice",
+					StartDateTime: time.Date(2024, 3, 1, 10, 0, 0, 0, time.FixedZone("CET", 3600)),
+					BytesIn:       10,
+					BytesOut:      20,
+				}},
+				NextCursor: "c-1",
+			}
+		case "c-1":
+			response = SessionsResponse{
+				Sessions: []Session{{SessionID: "s-2", UserName: "bob, jr", StartDateTime: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)}},
+			}
+		default:
+			response = SessionsResponse{NextCursor: cursor}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestSessionsService_Export_CSV(t *testing.T) {
+	var cursors []string
+	server := newSessionExportServer(t, &cursors)
+	defer server.Close()
+
+	client := createTestSessionsClient(server)
+	var buf bytes.Buffer
+	result, err := client.Sessions.Export(&buf, SessionExportOptions{Format: SessionExportCSV})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Sessions != 2 {
+		t.Errorf("Expected 2 sessions, got %d", result.Sessions)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d records", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(SessionExportColumns, ",") {
+		t.Errorf("Unexpected header %v", records[0])
+	}
+	if records[1][1] != "2024-03-01T09:00:00Z" {
+		t.Errorf("Expected UTC start time, got %s", records[1][1])
+	}
+	if records[1][13] != "10" || records[1][14] != "20" {
+		t.Errorf("Unexpected byte counts %v", records[1][13:])
+	}
+	if records[2][3] != "bob, jr" {
+		t.Errorf("Expected quoted user name to round-trip, got %q", records[2][3])
+	}
+}
+
+func TestSessionsService_Export_NDJSON(t *testing.T) {
+	var cursors []string
+	server := newSessionExportServer(t, &cursors)
+	defer server.Close()
+
+	client := createTestSessionsClient(server)
+	var buf bytes.Buffer
+	if _, err := client.Sessions.Export(&buf, SessionExportOptions{Format: SessionExportNDJSON}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Invalid JSON line: %v", err)
+	}
+	if len(record) != len(SessionExportColumns) {
+		t.Errorf("Expected %d fields, got %d", len(SessionExportColumns), len(record))
+	}
+	if record["start_time"] != "2024-03-01T09:00:00Z" || record["bytes_out"] != float64(20) {
+		t.Errorf("Unexpected record %v", record)
+	}
+}
+
+func TestSessionsService_Export_Parquet(t *testing.T) {
+	var cursors []string
+	server := newSessionExportServer(t, &cursors)
+	defer server.Close()
+
+	client := createTestSessionsClient(server)
+	var buf bytes.Buffer
+	if _, err := client.Sessions.Export(&buf, SessionExportOptions{Format: SessionExportParquet}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data := buf.Bytes()
+	meta := readParquetFooter(t, data)
+	if meta[3] != int64(2) || len(meta[4].([]any)) != 1 {
+		t.Errorf("Expected 2 rows in one row group, got %v rows in %v", meta[3], meta[4])
+	}
+	schema := meta[2].([]any)
+	if len(schema) != len(SessionExportColumns)+1 {
+		t.Fatalf("Expected %d columns, got %d", len(SessionExportColumns), len(schema)-1)
+	}
+	for i, name := range SessionExportColumns {
+		if got := schema[i+1].(map[int16]any)[4]; got != name {
+			t.Errorf("Expected column %d to be %s, got %v", i, name, got)
+		}
+	}
+	if !bytes.Contains(data, []byte("bob, jr")) {
+		t.Error("Expected values in Parquet output")
+	}
+}
+
+func TestSessionsService_ExportFile_Incremental(t *testing.T) {
+	var cursors []string
+	server := newSessionExportServer(t, &cursors)
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sessions.csv")
+	store := NewFileCursorStore(filepath.Join(dir, "cursor"))
+	client := createTestSessionsClient(server)
+	opts := SessionExportOptions{Format: SessionExportCSV, CursorStore: store}
+
+	result, err := client.Sessions.ExportFile(path, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Sessions != 1 || result.Cursor != "c-1" {
+		t.Errorf("Unexpected first result %+v", result)
+	}
+
+	// The second run resumes from the saved cursor and appends without a header.
+	result, err = client.Sessions.ExportFile(path, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Sessions != 1 {
+		t.Errorf("Expected 1 new session, got %d", result.Sessions)
+	}
+	if cursors[len(cursors)-1] != "c-1" {
+		t.Errorf("Expected export to resume from c-1, got %v", cursors)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "session_id" || records[2][0] != "s-2" {
+		t.Errorf("Unexpected file contents %v", records)
+	}
+}
+
+func TestSessionsService_ExportFile_ResumesAfterFailure(t *testing.T) {
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response SessionsResponse
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			for i := range 100 {
+				response.Sessions = append(response.Sessions, Session{SessionID: fmt.Sprintf("s-%d", i)})
+			}
+			response.NextCursor = "c-1"
+		case "c-1":
+			if fail {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			response = SessionsResponse{Sessions: []Session{{SessionID: "s-100"}}, NextCursor: "c-2"}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sessions.csv")
+	store := NewFileCursorStore(filepath.Join(dir, "cursor"))
+	client := createTestSessionsClient(server)
+	opts := SessionExportOptions{Format: SessionExportCSV, CursorStore: store}
+
+	result, err := client.Sessions.ExportFile(path, opts)
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if result == nil || result.Sessions != 100 || result.Cursor != "c-1" {
+		t.Errorf("Expected the partial result, got %+v", result)
+	}
+	if cursor, _ := store.LoadCursor(); cursor != "c-1" {
+		t.Errorf("Expected the cursor of the written page to be saved, got %q", cursor)
+	}
+
+	fail = false
+	if _, err := client.Sessions.ExportFile(path, opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(records) != 102 || records[100][0] != "s-99" || records[101][0] != "s-100" {
+		t.Errorf("Expected header and 101 distinct rows, got %d records", len(records))
+	}
+}
+
+func TestSessionsService_ExportFile_Parquet(t *testing.T) {
+	var cursors []string
+	server := newSessionExportServer(t, &cursors)
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sessions.parquet")
+	client := createTestSessionsClient(server)
+
+	_, err := client.Sessions.ExportFile(path, SessionExportOptions{
+		Format:      SessionExportParquet,
+		CursorStore: NewFileCursorStore(filepath.Join(dir, "cursor")),
+	})
+	if !errors.Is(err, ErrIncrementalParquetExport) {
+		t.Errorf("Expected ErrIncrementalParquetExport, got %v", err)
+	}
+
+	if _, err := client.Sessions.ExportFile(path, SessionExportOptions{Format: SessionExportParquet}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if meta := readParquetFooter(t, data); meta[3] != int64(2) {
+		t.Errorf("Expected 2 rows, got %v", meta[3])
+	}
+
+	if err := os.WriteFile(path, []byte("previous export"), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	server.Close()
+	if _, err := client.Sessions.ExportFile(path, SessionExportOptions{Format: SessionExportParquet}); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "previous export" {
+		t.Errorf("Expected the previous export to be kept, got %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected the temporary file to be removed, got %v", entries)
+	}
+}
+
+func TestNewSessionRecordWriter_UnsupportedFormat(t *testing.T) {
+	if _, err := NewSessionRecordWriter(&bytes.Buffer{}, "xml", true); err == nil {
+		t.Error("Expected error for unsupported format, got nil")
+	}
+}
@@ -0,0 +1,205 @@
+package cloudconnexa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWatchPollInterval = 30 * time.Second
+	defaultWatchMinBackoff   = 1 * time.Second
+	defaultWatchMaxBackoff   = 5 * time.Minute
+)
+
+// SessionCursorStore persists the sessions cursor so that a watch can resume
+// from its last checkpoint after a restart.
+type SessionCursorStore interface {
+	// LoadCursor returns the saved cursor, or an empty string if there is none.
+	LoadCursor() (string, error)
+	// SaveCursor persists cursor.
+	SaveCursor(cursor string) error
+}
+
+// FileCursorStore is a SessionCursorStore backed by a single file.
+type FileCursorStore struct {
+	Path string
+}
+
+// NewFileCursorStore returns a SessionCursorStore that keeps the cursor in path.
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{Path: path}
+}
+
+// LoadCursor reads the cursor from the file. A missing file yields an empty cursor.
+func (f *FileCursorStore) LoadCursor() (string, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SaveCursor atomically replaces the file contents with cursor.
+func (f *FileCursorStore) SaveCursor(cursor string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(cursor); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// SessionWatchOptions configures SessionsService.Watch.
+type SessionWatchOptions struct {
+	// Status optionally restricts the watch to sessions with this status.
+	Status SessionStatus
+	// Size is the page size of every poll, between 1 and 100. Defaults to 100.
+	Size int
+	// Cursor is the starting cursor used when CursorStore has no checkpoint.
+	Cursor string
+	// CursorStore persists the cursor after every delivered page. Optional.
+	CursorStore SessionCursorStore
+	// PollInterval is the wait between polls once no new sessions are returned. Defaults to 30s.
+	PollInterval time.Duration
+	// MinBackoff and MaxBackoff bound the exponential backoff applied after a failed poll.
+	// They default to 1s and 5m. A MaxBackoff below MinBackoff is raised to MinBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnError is called with every error encountered while polling or saving the cursor.
+	// The watch keeps running after such errors.
+	OnError func(error)
+}
+
+// SessionEvent is a session delivered by SessionsService.Watch.
+type SessionEvent struct {
+	Session Session
+	// Cursor is the cursor that resumes the watch after the page this session belongs to.
+	Cursor string
+}
+
+// Watch polls the sessions endpoint with ReturnOnlyNew and emits every new
+// session on the returned channel until ctx is cancelled, at which point the
+// channel is closed.
+//
+// The sessions API cannot long-poll: it has no parameter to hold a request
+// open until new sessions arrive and answers every request immediately, with
+// an empty page if nothing changed. Watch therefore fetches pages back to
+// back while the API returns full pages, and after a partial or empty page
+// waits PollInterval before polling again.
+//
+// The cursor is checkpointed after each page has been delivered, so a
+// restarted watch resumes where the previous one stopped; sessions of a page
+// interrupted by a restart may be delivered again. Failed polls are retried
+// with exponential backoff.
+func (s *SessionsService) Watch(ctx context.Context, opts SessionWatchOptions) (<-chan SessionEvent, error) {
+	opts = opts.withDefaults()
+	listOptions := SessionsListOptions{
+		Status:        opts.Status,
+		Size:          opts.Size,
+		ReturnOnlyNew: true,
+		Cursor:        opts.Cursor,
+	}
+	if opts.Size < 1 || opts.Size > 100 {
+		return nil, fmt.Errorf("size must be between 1 and 100, got %d", opts.Size)
+	}
+	if opts.CursorStore != nil {
+		saved, err := opts.CursorStore.LoadCursor()
+		if err != nil {
+			return nil, err
+		}
+		if saved != "" {
+			listOptions.Cursor = saved
+		}
+	}
+
+	events := make(chan SessionEvent)
+	go func() {
+		defer close(events)
+		s.watch(ctx, listOptions, opts, events)
+	}()
+	return events, nil
+}
+
+// withDefaults fills in unset options and raises MaxBackoff to at least MinBackoff.
+func (opts SessionWatchOptions) withDefaults() SessionWatchOptions {
+	if opts.Size == 0 {
+		opts.Size = 100
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultWatchPollInterval
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultWatchMinBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = max(defaultWatchMaxBackoff, opts.MinBackoff)
+	}
+	opts.MaxBackoff = max(opts.MaxBackoff, opts.MinBackoff)
+	return opts
+}
+
+func (s *SessionsService) watch(ctx context.Context, listOptions SessionsListOptions, opts SessionWatchOptions, events chan<- SessionEvent) {
+	reportErr := func(err error) {
+		if opts.OnError != nil {
+			opts.OnError(err)
+		}
+	}
+	backoff := opts.MinBackoff
+
+	for ctx.Err() == nil {
+		response, err := s.List(listOptions)
+		if err != nil {
+			reportErr(err)
+			if !sleepContext(ctx, backoff) {
+				return
+			}
+			backoff = min(backoff*2, opts.MaxBackoff)
+			continue
+		}
+		backoff = opts.MinBackoff
+
+		cursor := response.NextCursor
+		if cursor == "" {
+			cursor = listOptions.Cursor
+		}
+		for _, session := range response.Sessions {
+			select {
+			case <-ctx.Done():
+				return
+			case events <- SessionEvent{Session: session, Cursor: cursor}:
+			}
+		}
+
+		if cursor != listOptions.Cursor {
+			listOptions.Cursor = cursor
+			if opts.CursorStore != nil {
+				if err := opts.CursorStore.SaveCursor(cursor); err != nil {
+					reportErr(err)
+				}
+			}
+		}
+
+		// Keep draining while the API returns full pages; otherwise wait for new sessions.
+		if len(response.Sessions) < listOptions.Size {
+			if !sleepContext(ctx, opts.PollInterval) {
+				return
+			}
+		}
+	}
+}
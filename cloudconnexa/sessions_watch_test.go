@@ -0,0 +1,153 @@
+package cloudconnexa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSessionsService_Watch(t *testing.T) {
+	var mu sync.Mutex
+	var cursors []string
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		query := r.URL.Query()
+		if query.Get("returnOnlyNew") != "true" {
+			t.Errorf("Expected returnOnlyNew=true, got %s", query.Get("returnOnlyNew"))
+		}
+		cursors = append(cursors, query.Get("cursor"))
+
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			_ = json.NewEncoder(w).Encode(SessionsResponse{
+				Sessions:   []Session{{SessionID: "s-1"}, {SessionID: "s-2"}},
+				NextCursor: "c-1",
+			})
+		default:
+			_ = json.NewEncoder(w).Encode(SessionsResponse{
+				Sessions:   []Session{{SessionID: "s-3"}},
+				NextCursor: "c-2",
+			})
+		}
+	}))
+	defer server.Close()
+
+	client := createTestSessionsClient(server)
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var errs []error
+	events, err := client.Sessions.Watch(ctx, SessionWatchOptions{
+		CursorStore:  store,
+		Cursor:       "c-0",
+		PollInterval: time.Millisecond,
+		MinBackoff:   time.Millisecond,
+		OnError:      func(err error) { errs = append(errs, err) },
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var got []string
+	for event := range events {
+		got = append(got, event.Session.SessionID)
+		if len(got) == 3 {
+			if event.Cursor != "c-2" {
+				t.Errorf("Expected cursor c-2, got %s", event.Cursor)
+			}
+			cancel()
+		}
+	}
+
+	if len(got) != 3 || got[0] != "s-1" || got[2] != "s-3" {
+		t.Errorf("Unexpected sessions %v", got)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 reported error, got %v", errs)
+	}
+	mu.Lock()
+	if cursors[0] != "c-0" || cursors[1] != "c-0" || cursors[2] != "c-1" {
+		t.Errorf("Unexpected cursor sequence %v", cursors)
+	}
+	mu.Unlock()
+
+	saved, err := store.LoadCursor()
+	if err != nil {
+		t.Fatalf("LoadCursor failed: %v", err)
+	}
+	if saved != "c-2" {
+		t.Errorf("Expected checkpoint c-2, got %q", saved)
+	}
+}
+
+func TestSessionsService_Watch_ResumesFromCheckpoint(t *testing.T) {
+	requested := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- r.URL.Query().Get("cursor"):
+		default:
+		}
+		_ = json.NewEncoder(w).Encode(SessionsResponse{})
+	}))
+	defer server.Close()
+
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor"))
+	if err := store.SaveCursor("checkpoint"); err != nil {
+		t.Fatalf("SaveCursor failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := createTestSessionsClient(server)
+	events, err := client.Sessions.Watch(ctx, SessionWatchOptions{CursorStore: store, Cursor: "initial"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cursor := <-requested; cursor != "checkpoint" {
+		t.Errorf("Expected watch to resume from checkpoint, got %q", cursor)
+	}
+	cancel()
+	for range events {
+		t.Error("Expected no events")
+	}
+}
+
+func TestSessionsService_Watch_InvalidSize(t *testing.T) {
+	client := createTestSessionsClient(httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("Expected no HTTP call for an invalid size")
+	})))
+
+	if _, err := client.Sessions.Watch(context.Background(), SessionWatchOptions{Size: 101}); err == nil {
+		t.Error("Expected error for size 101, got nil")
+	}
+}
+
+func TestSessionWatchOptions_Backoff(t *testing.T) {
+	tests := []struct {
+		min, max         time.Duration
+		wantMin, wantMax time.Duration
+	}{
+		{0, 0, defaultWatchMinBackoff, defaultWatchMaxBackoff},
+		{2 * time.Second, 10 * time.Second, 2 * time.Second, 10 * time.Second},
+		{10 * time.Second, 2 * time.Second, 10 * time.Second, 10 * time.Second},
+		{10 * time.Minute, 0, 10 * time.Minute, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		opts := SessionWatchOptions{MinBackoff: tt.min, MaxBackoff: tt.max}.withDefaults()
+		if opts.MinBackoff != tt.wantMin || opts.MaxBackoff != tt.wantMax {
+			t.Errorf("min %v, max %v: expected %v/%v, got %v/%v", tt.min, tt.max, tt.wantMin, tt.wantMax, opts.MinBackoff, opts.MaxBackoff)
+		}
+	}
+}
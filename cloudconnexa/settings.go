@@ -162,6 +162,9 @@ func (c *SettingsService) GetDefaultConnectAuth() (string, error) {
 
 // SetDefaultConnectAuth sets the default connection authentication method
 func (c *SettingsService) SetDefaultConnectAuth(value string) (string, error) {
+	if err := c.client.validateEnum("defaultConnectAuth", value, connectAuthValues); err != nil {
+		return "", err
+	}
 	return c.setString("%s/settings/user/connect-auth", value)
 }
 
@@ -192,6 +195,9 @@ func (c *SettingsService) GetDeviceEnforcement() (string, error) {
 
 // SetDeviceEnforcement sets the device enforcement policy
 func (c *SettingsService) SetDeviceEnforcement(value string) (string, error) {
+	if err := c.client.validateEnum("deviceEnforcement", value, deviceEnforcementValues); err != nil {
+		return "", err
+	}
 	return c.setString("%s/settings/user/device-enforcement", value)
 }
 
@@ -202,6 +208,9 @@ func (c *SettingsService) GetProfileDistribution() (string, error) {
 
 // SetProfileDistribution sets the profile distribution method
 func (c *SettingsService) SetProfileDistribution(value string) (string, error) {
+	if err := c.client.validateEnum("profileDistribution", value, profileDistributionValues); err != nil {
+		return "", err
+	}
 	return c.setString("%s/settings/user/profile-distribution", value)
 }
 
@@ -341,6 +350,9 @@ func (c *SettingsService) GetTopology() (string, error) {
 
 // SetTopology sets the network topology
 func (c *SettingsService) SetTopology(value string) (string, error) {
+	if err := c.client.validateEnum("topology", value, topologyValues); err != nil {
+		return "", err
+	}
 	return c.setString("%s/settings/wpc/topology", value)
 }
 
@@ -361,6 +373,9 @@ func (c *SettingsService) GetIPAllocationMode() (string, error) {
 
 // SetIPAllocationMode sets the ip allocation mode
 func (c *SettingsService) SetIPAllocationMode(value string) (string, error) {
+	if err := c.client.validateEnum("ipAllocationMode", value, ipAllocationModeValues); err != nil {
+		return "", err
+	}
 	return c.setString("%s/settings/wpc/ip-allocation-mode", value)
 }
 
@@ -0,0 +1,250 @@
+package cloudconnexa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// ErrInvalidBaseline is returned when a settings baseline document cannot be used.
+var ErrInvalidBaseline = errors.New("invalid settings baseline")
+
+// Severity ranks findings reported by the compliance helpers.
+type Severity string
+
+// Severity levels, from least to most severe.
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+var severityRanks = map[Severity]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// AtLeast reports whether s is as severe as other or more.
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRanks[s] >= severityRanks[other]
+}
+
+// SettingsBaseline is a policy document listing required tenant setting values.
+//
+// Example:
+//
+//	{"rules": [
+//	  {"setting": "twoFactorAuthEnabled", "equals": true, "severity": "critical"},
+//	  {"setting": "deviceEnforcement", "oneOf": ["LEARN_AND_ENFORCE", "ENFORCE"], "severity": "high"},
+//	  {"setting": "connectionTimeout", "max": 60, "severity": "low"}
+//	]}
+type SettingsBaseline struct {
+	Rules []SettingsBaselineRule `json:"rules"`
+}
+
+// SettingsBaselineRule constrains one setting. Setting is the JSON name of a
+// TenantSettings field. Exactly one of Equals, OneOf or a Min/Max range must be given.
+type SettingsBaselineRule struct {
+	Setting     string   `json:"setting"`
+	Equals      any      `json:"equals,omitempty"`
+	OneOf       []any    `json:"oneOf,omitempty"`
+	Min         *float64 `json:"min,omitempty"`
+	Max         *float64 `json:"max,omitempty"`
+	Severity    Severity `json:"severity"`
+	Description string   `json:"description,omitempty"`
+}
+
+// SettingsViolation is a rule the live settings do not satisfy.
+type SettingsViolation struct {
+	Setting     string   `json:"setting"`
+	Severity    Severity `json:"severity"`
+	Expected    string   `json:"expected"`
+	Actual      any      `json:"actual"`
+	Description string   `json:"description,omitempty"`
+}
+
+// SettingsBaselineReport is the result of a baseline check.
+type SettingsBaselineReport struct {
+	CheckedAt  time.Time           `json:"checkedAt"`
+	Rules      int                 `json:"rules"`
+	Violations []SettingsViolation `json:"violations"`
+}
+
+// HasViolations reports whether the report contains a violation of at least severity min.
+// It is intended for deciding the exit status of a scheduled job or CLI.
+func (r *SettingsBaselineReport) HasViolations(minSeverity Severity) bool {
+	for _, v := range r.Violations {
+		if v.Severity.AtLeast(minSeverity) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultSettingsBaseline returns a baseline requiring two-factor authentication,
+// DNS logging and access visibility to be enabled and device enforcement to be on.
+func DefaultSettingsBaseline() *SettingsBaseline {
+	return &SettingsBaseline{Rules: []SettingsBaselineRule{
+		{Setting: "twoFactorAuthEnabled", Equals: true, Severity: SeverityCritical},
+		{Setting: "dnsLogEnabled", Equals: true, Severity: SeverityHigh},
+		{Setting: "accessVisibilityEnabled", Equals: true, Severity: SeverityHigh},
+		{Setting: "deviceEnforcement", OneOf: []any{DeviceEnforcementLearnAndEnforce, DeviceEnforcementEnforce}, Severity: SeverityHigh},
+	}}
+}
+
+// LoadSettingsBaseline decodes and validates a JSON baseline document.
+func LoadSettingsBaseline(r io.Reader) (*SettingsBaseline, error) {
+	var baseline SettingsBaseline
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&baseline); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBaseline, err)
+	}
+	if err := baseline.Validate(); err != nil {
+		return nil, err
+	}
+	return &baseline, nil
+}
+
+// Validate checks that every rule names a known setting, has a known severity
+// and exactly one kind of condition.
+func (b *SettingsBaseline) Validate() error {
+	for i, rule := range b.Rules {
+		if _, ok := findTenantSetting(rule.Setting); !ok {
+			return fmt.Errorf("%w: rule %d: unknown setting %q", ErrInvalidBaseline, i, rule.Setting)
+		}
+		if _, ok := severityRanks[rule.Severity]; !ok {
+			return fmt.Errorf("%w: rule %d: unknown severity %q", ErrInvalidBaseline, i, rule.Severity)
+		}
+		conditions := 0
+		if rule.Equals != nil {
+			conditions++
+		}
+		if rule.OneOf != nil {
+			conditions++
+		}
+		if rule.Min != nil || rule.Max != nil {
+			conditions++
+		}
+		if conditions != 1 {
+			return fmt.Errorf("%w: rule %d: exactly one of equals, oneOf or min/max is required", ErrInvalidBaseline, i)
+		}
+	}
+	return nil
+}
+
+// Check evaluates the baseline against settings. Settings the snapshot does not
+// contain are reported as violations with a nil Actual value.
+func (b *SettingsBaseline) Check(settings *TenantSettings) []SettingsViolation {
+	violations := []SettingsViolation{}
+	for _, rule := range b.Rules {
+		s, ok := findTenantSetting(rule.Setting)
+		if !ok {
+			continue
+		}
+		actual := normalizeSettingValue(s.value(settings))
+		if expected, ok := rule.evaluate(actual); !ok {
+			violations = append(violations, SettingsViolation{
+				Setting:     rule.Setting,
+				Severity:    rule.Severity,
+				Expected:    expected,
+				Actual:      actual,
+				Description: rule.Description,
+			})
+		}
+	}
+	return violations
+}
+
+// evaluate reports whether actual satisfies the rule, along with a description of the expectation.
+func (r SettingsBaselineRule) evaluate(actual any) (string, bool) {
+	switch {
+	case r.Equals != nil:
+		expected := normalizeSettingValue(r.Equals)
+		return fmt.Sprintf("equals %v", expected), actual != nil && reflect.DeepEqual(actual, expected)
+	case r.OneOf != nil:
+		description := fmt.Sprintf("one of %v", r.OneOf)
+		for _, candidate := range r.OneOf {
+			if actual != nil && reflect.DeepEqual(actual, normalizeSettingValue(candidate)) {
+				return description, true
+			}
+		}
+		return description, false
+	default:
+		description := "between"
+		if r.Min != nil {
+			description += fmt.Sprintf(" %v", *r.Min)
+		} else {
+			description += " -inf"
+		}
+		if r.Max != nil {
+			description += fmt.Sprintf(" and %v", *r.Max)
+		} else {
+			description += " and +inf"
+		}
+		n, ok := actual.(float64)
+		if !ok {
+			return description, false
+		}
+		return description, (r.Min == nil || n >= *r.Min) && (r.Max == nil || n <= *r.Max)
+	}
+}
+
+// normalizeSettingValue converts a value to its generic JSON form so that typed
+// setting values compare equal to values decoded from a baseline document.
+func normalizeSettingValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return v
+	}
+	return normalized
+}
+
+func findTenantSetting(name string) (tenantSetting, bool) {
+	for _, s := range tenantSettings {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return tenantSetting{}, false
+}
+
+// CheckBaseline reads the live settings referenced by baseline and returns the violations found.
+func (c *SettingsService) CheckBaseline(baseline *SettingsBaseline) (*SettingsBaselineReport, error) {
+	if err := baseline.Validate(); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var needed []tenantSetting
+	for _, rule := range baseline.Rules {
+		if !seen[rule.Setting] {
+			seen[rule.Setting] = true
+			s, _ := findTenantSetting(rule.Setting)
+			needed = append(needed, s)
+		}
+	}
+
+	settings := &TenantSettings{}
+	if err := c.getSettings(settings, needed); err != nil {
+		return nil, err
+	}
+	return &SettingsBaselineReport{
+		CheckedAt:  time.Now().UTC(),
+		Rules:      len(baseline.Rules),
+		Violations: baseline.Check(settings),
+	}, nil
+}
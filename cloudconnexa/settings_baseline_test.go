@@ -0,0 +1,84 @@
+package cloudconnexa
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoadSettingsBaseline_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown setting":   `{"rules":[{"setting":"nope","equals":true,"severity":"high"}]}`,
+		"unknown severity":  `{"rules":[{"setting":"snatEnabled","equals":true,"severity":"urgent"}]}`,
+		"no condition":      `{"rules":[{"setting":"snatEnabled","severity":"high"}]}`,
+		"two conditions":    `{"rules":[{"setting":"connectionTimeout","equals":5,"max":10,"severity":"high"}]}`,
+		"unknown attribute": `{"rules":[{"setting":"snatEnabled","equal":true,"severity":"high"}]}`,
+	}
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadSettingsBaseline(strings.NewReader(doc)); !errors.Is(err, ErrInvalidBaseline) {
+				t.Errorf("Expected ErrInvalidBaseline, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSettingsBaseline_Check(t *testing.T) {
+	baseline, err := LoadSettingsBaseline(strings.NewReader(`{"rules":[
+		{"setting":"twoFactorAuthEnabled","equals":true,"severity":"critical"},
+		{"setting":"deviceEnforcement","oneOf":["LEARN_AND_ENFORCE","ENFORCE"],"severity":"high"},
+		{"setting":"connectionTimeout","min":5,"max":60,"severity":"low"},
+		{"setting":"dnsServers","equals":{"primaryIpV4":"1.1.1.1"},"severity":"medium"},
+		{"setting":"dnsLogEnabled","equals":true,"severity":"high"}
+	]}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	disabled, enforcement, timeout := false, "ENFORCE", 90
+	violations := baseline.Check(&TenantSettings{
+		TwoFactorAuthEnabled: &disabled,
+		DeviceEnforcement:    &enforcement,
+		ConnectionTimeout:    &timeout,
+		DNSServers:           &DNSServers{PrimaryIPV4: "1.1.1.1"},
+	})
+
+	got := map[string]SettingsViolation{}
+	for _, v := range violations {
+		got[v.Setting] = v
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 violations, got %+v", violations)
+	}
+	if v := got["twoFactorAuthEnabled"]; v.Severity != SeverityCritical || v.Actual != false {
+		t.Errorf("Unexpected violation %+v", v)
+	}
+	if v := got["connectionTimeout"]; v.Actual != float64(90) || v.Expected != "between 5 and 60" {
+		t.Errorf("Unexpected violation %+v", v)
+	}
+	if v, ok := got["dnsLogEnabled"]; !ok || v.Actual != nil {
+		t.Errorf("Expected missing setting to be a violation, got %+v", v)
+	}
+}
+
+func TestSettingsService_CheckBaseline(t *testing.T) {
+	api := newSettingsAPI()
+	api.values["/settings/user/device-enforcement"] = "OFF"
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	report, err := createTestSettingsClient(server).Settings.CheckBaseline(DefaultSettingsBaseline())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Rules != 4 || len(report.Violations) != 2 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	if report.Violations[0].Setting != "accessVisibilityEnabled" || report.Violations[1].Setting != "deviceEnforcement" {
+		t.Errorf("Unexpected violations %+v", report.Violations)
+	}
+	if !report.HasViolations(SeverityHigh) || report.HasViolations(SeverityCritical) {
+		t.Error("Expected high but no critical violations")
+	}
+}
@@ -0,0 +1,257 @@
+package cloudconnexa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TenantSettings is a snapshot of every tenant setting exposed by SettingsService.
+// A nil field is unknown when returned by GetAll and left unchanged when passed to Apply.
+type TenantSettings struct {
+	TrustedDevicesAllowed              *bool                `json:"trustedDevicesAllowed,omitempty"`
+	TwoFactorAuthEnabled               *bool                `json:"twoFactorAuthEnabled,omitempty"`
+	DNSServers                         *DNSServers          `json:"dnsServers,omitempty"`
+	DefaultDNSSuffix                   *string              `json:"defaultDnsSuffix,omitempty"`
+	DNSProxyEnabled                    *bool                `json:"dnsProxyEnabled,omitempty"`
+	DNSZones                           *[]DNSZone           `json:"dnsZones,omitempty"`
+	DefaultConnectAuth                 *string              `json:"defaultConnectAuth,omitempty"`
+	DefaultDeviceAllowancePerUser      *int                 `json:"defaultDeviceAllowancePerUser,omitempty"`
+	ForceUpdateDeviceAllowanceEnabled  *bool                `json:"forceUpdateDeviceAllowanceEnabled,omitempty"`
+	DeviceEnforcement                  *string              `json:"deviceEnforcement,omitempty"`
+	ProfileDistribution                *string              `json:"profileDistribution,omitempty"`
+	ConnectionTimeout                  *int                 `json:"connectionTimeout,omitempty"`
+	ClientOptions                      *[]string            `json:"clientOptions,omitempty"`
+	DefaultRegion                      *string              `json:"defaultRegion,omitempty"`
+	DomainRoutingSubnet                *DomainRoutingSubnet `json:"domainRoutingSubnet,omitempty"`
+	SnatEnabled                        *bool                `json:"snatEnabled,omitempty"`
+	Subnet                             *Subnet              `json:"subnet,omitempty"`
+	Topology                           *string              `json:"topology,omitempty"`
+	RoutesAdvancedConfigurationEnabled *bool                `json:"routesAdvancedConfigurationEnabled,omitempty"`
+	IPAllocationMode                   *string              `json:"ipAllocationMode,omitempty"`
+	DNSLogEnabled                      *bool                `json:"dnsLogEnabled,omitempty"`
+	AccessVisibilityEnabled            *bool                `json:"accessVisibilityEnabled,omitempty"`
+}
+
+// SettingChange describes a setting updated by Apply. Setting is the JSON name of the TenantSettings field.
+type SettingChange struct {
+	Setting string `json:"setting"`
+	Old     any    `json:"old"`
+	New     any    `json:"new"`
+}
+
+// tenantSetting binds a TenantSettings field to its getter and setter.
+type tenantSetting struct {
+	name  string
+	get   func(c *SettingsService, t *TenantSettings) error
+	set   func(c *SettingsService, t *TenantSettings) error
+	isSet func(t *TenantSettings) bool
+	equal func(a, b *TenantSettings) bool
+	value func(t *TenantSettings) any
+}
+
+func newTenantSetting[T any](
+	name string,
+	field func(t *TenantSettings) **T,
+	get func(c *SettingsService) (T, error),
+	set func(c *SettingsService, value T) error,
+) tenantSetting {
+	return tenantSetting{
+		name: name,
+		get: func(c *SettingsService, t *TenantSettings) error {
+			v, err := get(c)
+			if err != nil {
+				return err
+			}
+			*field(t) = &v
+			return nil
+		},
+		set: func(c *SettingsService, t *TenantSettings) error {
+			return set(c, **field(t))
+		},
+		isSet: func(t *TenantSettings) bool {
+			return *field(t) != nil
+		},
+		equal: func(a, b *TenantSettings) bool {
+			pa, pb := *field(a), *field(b)
+			if pa == nil || pb == nil {
+				return pa == pb
+			}
+			return settingValuesEqual(*pa, *pb)
+		},
+		value: func(t *TenantSettings) any {
+			if p := *field(t); p != nil {
+				return *p
+			}
+			return nil
+		},
+	}
+}
+
+// settingValuesEqual compares setting values, treating nil and empty slices as equal
+// since the API does not distinguish them.
+func settingValuesEqual(a, b any) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == reflect.Slice && vb.Kind() == reflect.Slice && va.Len() == 0 && vb.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// discardResult adapts a setter returning the stored value to one returning only an error.
+func discardResult[T, R any](set func(c *SettingsService, value T) (R, error)) func(c *SettingsService, value T) error {
+	return func(c *SettingsService, value T) error {
+		_, err := set(c, value)
+		return err
+	}
+}
+
+// tenantSettings lists every setting in the order GetAll and Apply process them.
+var tenantSettings = []tenantSetting{
+	newTenantSetting("trustedDevicesAllowed",
+		func(t *TenantSettings) **bool { return &t.TrustedDevicesAllowed },
+		(*SettingsService).GetTrustedDevicesAllowed, discardResult((*SettingsService).SetTrustedDevicesAllowed)),
+	newTenantSetting("twoFactorAuthEnabled",
+		func(t *TenantSettings) **bool { return &t.TwoFactorAuthEnabled },
+		(*SettingsService).GetTwoFactorAuthEnabled, discardResult((*SettingsService).SetTwoFactorAuthEnabled)),
+	newTenantSetting("dnsServers",
+		func(t *TenantSettings) **DNSServers { return &t.DNSServers },
+		func(c *SettingsService) (DNSServers, error) {
+			v, err := c.GetDNSServers()
+			if err != nil || v == nil {
+				return DNSServers{}, err
+			}
+			return *v, nil
+		},
+		func(c *SettingsService, value DNSServers) error {
+			_, err := c.SetDNSServers(&value)
+			return err
+		}),
+	newTenantSetting("defaultDnsSuffix",
+		func(t *TenantSettings) **string { return &t.DefaultDNSSuffix },
+		(*SettingsService).GetDefaultDNSSuffix, discardResult((*SettingsService).SetDefaultDNSSuffix)),
+	newTenantSetting("dnsProxyEnabled",
+		func(t *TenantSettings) **bool { return &t.DNSProxyEnabled },
+		(*SettingsService).GetDNSProxyEnabled, discardResult((*SettingsService).SetDNSProxyEnabled)),
+	newTenantSetting("dnsZones",
+		func(t *TenantSettings) **[]DNSZone { return &t.DNSZones },
+		(*SettingsService).GetDNSZones, discardResult((*SettingsService).SetDNSZones)),
+	newTenantSetting("defaultConnectAuth",
+		func(t *TenantSettings) **string { return &t.DefaultConnectAuth },
+		(*SettingsService).GetDefaultConnectAuth, discardResult((*SettingsService).SetDefaultConnectAuth)),
+	newTenantSetting("defaultDeviceAllowancePerUser",
+		func(t *TenantSettings) **int { return &t.DefaultDeviceAllowancePerUser },
+		(*SettingsService).GetDefaultDeviceAllowancePerUser, discardResult((*SettingsService).SetDefaultDeviceAllowancePerUser)),
+	newTenantSetting("forceUpdateDeviceAllowanceEnabled",
+		func(t *TenantSettings) **bool { return &t.ForceUpdateDeviceAllowanceEnabled },
+		(*SettingsService).GetForceUpdateDeviceAllowanceEnabled, discardResult((*SettingsService).SetForceUpdateDeviceAllowanceEnabled)),
+	newTenantSetting("deviceEnforcement",
+		func(t *TenantSettings) **string { return &t.DeviceEnforcement },
+		(*SettingsService).GetDeviceEnforcement, discardResult((*SettingsService).SetDeviceEnforcement)),
+	newTenantSetting("profileDistribution",
+		func(t *TenantSettings) **string { return &t.ProfileDistribution },
+		(*SettingsService).GetProfileDistribution, discardResult((*SettingsService).SetProfileDistribution)),
+	newTenantSetting("connectionTimeout",
+		func(t *TenantSettings) **int { return &t.ConnectionTimeout },
+		(*SettingsService).GetConnectionTimeout, discardResult((*SettingsService).SetConnectionTimeout)),
+	newTenantSetting("clientOptions",
+		func(t *TenantSettings) **[]string { return &t.ClientOptions },
+		(*SettingsService).GetClientOptions, discardResult((*SettingsService).SetClientOptions)),
+	newTenantSetting("defaultRegion",
+		func(t *TenantSettings) **string { return &t.DefaultRegion },
+		(*SettingsService).GetDefaultRegion, discardResult((*SettingsService).SetDefaultRegion)),
+	newTenantSetting("domainRoutingSubnet",
+		func(t *TenantSettings) **DomainRoutingSubnet { return &t.DomainRoutingSubnet },
+		func(c *SettingsService) (DomainRoutingSubnet, error) {
+			v, err := c.GetDomainRoutingSubnet()
+			if err != nil {
+				return DomainRoutingSubnet{}, err
+			}
+			return *v, nil
+		},
+		discardResult((*SettingsService).SetDomainRoutingSubnet)),
+	newTenantSetting("snatEnabled",
+		func(t *TenantSettings) **bool { return &t.SnatEnabled },
+		(*SettingsService).GetSnatEnabled, discardResult((*SettingsService).SetSnatEnabled)),
+	newTenantSetting("subnet",
+		func(t *TenantSettings) **Subnet { return &t.Subnet },
+		func(c *SettingsService) (Subnet, error) {
+			v, err := c.GetSubnet()
+			if err != nil {
+				return Subnet{}, err
+			}
+			return *v, nil
+		},
+		discardResult((*SettingsService).SetSubnet)),
+	newTenantSetting("topology",
+		func(t *TenantSettings) **string { return &t.Topology },
+		(*SettingsService).GetTopology, discardResult((*SettingsService).SetTopology)),
+	newTenantSetting("routesAdvancedConfigurationEnabled",
+		func(t *TenantSettings) **bool { return &t.RoutesAdvancedConfigurationEnabled },
+		(*SettingsService).GetRoutesAdvancedConfigurationEnabled, discardResult((*SettingsService).SetRoutesAdvancedConfigurationEnabled)),
+	newTenantSetting("ipAllocationMode",
+		func(t *TenantSettings) **string { return &t.IPAllocationMode },
+		(*SettingsService).GetIPAllocationMode, discardResult((*SettingsService).SetIPAllocationMode)),
+	newTenantSetting("dnsLogEnabled",
+		func(t *TenantSettings) **bool { return &t.DNSLogEnabled },
+		(*SettingsService).GetDNSLogEnabled, (*SettingsService).SetDNSLogEnabled),
+	newTenantSetting("accessVisibilityEnabled",
+		func(t *TenantSettings) **bool { return &t.AccessVisibilityEnabled },
+		(*SettingsService).GetAccessVisibilityEnabled, (*SettingsService).SetAccessVisibilityEnabled),
+}
+
+// GetAll retrieves every tenant setting, issuing the individual requests with bounded concurrency.
+// Failures are reported together, each prefixed with the name of the setting.
+func (c *SettingsService) GetAll() (*TenantSettings, error) {
+	settings := &TenantSettings{}
+	if err := c.getSettings(settings, tenantSettings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// getSettings fills in the given settings of t concurrently.
+func (c *SettingsService) getSettings(t *TenantSettings, settings []tenantSetting) error {
+	var mu sync.Mutex
+	var errs []error
+	_ = forEachConcurrent(context.Background(), len(settings), defaultConcurrency, func(i int) {
+		// Each getter writes a distinct field of t, so only the error list needs locking.
+		if err := settings[i].get(c, t); err != nil {
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("%s: %w", settings[i].name, err))
+			mu.Unlock()
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// Apply updates the settings whose desired value is non-nil and differs from the
+// current one, and returns the changes made in order. Settings are updated one at
+// a time; if a setter fails, the changes applied so far are returned with the error.
+func (c *SettingsService) Apply(desired *TenantSettings) ([]SettingChange, error) {
+	var selected []tenantSetting
+	for _, s := range tenantSettings {
+		if s.isSet(desired) {
+			selected = append(selected, s)
+		}
+	}
+
+	current := &TenantSettings{}
+	if err := c.getSettings(current, selected); err != nil {
+		return nil, err
+	}
+
+	var changes []SettingChange
+	for _, s := range selected {
+		if s.equal(current, desired) {
+			continue
+		}
+		if err := s.set(c, desired); err != nil {
+			return changes, fmt.Errorf("%s: %w", s.name, err)
+		}
+		changes = append(changes, SettingChange{Setting: s.name, Old: s.value(current), New: s.value(desired)})
+	}
+	return changes, nil
+}
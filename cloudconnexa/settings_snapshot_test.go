@@ -0,0 +1,149 @@
+package cloudconnexa
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+// settingsAPI is an in-memory settings backend keyed by the path below /api/v1.
+type settingsAPI struct {
+	mu     sync.Mutex
+	values map[string]string
+	puts   []string
+}
+
+func newSettingsAPI() *settingsAPI {
+	return &settingsAPI{values: map[string]string{
+		"/settings/auth/trusted-devices-allowed":              "true",
+		"/settings/auth/two-factor-auth":                      "true",
+		"/settings/dns/custom-servers":                        `{"primaryIpV4":"1.1.1.1"}`,
+		"/settings/dns/default-suffix":                        "corp.example",
+		"/settings/dns/proxy-enabled":                         "false",
+		"/settings/dns/zones":                                 `{"zones":[]}`,
+		"/settings/user/connect-auth":                         "ON_PRIOR_AUTH",
+		"/settings/user/device-allowance":                     "5",
+		"/settings/user/device-allowance-force-update":        "false",
+		"/settings/user/device-enforcement":                   "LEARN_AND_ENFORCE",
+		"/settings/user/profile-distribution":                 "AUTOMATIC",
+		"/settings/users/connection-timeout":                  "20",
+		"/settings/wpc/client-options":                        `[]`,
+		"/settings/wpc/default-region":                        "us-east-1",
+		"/settings/wpc/domain-routing-subnet":                 `{"ipV4Address":"100.80.0.0/12","ipV6Address":""}`,
+		"/settings/wpc/snat":                                  "true",
+		"/settings/wpc/subnet":                                `{"ipV4Address":["100.96.0.0/11"],"ipV6Address":[]}`,
+		"/settings/wpc/topology":                              "FULL_MESH",
+		"/settings/wpc/routes-advanced-configuration-enabled": "false",
+		"/settings/wpc/ip-allocation-mode":                    "DYNAMIC",
+		"/dns-log/user-dns-resolutions/enabled":               "true",
+		"/access-visibility/enabled":                          "false",
+	}}
+}
+
+func (a *settingsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+	if r.Method == http.MethodGet {
+		value, ok := a.values[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, value)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	a.puts = append(a.puts, path)
+	switch {
+	case strings.HasSuffix(path, "/enable"):
+		a.values[strings.TrimSuffix(path, "/enable")+"/enabled"] = "true"
+	case strings.HasSuffix(path, "/disable"):
+		a.values[strings.TrimSuffix(path, "/disable")+"/enabled"] = "false"
+	default:
+		a.values[path] = string(body)
+	}
+	_, _ = w.Write(body)
+}
+
+func createTestSettingsClient(server *httptest.Server) *Client {
+	client := &Client{
+		client:            server.Client(),
+		BaseURL:           server.URL,
+		Token:             "test-token",
+		ReadRateLimiter:   rate.NewLimiter(rate.Every(1), 5),
+		UpdateRateLimiter: rate.NewLimiter(rate.Every(1), 5),
+	}
+	client.Settings = (*SettingsService)(&service{client: client})
+	return client
+}
+
+func TestSettingsService_GetAll(t *testing.T) {
+	server := httptest.NewServer(newSettingsAPI())
+	defer server.Close()
+
+	settings, err := createTestSettingsClient(server).Settings.GetAll()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, s := range tenantSettings {
+		if !s.isSet(settings) {
+			t.Errorf("Expected %s to be set", s.name)
+		}
+	}
+	if !*settings.TwoFactorAuthEnabled || *settings.DefaultDeviceAllowancePerUser != 5 || *settings.Topology != "FULL_MESH" {
+		t.Errorf("Unexpected settings %+v", settings)
+	}
+	if settings.DNSServers.PrimaryIPV4 != "1.1.1.1" || settings.Subnet.IPV4Address[0] != "100.96.0.0/11" {
+		t.Errorf("Unexpected structured settings %+v %+v", settings.DNSServers, settings.Subnet)
+	}
+}
+
+func TestSettingsService_GetAll_ReportsFailedSettings(t *testing.T) {
+	api := newSettingsAPI()
+	delete(api.values, "/access-visibility/enabled")
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	_, err := createTestSettingsClient(server).Settings.GetAll()
+	if err == nil || !strings.Contains(err.Error(), "accessVisibilityEnabled") {
+		t.Errorf("Expected error naming accessVisibilityEnabled, got %v", err)
+	}
+}
+
+func TestSettingsService_Apply(t *testing.T) {
+	api := newSettingsAPI()
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	enabled, disabled, timeout, empty := true, false, 30, []string{}
+	changes, err := createTestSettingsClient(server).Settings.Apply(&TenantSettings{
+		TwoFactorAuthEnabled:    &enabled,  // unchanged
+		ConnectionTimeout:       &timeout,  // changed
+		AccessVisibilityEnabled: &enabled,  // changed
+		SnatEnabled:             &disabled, // changed
+		ClientOptions:           &empty,    // unchanged
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %+v", changes)
+	}
+	if changes[0].Setting != "connectionTimeout" || changes[0].Old != 20 || changes[0].New != 30 {
+		t.Errorf("Unexpected change %+v", changes[0])
+	}
+	if changes[1].Setting != "snatEnabled" || changes[2].Setting != "accessVisibilityEnabled" {
+		t.Errorf("Unexpected change order %+v", changes)
+	}
+	want := []string{"/settings/users/connection-timeout", "/settings/wpc/snat", "/access-visibility/enable"}
+	if strings.Join(api.puts, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected PUTs %v, got %v", want, api.puts)
+	}
+}
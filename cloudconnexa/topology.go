@@ -0,0 +1,552 @@
+package cloudconnexa
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// TopologyServiceKind tells IP services and applications apart in a Topology.
+type TopologyServiceKind string
+
+const (
+	// TopologyServiceIP is a network or host IP service.
+	TopologyServiceIP TopologyServiceKind = "ip-service"
+	// TopologyServiceApplication is a network or host application.
+	TopologyServiceApplication TopologyServiceKind = "application"
+)
+
+// TopologyRegion is the VPN region a connector is attached to.
+type TopologyRegion struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Country string `json:"country,omitempty"`
+}
+
+// TopologyConnector is a network or host connector.
+type TopologyConnector struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Status      string         `json:"status"`
+	IPv4Address string         `json:"ipV4Address,omitempty"`
+	IPv6Address string         `json:"ipV6Address,omitempty"`
+	Region      TopologyRegion `json:"region"`
+	// IPsecState is the IPsec tunnel state of network connectors with IPsec configured.
+	IPsecState string `json:"ipsecState,omitempty"`
+}
+
+// TopologyService is an IP service or application.
+type TopologyService struct {
+	ID   string              `json:"id"`
+	Name string              `json:"name"`
+	Kind TopologyServiceKind `json:"kind"`
+	Type string              `json:"type,omitempty"`
+	// Routes lists the subnets of IP services or the domains of applications.
+	Routes []string `json:"routes,omitempty"`
+}
+
+// TopologyRoute is a network route with the services it carries.
+type TopologyRoute struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	// Services are the IP services and applications whose routes fall
+	// within this route.
+	Services []TopologyService `json:"services,omitempty"`
+}
+
+// TopologyNetwork is a network with its connectors, routes and services.
+type TopologyNetwork struct {
+	ID             string              `json:"id"`
+	Name           string              `json:"name"`
+	Description    string              `json:"description,omitempty"`
+	InternetAccess string              `json:"internetAccess,omitempty"`
+	Egress         bool                `json:"egress"`
+	Connectors     []TopologyConnector `json:"connectors"`
+	Routes         []TopologyRoute     `json:"routes"`
+	// Services are the IP services and applications not within any route.
+	Services []TopologyService `json:"services,omitempty"`
+}
+
+// TopologyHost is a host with its connectors and services.
+type TopologyHost struct {
+	ID             string              `json:"id"`
+	Name           string              `json:"name"`
+	Description    string              `json:"description,omitempty"`
+	Domain         string              `json:"domain,omitempty"`
+	InternetAccess string              `json:"internetAccess,omitempty"`
+	Connectors     []TopologyConnector `json:"connectors"`
+	Services       []TopologyService   `json:"services"`
+}
+
+// Topology is a hierarchical inventory of the networks and hosts of an
+// organization: network → connectors → region and routes → services, and
+// host → connectors → region and services.
+type Topology struct {
+	Networks []TopologyNetwork `json:"networks"`
+	Hosts    []TopologyHost    `json:"hosts"`
+}
+
+// TopologyInventory holds the resources a Topology is built from.
+type TopologyInventory struct {
+	Networks            []Network
+	Hosts               []Host
+	HostConnectors      []HostConnector
+	NetworkIPServices   []NetworkIPServiceResponse
+	HostIPServices      []HostIPServiceResponse
+	NetworkApplications []NetworkApplicationResponse
+	HostApplications    []ApplicationResponse
+	Regions             []VpnRegion
+}
+
+// TopologyOptions filters the topology built by BuildTopology. If Network or
+// Host is set, only the matching network and host are included.
+type TopologyOptions struct {
+	// Network keeps only the network with this ID or name.
+	Network string
+	// Host keeps only the host with this ID or name.
+	Host string
+}
+
+// LoadTopologyInventory lists networks, hosts, host connectors, IP services,
+// applications and VPN regions.
+func (c *NetworksService) LoadTopologyInventory() (*TopologyInventory, error) {
+	inv := &TopologyInventory{}
+	loaders := []func() error{
+		func() (err error) { inv.Networks, err = c.List(); return err },
+		func() (err error) { inv.Hosts, err = c.client.Hosts.List(); return err },
+		func() (err error) { inv.HostConnectors, err = c.client.HostConnectors.List(); return err },
+		func() (err error) { inv.NetworkIPServices, err = c.client.NetworkIPServices.List(); return err },
+		func() (err error) { inv.HostIPServices, err = c.client.HostIPServices.List(); return err },
+		func() (err error) { inv.NetworkApplications, err = c.client.NetworkApplications.List(); return err },
+		func() (err error) { inv.HostApplications, err = c.client.HostApplications.List(); return err },
+		func() (err error) { inv.Regions, err = c.client.VPNRegions.List(); return err },
+	}
+	errs := make([]error, len(loaders))
+	_ = forEachConcurrent(context.Background(), len(loaders), defaultConcurrency, func(i int) {
+		errs[i] = loaders[i]()
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// Topology loads the inventory and builds its topology.
+func (c *NetworksService) Topology(opts TopologyOptions) (*Topology, error) {
+	inv, err := c.LoadTopologyInventory()
+	if err != nil {
+		return nil, err
+	}
+	return BuildTopology(inv, opts), nil
+}
+
+// BuildTopology stitches the resources of inv into a Topology. IP services
+// and applications are placed under the network route that names them as
+// parent or, failing that, the most specific route containing their subnets
+// or domains. Everything is sorted by name for stable output.
+func BuildTopology(inv *TopologyInventory, opts TopologyOptions) *Topology {
+	regions := make(map[string]VpnRegion, len(inv.Regions))
+	for _, r := range inv.Regions {
+		regions[r.ID] = r
+	}
+	region := func(id string) TopologyRegion {
+		r, ok := regions[id]
+		if !ok || r.RegionName == "" {
+			return TopologyRegion{ID: id, Name: id, Country: r.Country}
+		}
+		return TopologyRegion{ID: id, Name: r.RegionName, Country: r.Country}
+	}
+	filtered := opts.Network != "" || opts.Host != ""
+
+	networkServices := map[string][]topologyServiceRef{}
+	for _, s := range inv.NetworkIPServices {
+		ref := topologyServiceRef{TopologyService: TopologyService{ID: s.ID, Name: s.Name, Kind: TopologyServiceIP, Type: s.Type}}
+		for _, r := range s.Routes {
+			if r == nil {
+				continue
+			}
+			ref.Routes = append(ref.Routes, routeValue(*r))
+			if r.ParentRouteID != "" {
+				ref.parents = appendUnique(ref.parents, r.ParentRouteID)
+			}
+		}
+		networkServices[s.NetworkItemID] = append(networkServices[s.NetworkItemID], ref)
+	}
+	for _, a := range inv.NetworkApplications {
+		ref := topologyServiceRef{TopologyService: TopologyService{ID: a.ID, Name: a.Name, Kind: TopologyServiceApplication}}
+		for _, r := range a.Routes {
+			if r != nil {
+				ref.Routes = append(ref.Routes, r.Domain)
+			}
+		}
+		networkServices[a.NetworkItemID] = append(networkServices[a.NetworkItemID], ref)
+	}
+
+	topology := &Topology{Networks: []TopologyNetwork{}, Hosts: []TopologyHost{}}
+	for _, n := range inv.Networks {
+		if filtered && n.ID != opts.Network && n.Name != opts.Network {
+			continue
+		}
+		network := TopologyNetwork{
+			ID:             n.ID,
+			Name:           n.Name,
+			Description:    n.Description,
+			InternetAccess: n.InternetAccess,
+			Egress:         n.Egress,
+			Connectors:     make([]TopologyConnector, 0, len(n.Connectors)),
+			Routes:         make([]TopologyRoute, 0, len(n.Routes)),
+		}
+		for _, c := range n.Connectors {
+			connector := TopologyConnector{ID: c.ID, Name: c.Name, Status: c.ConnectionStatus, IPv4Address: c.IPv4Address, IPv6Address: c.IPv6Address, Region: region(c.VpnRegionID)}
+			if c.IPSecConfig != nil {
+				connector.IPsecState = c.IPSecConfig.ConnectorState
+			}
+			network.Connectors = append(network.Connectors, connector)
+		}
+		for _, r := range n.Routes {
+			network.Routes = append(network.Routes, TopologyRoute{ID: r.ID, Type: r.Type, Value: routeValue(r), Description: r.Description})
+		}
+		for _, ref := range networkServices[n.ID] {
+			if i := ref.routeIndex(network.Routes); i >= 0 {
+				network.Routes[i].Services = append(network.Routes[i].Services, ref.TopologyService)
+			} else {
+				network.Services = append(network.Services, ref.TopologyService)
+			}
+		}
+		sortTopologyConnectors(network.Connectors)
+		sort.SliceStable(network.Routes, func(i, j int) bool { return network.Routes[i].Value < network.Routes[j].Value })
+		for i := range network.Routes {
+			sortTopologyServices(network.Routes[i].Services)
+		}
+		sortTopologyServices(network.Services)
+		topology.Networks = append(topology.Networks, network)
+	}
+
+	hostConnectors := map[string][]TopologyConnector{}
+	for _, c := range inv.HostConnectors {
+		hostConnectors[c.NetworkItemID] = append(hostConnectors[c.NetworkItemID],
+			TopologyConnector{ID: c.ID, Name: c.Name, Status: c.ConnectionStatus, IPv4Address: c.IPv4Address, IPv6Address: c.IPv6Address, Region: region(c.VpnRegionID)})
+	}
+	hostServices := map[string][]TopologyService{}
+	for _, s := range inv.HostIPServices {
+		hostServices[s.NetworkItemID] = append(hostServices[s.NetworkItemID], TopologyService{ID: s.ID, Name: s.Name, Kind: TopologyServiceIP, Type: s.Type})
+	}
+	for _, a := range inv.HostApplications {
+		service := TopologyService{ID: a.ID, Name: a.Name, Kind: TopologyServiceApplication}
+		for _, r := range a.Routes {
+			if r != nil {
+				service.Routes = append(service.Routes, routeValue(*r))
+			}
+		}
+		hostServices[a.NetworkItemID] = append(hostServices[a.NetworkItemID], service)
+	}
+	for _, h := range inv.Hosts {
+		if filtered && h.ID != opts.Host && h.Name != opts.Host {
+			continue
+		}
+		host := TopologyHost{
+			ID:             h.ID,
+			Name:           h.Name,
+			Description:    h.Description,
+			Domain:         h.Domain,
+			InternetAccess: h.InternetAccess,
+			Connectors:     append([]TopologyConnector{}, hostConnectors[h.ID]...),
+			Services:       append([]TopologyService{}, hostServices[h.ID]...),
+		}
+		sortTopologyConnectors(host.Connectors)
+		sortTopologyServices(host.Services)
+		topology.Hosts = append(topology.Hosts, host)
+	}
+
+	sort.SliceStable(topology.Networks, func(i, j int) bool { return topology.Networks[i].Name < topology.Networks[j].Name })
+	sort.SliceStable(topology.Hosts, func(i, j int) bool { return topology.Hosts[i].Name < topology.Hosts[j].Name })
+	return topology
+}
+
+// topologyServiceRef is a network service with the IDs of the routes its own routes are children of.
+type topologyServiceRef struct {
+	TopologyService
+	parents []string
+}
+
+// routeIndex returns the index of the route in routes the service belongs
+// under, or -1 if there is none.
+func (s topologyServiceRef) routeIndex(routes []TopologyRoute) int {
+	for i, r := range routes {
+		for _, parent := range s.parents {
+			if parent == r.ID {
+				return i
+			}
+		}
+	}
+	best, bestLen := -1, -1
+	for i, r := range routes {
+		for _, value := range s.Routes {
+			if n := routeContains(r.Value, value); n > bestLen {
+				best, bestLen = i, n
+			}
+		}
+	}
+	return best
+}
+
+// routeContains reports how specific route is if it contains value, a subnet
+// or domain, or returns -1 if it does not.
+func routeContains(route, value string) int {
+	if prefix, err := netip.ParsePrefix(route); err == nil {
+		inner, err := netip.ParsePrefix(value)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(value)
+			if addrErr != nil {
+				return -1
+			}
+			inner = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		if inner.Bits() >= prefix.Bits() && prefix.Contains(inner.Addr()) {
+			return prefix.Bits()
+		}
+		return -1
+	}
+	route = strings.TrimPrefix(strings.ToLower(route), "*.")
+	value = strings.ToLower(value)
+	if route != "" && (value == route || strings.HasSuffix(value, "."+route)) {
+		return len(route)
+	}
+	return -1
+}
+
+// routeValue returns the subnet or domain of r.
+func routeValue(r Route) string {
+	if r.Subnet != "" {
+		return r.Subnet
+	}
+	return r.Domain
+}
+
+func sortTopologyConnectors(connectors []TopologyConnector) {
+	sort.SliceStable(connectors, func(i, j int) bool { return connectors[i].Name < connectors[j].Name })
+}
+
+func sortTopologyServices(services []TopologyService) {
+	sort.SliceStable(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+}
+
+// WriteJSON writes the topology as indented JSON.
+func (t *Topology) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(t)
+}
+
+// WriteMarkdown writes the topology as a Markdown report with one section per
+// network and host.
+func (t *Topology) WriteMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Topology")
+	for _, n := range t.Networks {
+		fmt.Fprintf(bw, "\n## Network %s (%s)\n\n", n.Name, n.ID)
+		if n.Description != "" {
+			fmt.Fprintf(bw, "%s\n\n", n.Description)
+		}
+		fmt.Fprintf(bw, "Internet access: %s, egress: %t\n", valueOrNone(n.InternetAccess), n.Egress)
+		writeMarkdownConnectors(bw, n.Connectors)
+		fmt.Fprintln(bw, "\n### Routes")
+		fmt.Fprintln(bw)
+		if len(n.Routes) == 0 {
+			fmt.Fprintln(bw, "None.")
+		}
+		for _, r := range n.Routes {
+			fmt.Fprintf(bw, "- %s (%s)", r.Value, r.Type)
+			if r.Description != "" {
+				fmt.Fprintf(bw, ": %s", r.Description)
+			}
+			fmt.Fprintln(bw)
+			for _, s := range r.Services {
+				fmt.Fprintf(bw, "  - %s\n", s.label())
+			}
+		}
+		if len(n.Services) > 0 {
+			fmt.Fprintln(bw, "\n### Services outside routes")
+			fmt.Fprintln(bw)
+			for _, s := range n.Services {
+				fmt.Fprintf(bw, "- %s\n", s.label())
+			}
+		}
+	}
+	for _, h := range t.Hosts {
+		fmt.Fprintf(bw, "\n## Host %s (%s)\n\n", h.Name, h.ID)
+		if h.Description != "" {
+			fmt.Fprintf(bw, "%s\n\n", h.Description)
+		}
+		fmt.Fprintf(bw, "Domain: %s, internet access: %s\n", valueOrNone(h.Domain), valueOrNone(h.InternetAccess))
+		writeMarkdownConnectors(bw, h.Connectors)
+		fmt.Fprintln(bw, "\n### Services")
+		fmt.Fprintln(bw)
+		if len(h.Services) == 0 {
+			fmt.Fprintln(bw, "None.")
+		}
+		for _, s := range h.Services {
+			fmt.Fprintf(bw, "- %s\n", s.label())
+		}
+	}
+	return bw.Flush()
+}
+
+func writeMarkdownConnectors(bw *bufio.Writer, connectors []TopologyConnector) {
+	fmt.Fprintln(bw, "\n### Connectors")
+	fmt.Fprintln(bw)
+	if len(connectors) == 0 {
+		fmt.Fprintln(bw, "None.")
+	}
+	for _, c := range connectors {
+		fmt.Fprintf(bw, "- %s\n", c.label())
+	}
+}
+
+// WriteHTML writes the topology as a standalone HTML page of nested lists.
+func (t *Topology) WriteHTML(w io.Writer) error {
+	e := html.EscapeString
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "<!DOCTYPE html>")
+	fmt.Fprintln(bw, `<html><head><meta charset="utf-8"><title>Topology</title></head><body>`)
+	fmt.Fprintln(bw, "<h1>Topology</h1>")
+	for _, n := range t.Networks {
+		fmt.Fprintf(bw, "<h2>Network %s <small>%s</small></h2>\n", e(n.Name), e(n.ID))
+		if n.Description != "" {
+			fmt.Fprintf(bw, "<p>%s</p>\n", e(n.Description))
+		}
+		writeHTMLConnectors(bw, n.Connectors)
+		fmt.Fprintln(bw, "<h3>Routes</h3>\n<ul>")
+		for _, r := range n.Routes {
+			fmt.Fprintf(bw, "<li>%s (%s)", e(r.Value), e(r.Type))
+			writeHTMLServices(bw, r.Services)
+			fmt.Fprintln(bw, "</li>")
+		}
+		fmt.Fprintln(bw, "</ul>")
+		if len(n.Services) > 0 {
+			fmt.Fprintln(bw, "<h3>Services outside routes</h3>")
+			writeHTMLServices(bw, n.Services)
+		}
+	}
+	for _, h := range t.Hosts {
+		fmt.Fprintf(bw, "<h2>Host %s <small>%s</small></h2>\n", e(h.Name), e(h.ID))
+		if h.Description != "" {
+			fmt.Fprintf(bw, "<p>%s</p>\n", e(h.Description))
+		}
+		writeHTMLConnectors(bw, h.Connectors)
+		fmt.Fprintln(bw, "<h3>Services</h3>")
+		writeHTMLServices(bw, h.Services)
+	}
+	fmt.Fprintln(bw, "</body></html>")
+	return bw.Flush()
+}
+
+func writeHTMLConnectors(bw *bufio.Writer, connectors []TopologyConnector) {
+	fmt.Fprintln(bw, "<h3>Connectors</h3>\n<ul>")
+	for _, c := range connectors {
+		fmt.Fprintf(bw, "<li>%s</li>\n", html.EscapeString(c.label()))
+	}
+	fmt.Fprintln(bw, "</ul>")
+}
+
+func writeHTMLServices(bw *bufio.Writer, services []TopologyService) {
+	if len(services) == 0 {
+		return
+	}
+	fmt.Fprintln(bw, "<ul>")
+	for _, s := range services {
+		fmt.Fprintf(bw, "<li>%s</li>\n", html.EscapeString(s.label()))
+	}
+	fmt.Fprintln(bw, "</ul>")
+}
+
+// WriteDOT writes the topology in Graphviz DOT format. Regions are shared
+// nodes, so connectors in the same region point at the same node.
+func (t *Topology) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph topology {")
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	regions := map[string]bool{}
+	node := func(id, label, shape string) {
+		fmt.Fprintf(bw, "  %s [label=%s, shape=%s];\n", dotQuote(id), dotQuote(label), shape)
+	}
+	edge := func(from, to string) {
+		fmt.Fprintf(bw, "  %s -> %s;\n", dotQuote(from), dotQuote(to))
+	}
+	connectors := func(parent string, list []TopologyConnector) {
+		for _, c := range list {
+			id := "connector:" + c.ID
+			node(id, c.Name, "component")
+			edge(parent, id)
+			regionID := "region:" + c.Region.ID
+			if !regions[regionID] {
+				regions[regionID] = true
+				node(regionID, c.Region.Name, "ellipse")
+			}
+			edge(id, regionID)
+		}
+	}
+	services := func(parent string, list []TopologyService) {
+		for _, s := range list {
+			id := "service:" + s.ID
+			node(id, s.Name, "note")
+			edge(parent, id)
+		}
+	}
+	for _, n := range t.Networks {
+		id := "network:" + n.ID
+		node(id, n.Name, "box")
+		connectors(id, n.Connectors)
+		for _, r := range n.Routes {
+			routeID := "route:" + r.ID
+			node(routeID, r.Value, "cds")
+			edge(id, routeID)
+			services(routeID, r.Services)
+		}
+		services(id, n.Services)
+	}
+	for _, h := range t.Hosts {
+		id := "host:" + h.ID
+		node(id, h.Name, "box3d")
+		connectors(id, h.Connectors)
+		services(id, h.Services)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func (c TopologyConnector) label() string {
+	label := fmt.Sprintf("%s (%s): %s, region %s", c.Name, c.ID, valueOrNone(c.Status), c.Region.Name)
+	if c.IPsecState != "" {
+		label += ", IPsec " + c.IPsecState
+	}
+	return label
+}
+
+func (s TopologyService) label() string {
+	kind := "IP service"
+	if s.Kind == TopologyServiceApplication {
+		kind = "Application"
+	}
+	label := fmt.Sprintf("%s %s (%s)", kind, s.Name, s.ID)
+	if len(s.Routes) > 0 {
+		label += ": " + strings.Join(s.Routes, ", ")
+	}
+	return label
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,161 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testTopologyInventory() *TopologyInventory {
+	return &TopologyInventory{
+		Networks: []Network{
+			{
+				ID:   "n-2",
+				Name: "office",
+				Connectors: []NetworkConnector{
+					{ID: "nc-2", Name: "fra", VpnRegionID: "eu-central-1", ConnectionStatus: "ONLINE", IPSecConfig: &IPSecConfig{ConnectorState: "ACTIVE"}},
+				},
+				Routes: []Route{
+					{ID: "r-1", Type: "IP_V4", Subnet: "10.0.0.0/16"},
+					{ID: "r-2", Type: "IP_V4", Subnet: "10.0.1.0/24"},
+					{ID: "r-3", Type: "DOMAIN", Domain: "corp.example.com"},
+				},
+			},
+			{ID: "n-1", Name: "lab"},
+		},
+		Hosts: []Host{{ID: "h-1", Name: "db <primary>"}},
+		HostConnectors: []HostConnector{
+			{ID: "hc-1", Name: "db-connector", NetworkItemID: "h-1", VpnRegionID: "eu-central-1", ConnectionStatus: "OFFLINE"},
+		},
+		NetworkIPServices: []NetworkIPServiceResponse{
+			{ID: "s-1", Name: "web", NetworkItemID: "n-2", Type: "SERVICE_DESTINATION", Routes: []*Route{{Subnet: "10.0.1.5/32"}}},
+			{ID: "s-2", Name: "ssh", NetworkItemID: "n-2", Routes: []*Route{{Subnet: "10.9.0.0/24", ParentRouteID: "r-1"}}},
+			{ID: "s-3", Name: "legacy", NetworkItemID: "n-2", Routes: []*Route{{Subnet: "192.168.0.0/24"}}},
+		},
+		NetworkApplications: []NetworkApplicationResponse{
+			{NetworkApplication: NetworkApplication{ID: "a-1", Name: "wiki", NetworkItemID: "n-2"}, Routes: []*NetworkApplicationDomainRoute{{Domain: "wiki.corp.example.com"}}},
+		},
+		HostIPServices: []HostIPServiceResponse{{ID: "s-4", Name: "postgres", NetworkItemID: "h-1"}},
+		Regions:        []VpnRegion{{ID: "eu-central-1", RegionName: "Frankfurt", Country: "Germany"}},
+	}
+}
+
+func TestBuildTopology(t *testing.T) {
+	topology := BuildTopology(testTopologyInventory(), TopologyOptions{})
+	if len(topology.Networks) != 2 || topology.Networks[0].Name != "lab" || len(topology.Hosts) != 1 {
+		t.Fatalf("Expected 2 networks sorted by name and 1 host, got %+v", topology)
+	}
+	office := topology.Networks[1]
+	if c := office.Connectors[0]; c.Region.Name != "Frankfurt" || c.IPsecState != "ACTIVE" {
+		t.Errorf("Unexpected connector %+v", c)
+	}
+
+	services := map[string][]string{}
+	for _, r := range office.Routes {
+		for _, s := range r.Services {
+			services[r.Value] = append(services[r.Value], s.Name)
+		}
+	}
+	if got := services["10.0.1.0/24"]; len(got) != 1 || got[0] != "web" {
+		t.Errorf("Expected web under the most specific route, got %v", got)
+	}
+	if got := services["10.0.0.0/16"]; len(got) != 1 || got[0] != "ssh" {
+		t.Errorf("Expected ssh under its parent route, got %v", got)
+	}
+	if got := services["corp.example.com"]; len(got) != 1 || got[0] != "wiki" {
+		t.Errorf("Expected wiki under the domain route, got %v", got)
+	}
+	if len(office.Services) != 1 || office.Services[0].Name != "legacy" {
+		t.Errorf("Expected legacy outside routes, got %+v", office.Services)
+	}
+
+	host := topology.Hosts[0]
+	if len(host.Connectors) != 1 || host.Connectors[0].Status != "OFFLINE" || len(host.Services) != 1 {
+		t.Errorf("Unexpected host %+v", host)
+	}
+
+	filtered := BuildTopology(testTopologyInventory(), TopologyOptions{Network: "office"})
+	if len(filtered.Networks) != 1 || filtered.Networks[0].ID != "n-2" || len(filtered.Hosts) != 0 {
+		t.Errorf("Expected only the office network, got %+v", filtered)
+	}
+}
+
+func TestTopology_Write(t *testing.T) {
+	topology := BuildTopology(testTopologyInventory(), TopologyOptions{})
+
+	var md bytes.Buffer
+	if err := topology.WriteMarkdown(&md); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, want := range []string{
+		"## Network office (n-2)",
+		"- fra (nc-2): ONLINE, region Frankfurt, IPsec ACTIVE",
+		"- 10.0.1.0/24 (IP_V4)\n  - IP service web (s-1): 10.0.1.5/32",
+		"### Services outside routes\n\n- IP service legacy (s-3): 192.168.0.0/24",
+		"## Host db <primary> (h-1)",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Expected Markdown to contain %q, got:\n%s", want, md.String())
+		}
+	}
+
+	var page bytes.Buffer
+	if err := topology.WriteHTML(&page); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(page.String(), "Host db &lt;primary&gt;") || strings.Contains(page.String(), "<primary>") {
+		t.Errorf("Expected escaped HTML, got:\n%s", page.String())
+	}
+
+	var dot bytes.Buffer
+	if err := topology.WriteDOT(&dot); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Count(dot.String(), `"region:eu-central-1" [`) != 1 || !strings.Contains(dot.String(), `"route:r-2" -> "service:s-1";`) {
+		t.Errorf("Unexpected DOT output:\n%s", dot.String())
+	}
+
+	var out bytes.Buffer
+	if err := topology.WriteJSON(&out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var decoded Topology
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded.Networks) != 2 {
+		t.Errorf("Expected the topology as JSON, got %+v, %v", decoded, err)
+	}
+}
+
+func TestNetworksService_Topology(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/networks":
+			_ = json.NewEncoder(w).Encode(NetworkPageResponse{Content: []Network{{ID: "n-1", Name: "office", Routes: []Route{{ID: "r-1", Subnet: "10.0.0.0/24"}}}}, TotalPages: 1})
+		case "/api/v1/networks/ip-services":
+			_ = json.NewEncoder(w).Encode(NetworkIPServicePageResponse{Content: []NetworkIPServiceResponse{{ID: "s-1", Name: "web", NetworkItemID: "n-1", Routes: []*Route{{Subnet: "10.0.0.5/32"}}}}, TotalPages: 1})
+		case "/api/v1/hosts":
+			_ = json.NewEncoder(w).Encode(HostPageResponse{Content: []Host{{ID: "h-1", Name: "db"}}, TotalPages: 1})
+		case "/api/v1/hosts/connectors":
+			_ = json.NewEncoder(w).Encode(HostConnectorPageResponse{Content: []HostConnector{{ID: "hc-1", Name: "db-connector", NetworkItemID: "h-1", VpnRegionID: "eu"}}, TotalPages: 1})
+		case "/api/v1/regions":
+			_ = json.NewEncoder(w).Encode([]VpnRegion{{ID: "eu", RegionName: "Frankfurt"}})
+		default:
+			_, _ = w.Write([]byte(`{"content":[],"totalPages":1}`))
+		}
+	}))
+	defer server.Close()
+	client := createTestClientWithServices(server)
+
+	topology, err := client.Networks.Topology(TopologyOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(topology.Networks) != 1 || len(topology.Networks[0].Routes) != 1 || len(topology.Networks[0].Routes[0].Services) != 1 {
+		t.Errorf("Expected web under the office route, got %+v", topology.Networks)
+	}
+	if len(topology.Hosts) != 1 || len(topology.Hosts[0].Connectors) != 1 || topology.Hosts[0].Connectors[0].Region.Name != "Frankfurt" {
+		t.Errorf("Expected db with its connector in Frankfurt, got %+v", topology.Hosts)
+	}
+}
@@ -97,17 +97,25 @@ func (c *UserGroupsService) List() ([]UserGroup, error) {
 // name: The name of the user group to retrieve
 // Returns the user group and any error that occurred
 func (c *UserGroupsService) GetByName(name string) (*UserGroup, error) {
-	userGroups, err := c.List()
+	items, err := findByKey(c.client, "user-groups", c.List, c.pages, nameKey[UserGroup], name, 1)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, ug := range userGroups {
-		if ug.Name == name {
-			return &ug, nil
-		}
+	if len(items) == 0 {
+		return nil, ErrUserGroupNotFound
 	}
-	return nil, ErrUserGroupNotFound
+	return &items[0], nil
+}
+
+// Query returns the user groups matching all preds, e.g. Query(NamePrefix[UserGroup]("prod-")).
+func (c *UserGroupsService) Query(preds ...Predicate[UserGroup]) ([]UserGroup, error) {
+	return queryCollection(c.client, "user-groups", c.List, c.pages, nameKey[UserGroup], preds)
+}
+
+// pages adapts GetByPage to a pageLister.
+func (c *UserGroupsService) pages(page, size int) ([]UserGroup, int, error) {
+	response, err := c.GetByPage(page, size)
+	return response.Content, response.TotalPages, err
 }
 
 // GetByID retrieves a user group by its ID using the direct API endpoint.
@@ -160,6 +168,9 @@ func (c *UserGroupsService) Get(id string) (*UserGroup, error) {
 // userGroup: The user group configuration to create
 // Returns the created user group and any error that occurred
 func (c *UserGroupsService) Create(userGroup *UserGroup) (*UserGroup, error) {
+	if err := c.validateEnums(userGroup); err != nil {
+		return nil, err
+	}
 	userGroupJSON, err := json.Marshal(userGroup)
 	if err != nil {
 		return nil, err
@@ -191,6 +202,9 @@ func (c *UserGroupsService) Update(id string, userGroup *UserGroup) (*UserGroup,
 	if err := validateID(id); err != nil {
 		return nil, err
 	}
+	if err := c.validateEnums(userGroup); err != nil {
+		return nil, err
+	}
 	userGroupJSON, err := json.Marshal(userGroup)
 	if err != nil {
 		return nil, err
@@ -231,3 +245,14 @@ func (c *UserGroupsService) Delete(id string) error {
 	}
 	return nil
 }
+
+// validateEnums checks the enum fields of userGroup.
+func (c *UserGroupsService) validateEnums(userGroup *UserGroup) error {
+	if userGroup == nil {
+		return nil
+	}
+	if err := c.client.validateEnum("connectAuth", userGroup.ConnectAuth, connectAuthValues); err != nil {
+		return err
+	}
+	return c.client.validateEnum("internetAccess", userGroup.InternetAccess, internetAccessValues)
+}
@@ -13,6 +13,13 @@ var (
 	ErrUserNotFound = errors.New("user not found")
 )
 
+const (
+	// UserStatusActive is the status of a user that can connect.
+	UserStatusActive = "ACTIVE"
+	// UserStatusSuspended is the status of a user suspended via Suspend or the admin UI.
+	UserStatusSuspended = "SUSPENDED"
+)
+
 // User represents a user configuration.
 // Fields match the API v1.2.0 UserResponse schema.
 type User struct {
@@ -80,7 +87,7 @@ func (c *UsersService) GetByPage(page int, pageSize int) (UserPageResponse, erro
 
 // List retrieves all users by paginating through all available pages.
 // The CloudConnexa API does not support filtering server-side beyond
-// pagination — use FindByUsernameAndRole or GetByUsername for filtered lookups.
+// pagination — use FindByUsernameAndRole, GetByUsername or Query for filtered lookups.
 // Returns the full slice of users and any error that occurred.
 func (c *UsersService) List() ([]User, error) {
 	var allUsers []User
@@ -102,33 +109,22 @@ func (c *UsersService) List() ([]User, error) {
 	return allUsers, nil
 }
 
-// FindByUsernameAndRole returns the first user matching both username and role
-// by listing all users and filtering client-side. The CloudConnexa API does not
-// expose a username/role filter, so this pages through the full user list.
+// FindByUsernameAndRole returns the first user matching both username and role,
+// filtering client-side. The CloudConnexa API does not expose a username/role
+// filter, so this pages through the user list until a match is found, or uses
+// the lookup index when Client.LookupIndexTTL is set.
 // username: The username to search for
 // role: The role to filter by
 // Returns the user and any error that occurred
 func (c *UsersService) FindByUsernameAndRole(username string, role string) (*User, error) {
-	page := 0
-
-	for {
-		response, err := c.GetByPage(page, defaultPageSize)
-		if err != nil {
-			return nil, err
-		}
-
-		for _, user := range response.Content {
-			if user.Username == username && user.Role == role {
-				return &user, nil
-			}
-		}
-
-		page++
-		if page >= response.TotalPages {
-			break
-		}
+	users, err := findByKey(c.client, "users", c.List, c.pages, nameKey[User], username, 1, Where(func(u User) string { return u.Role }, role))
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, ErrUserNotFound
 	}
-	return nil, ErrUserNotFound
+	return &users[0], nil
 }
 
 // Get retrieves a user by ID
@@ -168,26 +164,25 @@ func (c *UsersService) GetByID(userID string) (*User, error) {
 // username: The username to search for
 // Returns the user and any error that occurred
 func (c *UsersService) GetByUsername(username string) (*User, error) {
-	page := 0
-
-	for {
-		response, err := c.GetByPage(page, defaultPageSize)
-		if err != nil {
-			return nil, err
-		}
+	users, err := findByKey(c.client, "users", c.List, c.pages, nameKey[User], username, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, ErrUserNotFound
+	}
+	return &users[0], nil
+}
 
-		for _, user := range response.Content {
-			if user.Username == username {
-				return &user, nil
-			}
-		}
+// Query returns the users matching all preds, e.g. Query(FieldEquals[User]("role", "ADMIN")).
+func (c *UsersService) Query(preds ...Predicate[User]) ([]User, error) {
+	return queryCollection(c.client, "users", c.List, c.pages, nameKey[User], preds)
+}
 
-		page++
-		if page >= response.TotalPages {
-			break
-		}
-	}
-	return nil, ErrUserNotFound
+// pages adapts GetByPage to a pageLister.
+func (c *UsersService) pages(page, size int) ([]User, int, error) {
+	response, err := c.GetByPage(page, size)
+	return response.Content, response.TotalPages, err
 }
 
 // Create creates a new user
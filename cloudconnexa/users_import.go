@@ -0,0 +1,462 @@
+package cloudconnexa
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidUserRecord is returned for an import row that fails validation.
+var ErrInvalidUserRecord = errors.New("invalid user record")
+
+// UserFileFormat is the file format read by UsersService.Import and written by UsersService.Export.
+type UserFileFormat string
+
+const (
+	// UserFileCSV is RFC 4180 CSV with a header row naming UserFileColumns.
+	// Secondary groups are separated by semicolons.
+	UserFileCSV UserFileFormat = "csv"
+	// UserFileJSON is a JSON array of UserRecord objects.
+	UserFileJSON UserFileFormat = "json"
+)
+
+// UserFileColumns are the CSV columns of user files. Columns may appear in any
+// order; only username is required.
+var UserFileColumns = []string{"username", "email", "first_name", "last_name", "role", "group", "secondary_groups"}
+
+// UserRecord is one row of a user file. Groups are referenced by name.
+type UserRecord struct {
+	Username        string   `json:"username"`
+	Email           string   `json:"email,omitempty"`
+	FirstName       string   `json:"firstName,omitempty"`
+	LastName        string   `json:"lastName,omitempty"`
+	Role            string   `json:"role,omitempty"`
+	Group           string   `json:"group,omitempty"`
+	SecondaryGroups []string `json:"secondaryGroups,omitempty"`
+}
+
+// ReadUserRecords reads user records in format from r.
+func ReadUserRecords(r io.Reader, format UserFileFormat) ([]UserRecord, error) {
+	switch format {
+	case UserFileJSON:
+		var records []UserRecord
+		if err := json.NewDecoder(r).Decode(&records); err != nil {
+			return nil, err
+		}
+		return records, nil
+	case UserFileCSV:
+		reader := csv.NewReader(r)
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, nil
+		}
+		columns := map[string]int{}
+		for i, name := range rows[0] {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if !slices.Contains(UserFileColumns, name) {
+				return nil, fmt.Errorf("unknown user file column %q", name)
+			}
+			columns[name] = i
+		}
+		if _, ok := columns["username"]; !ok {
+			return nil, errors.New("user file has no username column")
+		}
+		field := func(row []string, name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		records := make([]UserRecord, 0, len(rows)-1)
+		for _, row := range rows[1:] {
+			record := UserRecord{
+				Username:  field(row, "username"),
+				Email:     field(row, "email"),
+				FirstName: field(row, "first_name"),
+				LastName:  field(row, "last_name"),
+				Role:      field(row, "role"),
+				Group:     field(row, "group"),
+			}
+			for _, group := range strings.Split(field(row, "secondary_groups"), ";") {
+				if group = strings.TrimSpace(group); group != "" {
+					record.SecondaryGroups = append(record.SecondaryGroups, group)
+				}
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	default:
+		return nil, fmt.Errorf("unsupported user file format %q", format)
+	}
+}
+
+// WriteUserRecords writes user records in format to w.
+func WriteUserRecords(w io.Writer, format UserFileFormat, records []UserRecord) error {
+	switch format {
+	case UserFileJSON:
+		if records == nil {
+			records = []UserRecord{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case UserFileCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(UserFileColumns); err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := cw.Write([]string{r.Username, r.Email, r.FirstName, r.LastName, r.Role, r.Group, strings.Join(r.SecondaryGroups, ";")}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unsupported user file format %q", format)
+	}
+}
+
+// UserFileFormatFromPath returns the user file format matching the extension of path.
+func UserFileFormatFromPath(path string) (UserFileFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return UserFileCSV, nil
+	case ".json":
+		return UserFileJSON, nil
+	default:
+		return "", fmt.Errorf("cannot infer user file format from %q", path)
+	}
+}
+
+// UserImportAction is what an import did, or would do in a dry run, with a row.
+type UserImportAction string
+
+const (
+	// UserImportCreate marks a row creating a new user.
+	UserImportCreate UserImportAction = "create"
+	// UserImportUpdate marks a row updating an existing user.
+	UserImportUpdate UserImportAction = "update"
+	// UserImportUnchanged marks a row matching an existing user.
+	UserImportUnchanged UserImportAction = "unchanged"
+	// UserImportSkip marks a row for an existing user when upserts are disabled.
+	UserImportSkip UserImportAction = "skip"
+	// UserImportError marks a row that failed validation or whose request failed.
+	UserImportError UserImportAction = "error"
+)
+
+// UserImportOptions configures UsersService.Import.
+type UserImportOptions struct {
+	// Upsert updates existing users to match their rows. By default rows for
+	// existing usernames are skipped. Either way re-running an import is idempotent.
+	Upsert bool
+	// DryRun validates rows and reports the planned actions without writing.
+	DryRun bool
+	// DefaultRole is the role of created users whose row has none. Defaults to "MEMBER".
+	DefaultRole string
+	// Concurrency is the number of rows processed in parallel. Defaults to 4.
+	// All writes still go through the client's update rate limiter.
+	Concurrency int
+}
+
+// UserImportResult reports the outcome of one row.
+type UserImportResult struct {
+	// Row is the 1-based position of the record in the input.
+	Row      int              `json:"row"`
+	Username string           `json:"username"`
+	UserID   string           `json:"userId,omitempty"`
+	Action   UserImportAction `json:"action"`
+	Error    string           `json:"error,omitempty"`
+	Err      error            `json:"-"`
+}
+
+func (r *UserImportResult) setErr(err error) {
+	r.Action = UserImportError
+	r.Err = err
+	r.Error = err.Error()
+}
+
+// Import creates or updates users from records. Group names are resolved to
+// GroupID and SecondaryGroupIDs. Every row is validated before any write, and
+// per-row failures are reported in the results; the returned error is only set
+// when the import could not start or ctx was cancelled. Rows not written
+// because ctx was cancelled are reported as errors wrapping ctx.Err().
+//
+// Empty optional fields leave the existing value of an updated user untouched.
+func (c *UsersService) Import(ctx context.Context, records []UserRecord, opts *UserImportOptions) ([]UserImportResult, error) {
+	if opts == nil {
+		opts = &UserImportOptions{}
+	}
+	existing, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := c.client.UserGroups.List()
+	if err != nil {
+		return nil, err
+	}
+	usersByName := make(map[string]User, len(existing))
+	for _, u := range existing {
+		usersByName[u.Username] = u
+	}
+	groupIDs := make(map[string]string, len(groups))
+	for _, g := range groups {
+		groupIDs[g.Name] = g.ID
+	}
+
+	results := make([]UserImportResult, len(records))
+	users := make([]User, len(records))
+	seen := map[string]int{}
+	for i, record := range records {
+		results[i] = UserImportResult{Row: i + 1, Username: record.Username}
+		user, err := userFromRecord(record, groupIDs)
+		if err == nil && seen[record.Username] > 0 {
+			err = fmt.Errorf("%w: username %q already appears in row %d", ErrInvalidUserRecord, record.Username, seen[record.Username])
+		}
+		if err != nil {
+			results[i].setErr(err)
+			continue
+		}
+		seen[record.Username] = i + 1
+
+		current, exists := usersByName[record.Username]
+		switch {
+		case !exists:
+			if user.Role == "" {
+				user.Role = opts.DefaultRole
+				if user.Role == "" {
+					user.Role = "MEMBER"
+				}
+			}
+			results[i].Action = UserImportCreate
+		case !opts.Upsert:
+			results[i].UserID, results[i].Action = current.ID, UserImportSkip
+		default:
+			user = mergeUserRecord(current, user)
+			results[i].UserID, results[i].Action = current.ID, UserImportUpdate
+			if userRecordEqual(current, user) {
+				results[i].Action = UserImportUnchanged
+			}
+		}
+		users[i] = user
+	}
+	if opts.DryRun {
+		return results, nil
+	}
+
+	started := make([]bool, len(records))
+	err = forEachConcurrent(ctx, len(records), opts.Concurrency, func(i int) {
+		if ctx.Err() != nil {
+			return
+		}
+		started[i] = true
+		r := &results[i]
+		switch r.Action {
+		case UserImportCreate:
+			created, err := c.Create(users[i])
+			if err != nil {
+				r.setErr(fmt.Errorf("create user: %w", err))
+				return
+			}
+			r.UserID = created.ID
+		case UserImportUpdate:
+			if err := c.Update(users[i]); err != nil {
+				r.setErr(fmt.Errorf("update user: %w", err))
+			}
+		}
+	})
+	// Rows not started before ctx was cancelled were never written.
+	for i := range results {
+		r := &results[i]
+		if !started[i] && (r.Action == UserImportCreate || r.Action == UserImportUpdate) {
+			if err == nil {
+				err = ctx.Err()
+			}
+			r.setErr(fmt.Errorf("not imported: %w", ctx.Err()))
+		}
+	}
+	return results, err
+}
+
+// ImportFile reads user records from path, with the format inferred from its
+// extension, and imports them. If resultPath is not empty the per-row results
+// are written there as CSV, or as JSON if it ends in .json.
+func (c *UsersService) ImportFile(ctx context.Context, path, resultPath string, opts *UserImportOptions) ([]UserImportResult, error) {
+	format, err := UserFileFormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path) //nolint:gosec // path is chosen by the caller
+	if err != nil {
+		return nil, err
+	}
+	records, err := ReadUserRecords(f, format)
+	_ = f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.Import(ctx, records, opts)
+	if resultPath == "" || results == nil {
+		return results, err
+	}
+	out, createErr := os.Create(resultPath) //nolint:gosec // path is chosen by the caller
+	if createErr != nil {
+		return results, errors.Join(err, createErr)
+	}
+	writeErr := WriteUserImportResults(out, results, strings.EqualFold(filepath.Ext(resultPath), ".json"))
+	return results, errors.Join(err, writeErr, out.Close())
+}
+
+// WriteUserImportResults writes per-row import results as CSV, or as a JSON array if asJSON is set.
+func WriteUserImportResults(w io.Writer, results []UserImportResult, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"row", "username", "user_id", "action", "error"}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := cw.Write([]string{fmt.Sprint(r.Row), r.Username, r.UserID, string(r.Action), r.Error}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportRecords lists users as records, with groups referenced by name.
+func (c *UsersService) ExportRecords() ([]UserRecord, error) {
+	users, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := c.client.UserGroups.List()
+	if err != nil {
+		return nil, err
+	}
+	groupNames := make(map[string]string, len(groups))
+	for _, g := range groups {
+		groupNames[g.ID] = g.Name
+	}
+	name := func(id string) string {
+		if n, ok := groupNames[id]; ok {
+			return n
+		}
+		return id
+	}
+
+	records := make([]UserRecord, 0, len(users))
+	for _, u := range users {
+		record := UserRecord{
+			Username:  u.Username,
+			Email:     u.Email,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Role:      u.Role,
+		}
+		if u.GroupID != "" {
+			record.Group = name(u.GroupID)
+		}
+		for _, id := range u.SecondaryGroupIDs {
+			record.SecondaryGroups = append(record.SecondaryGroups, name(id))
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Export writes all users to w in format, which Import reads back.
+func (c *UsersService) Export(w io.Writer, format UserFileFormat) error {
+	records, err := c.ExportRecords()
+	if err != nil {
+		return err
+	}
+	return WriteUserRecords(w, format, records)
+}
+
+// userFromRecord validates record and converts it to a User with resolved group IDs.
+func userFromRecord(record UserRecord, groupIDs map[string]string) (User, error) {
+	user := User{
+		Username:  record.Username,
+		Email:     record.Email,
+		FirstName: record.FirstName,
+		LastName:  record.LastName,
+		Role:      record.Role,
+	}
+	if strings.TrimSpace(record.Username) == "" {
+		return user, fmt.Errorf("%w: username is required", ErrInvalidUserRecord)
+	}
+	if record.Email != "" {
+		if addr, err := mail.ParseAddress(record.Email); err != nil || addr.Address != record.Email {
+			return user, fmt.Errorf("%w: invalid email %q", ErrInvalidUserRecord, record.Email)
+		}
+	}
+	if record.Group != "" {
+		id, ok := groupIDs[record.Group]
+		if !ok {
+			return user, fmt.Errorf("%w: unknown user group %q", ErrInvalidUserRecord, record.Group)
+		}
+		user.GroupID = id
+	}
+	for _, name := range record.SecondaryGroups {
+		id, ok := groupIDs[name]
+		if !ok {
+			return user, fmt.Errorf("%w: unknown user group %q", ErrInvalidUserRecord, name)
+		}
+		user.SecondaryGroupIDs = append(user.SecondaryGroupIDs, id)
+	}
+	return user, nil
+}
+
+// mergeUserRecord applies the non-empty fields of update to current.
+func mergeUserRecord(current, update User) User {
+	merged := current
+	merged.Devices = nil
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&merged.Email, update.Email},
+		{&merged.FirstName, update.FirstName},
+		{&merged.LastName, update.LastName},
+		{&merged.Role, update.Role},
+		{&merged.GroupID, update.GroupID},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	if update.SecondaryGroupIDs != nil {
+		merged.SecondaryGroupIDs = update.SecondaryGroupIDs
+	}
+	return merged
+}
+
+// userRecordEqual reports whether the fields managed by user files are equal.
+func userRecordEqual(a, b User) bool {
+	return a.Email == b.Email && a.FirstName == b.FirstName && a.LastName == b.LastName &&
+		a.Role == b.Role && a.GroupID == b.GroupID &&
+		slices.Equal(sortedCopy(a.SecondaryGroupIDs), sortedCopy(b.SecondaryGroupIDs))
+}
+
+func sortedCopy(values []string) []string {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted
+}
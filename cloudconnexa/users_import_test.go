@@ -0,0 +1,223 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testUserCSV = `username,email,first_name,last_name,role,group,secondary_groups
+alice,alice@example.com,Alice,Smith,ADMIN,Engineering,Sales
+bob,bob@example.com,Bob,,,Sales,
+carol,carol@example.com,,,,Marketing,
+,nobody@example.com,,,,,
+dave,not-an-email,,,,,
+`
+
+type usersImportAPI struct {
+	mu     sync.Mutex
+	writes []string
+	posted []User
+	put    []User
+}
+
+func (a *usersImportAPI) server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/users":
+			_ = json.NewEncoder(w).Encode(UserPageResponse{
+				Content: []User{
+					{ID: "u-alice", Username: "alice", Email: "alice@example.com", FirstName: "Alice", LastName: "Smith", Role: "ADMIN", GroupID: "ug-eng", SecondaryGroupIDs: []string{"ug-sales"}},
+					{ID: "u-bob", Username: "bob", Email: "bob@old.example", Role: "MEMBER", GroupID: "ug-eng", SecondaryGroupIDs: []string{"ug-gone"}},
+				},
+				TotalPages: 1,
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/user-groups":
+			_ = json.NewEncoder(w).Encode(UserGroupPageResponse{
+				Content:    []UserGroup{{ID: "ug-eng", Name: "Engineering"}, {ID: "ug-sales", Name: "Sales"}},
+				TotalPages: 1,
+			})
+		case r.Method == http.MethodPost:
+			var u User
+			_ = json.NewDecoder(r.Body).Decode(&u)
+			a.posted = append(a.posted, u)
+			a.writes = append(a.writes, "POST "+u.Username)
+			u.ID = "u-" + u.Username
+			_ = json.NewEncoder(w).Encode(u)
+		case r.Method == http.MethodPut:
+			var u User
+			_ = json.NewDecoder(r.Body).Decode(&u)
+			a.put = append(a.put, u)
+			a.writes = append(a.writes, "PUT "+r.URL.Path)
+		}
+	}))
+}
+
+func importActions(results []UserImportResult) string {
+	actions := make([]string, 0, len(results))
+	for _, r := range results {
+		actions = append(actions, string(r.Action))
+	}
+	return strings.Join(actions, ",")
+}
+
+func TestReadUserRecords_CSV(t *testing.T) {
+	records, err := ReadUserRecords(strings.NewReader(testUserCSV), UserFileCSV)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("Expected 5 records, got %d", len(records))
+	}
+	want := UserRecord{Username: "alice", Email: "alice@example.com", FirstName: "Alice", LastName: "Smith", Role: "ADMIN", Group: "Engineering", SecondaryGroups: []string{"Sales"}}
+	if !reflect.DeepEqual(records[0], want) {
+		t.Errorf("Expected %+v, got %+v", want, records[0])
+	}
+
+	if _, err := ReadUserRecords(strings.NewReader("login,email\nalice,a@example.com\n"), UserFileCSV); err == nil {
+		t.Error("Expected an error for an unknown column")
+	}
+}
+
+func TestWriteUserRecords_RoundTrip(t *testing.T) {
+	records := []UserRecord{
+		{Username: "alice", Email: "alice@example.com", Group: "Engineering", SecondaryGroups: []string{"Sales", "Support"}},
+		{Username: "bob"},
+	}
+	for _, format := range []UserFileFormat{UserFileCSV, UserFileJSON} {
+		var buf bytes.Buffer
+		if err := WriteUserRecords(&buf, format, records); err != nil {
+			t.Fatalf("%s: write failed: %v", format, err)
+		}
+		got, err := ReadUserRecords(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: read failed: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, records) {
+			t.Errorf("%s: expected %+v, got %+v", format, records, got)
+		}
+	}
+}
+
+func TestUsersService_Import(t *testing.T) {
+	api := &usersImportAPI{}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+
+	records, _ := ReadUserRecords(strings.NewReader(testUserCSV), UserFileCSV)
+	records = append(records, UserRecord{Username: "erin", Group: "Sales"}, UserRecord{Username: "erin"})
+
+	results, err := client.Users.Import(context.Background(), records, &UserImportOptions{Upsert: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := importActions(results); got != "unchanged,update,error,error,error,create,error" {
+		t.Errorf("Unexpected dry run actions %s", got)
+	}
+	if len(api.writes) != 0 {
+		t.Errorf("Expected no writes in a dry run, got %v", api.writes)
+	}
+	if !errors.Is(results[2].Err, ErrInvalidUserRecord) || !strings.Contains(results[2].Error, "Marketing") {
+		t.Errorf("Expected unknown group error, got %v", results[2].Err)
+	}
+
+	results, err = client.Users.Import(context.Background(), records, &UserImportOptions{Upsert: true, Concurrency: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Join(api.writes, ",") != "PUT /api/v1/users/u-bob,POST erin" {
+		t.Errorf("Unexpected writes %v", api.writes)
+	}
+	if api.put[0].Email != "bob@example.com" || api.put[0].GroupID != "ug-sales" || api.put[0].SecondaryGroupIDs[0] != "ug-gone" {
+		t.Errorf("Expected bob to be merged, got %+v", api.put[0])
+	}
+	if api.posted[0].Role != "MEMBER" || api.posted[0].GroupID != "ug-sales" || results[5].UserID != "u-erin" {
+		t.Errorf("Unexpected created user %+v, result %+v", api.posted[0], results[5])
+	}
+
+	results, err = client.Users.Import(context.Background(), records[:2], nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := importActions(results); got != "skip,skip" {
+		t.Errorf("Expected existing users to be skipped without upsert, got %s", got)
+	}
+}
+
+func TestUsersService_Import_Cancelled(t *testing.T) {
+	api := &usersImportAPI{}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	records := []UserRecord{{Username: "alice"}, {Username: "erin"}, {Username: "frank"}}
+	results, err := client.Users.Import(ctx, records, &UserImportOptions{Upsert: true})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(api.writes) != 0 {
+		t.Errorf("Expected no writes, got %v", api.writes)
+	}
+	if got := importActions(results); got != "unchanged,error,error" {
+		t.Errorf("Expected rows that never ran to be failed, got %s", got)
+	}
+	if !errors.Is(results[1].Err, context.Canceled) {
+		t.Errorf("Expected the row error to wrap context.Canceled, got %v", results[1].Err)
+	}
+}
+
+func TestUsersService_ImportFile_ResultFile(t *testing.T) {
+	api := &usersImportAPI{}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "users.json")
+	if err := os.WriteFile(input, []byte(`[{"username":"frank","group":"Engineering"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	resultPath := filepath.Join(dir, "results.csv")
+	if _, err := client.Users.ImportFile(context.Background(), input, resultPath, &UserImportOptions{DryRun: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	out, err := os.ReadFile(resultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "row,username,user_id,action,error\n1,frank,,create,\n" {
+		t.Errorf("Unexpected result file:\n%s", out)
+	}
+}
+
+func TestUsersService_Export(t *testing.T) {
+	api := &usersImportAPI{}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+
+	var buf bytes.Buffer
+	if err := client.Users.Export(&buf, UserFileCSV); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "username,email,first_name,last_name,role,group,secondary_groups\n" +
+		"alice,alice@example.com,Alice,Smith,ADMIN,Engineering,Sales\n" +
+		"bob,bob@old.example,,,MEMBER,Engineering,ug-gone\n"
+	if buf.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, buf.String())
+	}
+}
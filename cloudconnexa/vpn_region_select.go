@@ -0,0 +1,161 @@
+package cloudconnexa
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/country_centroids.csv
+var countryCentroidsCSV string
+
+var (
+	countryCentroidsOnce sync.Once
+	countryCentroids     map[string][2]float64
+)
+
+// CountryCentroid returns the approximate geographic center of the country with
+// the given ISO 3166-1 alpha-2 code from the embedded offline dataset.
+func CountryCentroid(code string) (latitude, longitude float64, ok bool) {
+	centroid, ok := loadCountryCentroids()[strings.ToUpper(code)]
+	return centroid[0], centroid[1], ok
+}
+
+// loadCountryCentroids parses the embedded dataset, which has one row for
+// every officially assigned ISO 3166-1 alpha-2 code, on first use.
+func loadCountryCentroids() map[string][2]float64 {
+	countryCentroidsOnce.Do(func() {
+		countryCentroids = map[string][2]float64{}
+		records, err := csv.NewReader(strings.NewReader(countryCentroidsCSV)).ReadAll()
+		if err != nil {
+			panic(fmt.Sprintf("cloudconnexa: invalid embedded country centroids: %v", err))
+		}
+		for _, record := range records[1:] {
+			lat, latErr := strconv.ParseFloat(record[1], 64)
+			lon, lonErr := strconv.ParseFloat(record[2], 64)
+			if latErr != nil || lonErr != nil {
+				panic(fmt.Sprintf("cloudconnexa: invalid embedded country centroid %v", record))
+			}
+			countryCentroids[record[0]] = [2]float64{lat, lon}
+		}
+	})
+	return countryCentroids
+}
+
+// VPNRegionSelection describes where a client is and which regions it prefers.
+type VPNRegionSelection struct {
+	// Country is the ISO 3166-1 alpha-2 code of the client's country. It is
+	// ignored if Latitude and Longitude are set.
+	Country string
+	// Latitude and Longitude locate the client in decimal degrees.
+	Latitude  *float64
+	Longitude *float64
+	// Preferred lists region IDs to rank ahead of all others, in order.
+	Preferred []string
+	// UserGroup, if set, restricts the selection to regions available to the group.
+	UserGroup *UserGroup
+}
+
+// RankedVPNRegion is a candidate region and its distance from the client.
+type RankedVPNRegion struct {
+	Region VpnRegion `json:"region"`
+	// DistanceKm is the great-circle distance between the client and the
+	// centroid of the region's country, or -1 if either location is unknown.
+	DistanceKm float64 `json:"distanceKm"`
+	Preferred  bool    `json:"preferred"`
+}
+
+// RankVPNRegions orders regions for a client: preferred regions in the given
+// order first, then the others by distance, nearest first. Regions whose
+// distance is unknown come last, ordered by ID. The result is the fallback
+// order to try when connecting.
+func RankVPNRegions(regions []VpnRegion, selection VPNRegionSelection) ([]RankedVPNRegion, error) {
+	var origin *[2]float64
+	switch {
+	case selection.Latitude != nil && selection.Longitude != nil:
+		origin = &[2]float64{*selection.Latitude, *selection.Longitude}
+	case selection.Country != "":
+		lat, lon, ok := CountryCentroid(selection.Country)
+		if !ok {
+			return nil, fmt.Errorf("unknown country code %q", selection.Country)
+		}
+		origin = &[2]float64{lat, lon}
+	}
+
+	ranked := make([]RankedVPNRegion, 0, len(regions))
+	for _, region := range regions {
+		if selection.UserGroup != nil && ValidateUserGroupRegion(*selection.UserGroup, region.ID) != nil {
+			continue
+		}
+		candidate := RankedVPNRegion{Region: region, DistanceKm: -1, Preferred: slices.Contains(selection.Preferred, region.ID)}
+		if lat, lon, ok := CountryCentroid(region.CountryISO); ok && origin != nil {
+			candidate.DistanceKm = haversineKm(origin[0], origin[1], lat, lon)
+		}
+		ranked = append(ranked, candidate)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Preferred != b.Preferred {
+			return a.Preferred
+		}
+		if a.Preferred {
+			return slices.Index(selection.Preferred, a.Region.ID) < slices.Index(selection.Preferred, b.Region.ID)
+		}
+		if (a.DistanceKm < 0) != (b.DistanceKm < 0) {
+			return b.DistanceKm < 0
+		}
+		if a.DistanceKm != b.DistanceKm {
+			return a.DistanceKm < b.DistanceKm
+		}
+		return a.Region.ID < b.Region.ID
+	})
+	return ranked, nil
+}
+
+// SelectVPNRegion returns the best ranked region, or ErrNoVPNRegion if none qualifies.
+func SelectVPNRegion(regions []VpnRegion, selection VPNRegionSelection) (*VpnRegion, error) {
+	ranked, err := RankVPNRegions(regions, selection)
+	if err != nil {
+		return nil, err
+	}
+	if len(ranked) == 0 {
+		return nil, ErrNoVPNRegion
+	}
+	return &ranked[0].Region, nil
+}
+
+// Select lists the VPN regions and returns the best one for selection.
+func (c *VPNRegionsService) Select(selection VPNRegionSelection) (*VpnRegion, error) {
+	regions, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	return SelectVPNRegion(regions, selection)
+}
+
+// ValidateUserGroupRegion returns ErrVPNRegionNotAllowed unless the user group
+// includes all regions or lists regionID in its VpnRegionIDs.
+func ValidateUserGroupRegion(group UserGroup, regionID string) error {
+	if group.AllRegionsIncluded || slices.Contains(group.VpnRegionIDs, regionID) {
+		return nil
+	}
+	return fmt.Errorf("%w: region %q, user group %q", ErrVPNRegionNotAllowed, regionID, group.Name)
+}
+
+// haversineKm returns the great-circle distance in kilometers between two points given in degrees.
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
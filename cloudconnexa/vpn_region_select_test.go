@@ -0,0 +1,94 @@
+package cloudconnexa
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+var testSelectRegions = []VpnRegion{
+	{ID: "us-east", CountryISO: "US"},
+	{ID: "eu-central", CountryISO: "DE"},
+	{ID: "eu-west", CountryISO: "GB"},
+	{ID: "ap-southeast", CountryISO: "SG"},
+	{ID: "unknown", CountryISO: ""},
+}
+
+func rankedIDs(ranked []RankedVPNRegion) string {
+	ids := make([]string, 0, len(ranked))
+	for _, r := range ranked {
+		ids = append(ids, r.Region.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestCountryCentroid(t *testing.T) {
+	if lat, lon, ok := CountryCentroid("de"); !ok || lat < 47 || lat > 55 || lon < 6 || lon > 15 {
+		t.Errorf("Expected a centroid in Germany, got %v, %v, %v", lat, lon, ok)
+	}
+	if _, _, ok := CountryCentroid("XX"); ok {
+		t.Error("Expected no centroid for XX")
+	}
+}
+
+func TestHaversineKm(t *testing.T) {
+	// London to Paris is about 344 km.
+	if d := haversineKm(51.5074, -0.1278, 48.8566, 2.3522); math.Abs(d-344) > 5 {
+		t.Errorf("Expected about 344 km, got %.1f", d)
+	}
+}
+
+func TestRankVPNRegions(t *testing.T) {
+	ranked, err := RankVPNRegions(testSelectRegions, VPNRegionSelection{Country: "fr"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := rankedIDs(ranked); got != "eu-central,eu-west,us-east,ap-southeast,unknown" {
+		t.Errorf("Unexpected order %s", got)
+	}
+	if ranked[4].DistanceKm != -1 {
+		t.Errorf("Expected unknown distance for region without country, got %v", ranked[4].DistanceKm)
+	}
+
+	lat, lon := 1.3, 103.8
+	ranked, err = RankVPNRegions(testSelectRegions, VPNRegionSelection{Latitude: &lat, Longitude: &lon, Preferred: []string{"us-east", "missing"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := rankedIDs(ranked); !strings.HasPrefix(got, "us-east,ap-southeast,") || !ranked[0].Preferred {
+		t.Errorf("Expected preferred region first, then nearest, got %s", got)
+	}
+
+	if _, err := RankVPNRegions(testSelectRegions, VPNRegionSelection{Country: "XX"}); err == nil {
+		t.Error("Expected an error for an unknown country")
+	}
+}
+
+func TestSelectVPNRegion_UserGroup(t *testing.T) {
+	group := &UserGroup{Name: "Engineering", VpnRegionIDs: []string{"us-east", "ap-southeast"}}
+	region, err := SelectVPNRegion(testSelectRegions, VPNRegionSelection{Country: "DE", UserGroup: group})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if region.ID != "us-east" {
+		t.Errorf("Expected nearest allowed region us-east, got %s", region.ID)
+	}
+
+	group.VpnRegionIDs = nil
+	if _, err := SelectVPNRegion(testSelectRegions, VPNRegionSelection{Country: "DE", UserGroup: group}); !errors.Is(err, ErrNoVPNRegion) {
+		t.Errorf("Expected ErrNoVPNRegion, got %v", err)
+	}
+}
+
+func TestValidateUserGroupRegion(t *testing.T) {
+	if err := ValidateUserGroupRegion(UserGroup{AllRegionsIncluded: true}, "eu-west"); err != nil {
+		t.Errorf("Expected all regions to be allowed, got %v", err)
+	}
+	if err := ValidateUserGroupRegion(UserGroup{VpnRegionIDs: []string{"eu-west"}}, "eu-west"); err != nil {
+		t.Errorf("Expected listed region to be allowed, got %v", err)
+	}
+	if err := ValidateUserGroupRegion(UserGroup{VpnRegionIDs: []string{"eu-west"}}, "us-east"); !errors.Is(err, ErrVPNRegionNotAllowed) {
+		t.Errorf("Expected ErrVPNRegionNotAllowed, got %v", err)
+	}
+}
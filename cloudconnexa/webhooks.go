@@ -0,0 +1,488 @@
+package cloudconnexa
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebhookEventType is the type of a change event delivered by a WebhookDispatcher.
+type WebhookEventType string
+
+const (
+	// WebhookConnectorOffline is emitted when a host or network connector stops being ONLINE.
+	WebhookConnectorOffline WebhookEventType = "connector.offline"
+	// WebhookConnectorOnline is emitted when a host or network connector comes back ONLINE.
+	WebhookConnectorOnline WebhookEventType = "connector.online"
+	// WebhookIPsecTunnelDown is emitted when the IPsec tunnel of a network connector stops being ACTIVE.
+	WebhookIPsecTunnelDown WebhookEventType = "ipsec.tunnel_down"
+	// WebhookIPsecTunnelUp is emitted when the IPsec tunnel of a network connector becomes ACTIVE.
+	WebhookIPsecTunnelUp WebhookEventType = "ipsec.tunnel_up"
+	// WebhookUserCreated is emitted for a new user.
+	WebhookUserCreated WebhookEventType = "user.created"
+	// WebhookUserSuspended is emitted when a user is suspended.
+	WebhookUserSuspended WebhookEventType = "user.suspended"
+	// WebhookUserActivated is emitted when a suspended user is activated.
+	WebhookUserActivated WebhookEventType = "user.activated"
+	// WebhookUserDeleted is emitted when a user disappears.
+	WebhookUserDeleted WebhookEventType = "user.deleted"
+	// WebhookDeviceRegistered is emitted for a new device.
+	WebhookDeviceRegistered WebhookEventType = "device.registered"
+	// WebhookDeviceRemoved is emitted when a device disappears.
+	WebhookDeviceRemoved WebhookEventType = "device.removed"
+	// WebhookSessionStarted is emitted for every new session.
+	WebhookSessionStarted WebhookEventType = "session.started"
+	// WebhookSessionFailed is emitted for every new failed session.
+	WebhookSessionFailed WebhookEventType = "session.failed"
+)
+
+// Webhook request headers.
+const (
+	WebhookIDHeader        = "X-CloudConnexa-Webhook-Id"
+	WebhookEventHeader     = "X-CloudConnexa-Event"
+	WebhookTimestampHeader = "X-CloudConnexa-Timestamp"
+	WebhookSignatureHeader = "X-CloudConnexa-Signature"
+)
+
+const (
+	defaultWebhookMaxAttempts = 5
+	defaultWebhookMinBackoff  = 1 * time.Second
+	defaultWebhookMaxBackoff  = 1 * time.Minute
+	defaultWebhookQueueSize   = 1024
+)
+
+// ErrInvalidWebhookSignature is returned by VerifyWebhookSignature for a
+// missing, stale or mismatched signature.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// ErrWebhookQueueFull is reported through OnError, and recorded in the
+// dead-letter file, for an event dropped because an endpoint's queue was full.
+var ErrWebhookQueueFull = errors.New("webhook queue full")
+
+// WebhookEvent is the JSON payload of a webhook.
+type WebhookEvent struct {
+	ID   string           `json:"id"`
+	Type WebhookEventType `json:"type"`
+	Time time.Time        `json:"time"`
+	// Resource is the kind of object the event is about: "host_connector",
+	// "network_connector", "user", "device" or "session".
+	Resource   string `json:"resource"`
+	ResourceID string `json:"resourceId"`
+	// Data is the object after the change, or the removed object.
+	Data any `json:"data"`
+	// Previous is the object before the change, for changes of existing objects.
+	Previous any `json:"previous,omitempty"`
+}
+
+// WebhookEndpoint is a destination of webhooks.
+type WebhookEndpoint struct {
+	URL string
+	// Secret is the HMAC-SHA256 key used to sign payloads.
+	Secret string
+	// Events restricts the endpoint to these event types. Empty means all.
+	Events []WebhookEventType
+}
+
+func (e WebhookEndpoint) wants(t WebhookEventType) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, t)
+}
+
+// WebhookDeadLetter is a line of the dead-letter file: an event that could
+// not be delivered to an endpoint.
+type WebhookDeadLetter struct {
+	Time     time.Time    `json:"time"`
+	Endpoint string       `json:"endpoint"`
+	Attempts int          `json:"attempts"`
+	Error    string       `json:"error"`
+	Event    WebhookEvent `json:"event"`
+}
+
+// WebhookDispatcherOptions configures a WebhookDispatcher.
+type WebhookDispatcherOptions struct {
+	Endpoints []WebhookEndpoint
+	// Interval is the wait between polls of connectors, users and devices. Defaults to 30s.
+	Interval time.Duration
+	// WatchSessions enables session events, read with SessionsService.Watch
+	// configured by Sessions.
+	WatchSessions bool
+	Sessions      SessionWatchOptions
+	// MaxAttempts is the number of delivery attempts per endpoint. Defaults to 5.
+	MaxAttempts int
+	// MinBackoff and MaxBackoff bound the exponential backoff between attempts.
+	// They default to 1s and 1m. A MaxBackoff below MinBackoff is raised to MinBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// QueueSize is the number of events buffered per endpoint. Events for an
+	// endpoint whose queue is full are dead-lettered instead of holding up
+	// the other endpoints. Defaults to 1024.
+	QueueSize int
+	// DeadLetterPath is a JSON Lines file receiving a WebhookDeadLetter for
+	// every event that could not be delivered. Optional.
+	DeadLetterPath string
+	// HTTPClient sends the webhooks. Defaults to a client with a 10s timeout.
+	HTTPClient *http.Client
+	// OnError is called with polling and delivery errors. The dispatcher keeps running.
+	OnError func(error)
+}
+
+// WebhookDispatcher polls CloudConnexa for changes, which the API does not
+// push, and posts them as signed JSON webhooks. Only resources that some
+// endpoint subscribes to are polled. Objects present at the first successful
+// poll of a resource do not produce events.
+type WebhookDispatcher struct {
+	client *Client
+	opts   WebhookDispatcherOptions
+	now    func() time.Time
+
+	deadMu sync.Mutex
+}
+
+// NewWebhookDispatcher creates a dispatcher polling through client.
+func NewWebhookDispatcher(client *Client, opts WebhookDispatcherOptions) *WebhookDispatcher {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInformerInterval
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultWebhookMaxAttempts
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultWebhookMinBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = max(defaultWebhookMaxBackoff, opts.MinBackoff)
+	}
+	opts.MaxBackoff = max(opts.MaxBackoff, opts.MinBackoff)
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultWebhookQueueSize
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookDispatcher{client: client, opts: opts, now: time.Now}
+}
+
+// webhookSource is a polled resource whose first successful sync primes the mirror.
+type webhookSource struct {
+	sync   func() error
+	primed bool
+}
+
+// Run polls and delivers events until ctx is cancelled, then returns
+// ctx.Err(). Events still queued or being retried at that point are written
+// to the dead-letter file.
+func (d *WebhookDispatcher) Run(ctx context.Context) error {
+	queues := make([]chan WebhookEvent, len(d.opts.Endpoints))
+	var workers sync.WaitGroup
+	for i, endpoint := range d.opts.Endpoints {
+		queues[i] = make(chan WebhookEvent, d.opts.QueueSize)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for event := range queues[i] {
+				d.deliver(ctx, endpoint, event)
+			}
+		}()
+	}
+	publish := func(event WebhookEvent) {
+		for i, endpoint := range d.opts.Endpoints {
+			if !endpoint.wants(event.Type) {
+				continue
+			}
+			select {
+			case queues[i] <- event:
+			default:
+				err := fmt.Errorf("webhook %s to %s: %w", event.ID, endpoint.URL, ErrWebhookQueueFull)
+				d.reportErr(err)
+				d.deadLetter(endpoint, event, 0, err)
+			}
+		}
+	}
+
+	var producers sync.WaitGroup
+	if d.opts.WatchSessions && d.wants("session.") {
+		events, err := d.client.WithoutCache().Sessions.Watch(ctx, d.opts.Sessions)
+		if err != nil {
+			d.reportErr(err)
+		} else {
+			producers.Add(1)
+			go func() {
+				defer producers.Done()
+				for e := range events {
+					publish(d.sessionEvent(e.Session))
+				}
+			}()
+		}
+	}
+
+	sources := d.sources(publish)
+	for {
+		for _, src := range sources {
+			if err := src.sync(); err != nil {
+				d.reportErr(err)
+				continue
+			}
+			src.primed = true
+		}
+		if !sleepContext(ctx, d.opts.Interval) {
+			break
+		}
+	}
+
+	producers.Wait()
+	for _, q := range queues {
+		close(q)
+	}
+	workers.Wait()
+	return ctx.Err()
+}
+
+func (d *WebhookDispatcher) wants(prefix string) bool {
+	for _, endpoint := range d.opts.Endpoints {
+		if len(endpoint.Events) == 0 || slices.ContainsFunc(endpoint.Events, func(t WebhookEventType) bool {
+			return strings.HasPrefix(string(t), prefix)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// sources returns the polled resources some endpoint subscribes to.
+func (d *WebhookDispatcher) sources(publish func(WebhookEvent)) []*webhookSource {
+	uncached := d.client.WithoutCache()
+	var sources []*webhookSource
+
+	if d.wants("connector.") {
+		hosts := uncached.HostConnectors.Informer(InformerOptions{})
+		sources = append(sources, watchSource(hosts, func(src *webhookSource) ResourceEventHandler[HostConnector] {
+			return ResourceEventHandler[HostConnector]{OnUpdate: func(old, c HostConnector) {
+				if t, ok := connectorStatusEvent(old.ConnectionStatus, c.ConnectionStatus); ok {
+					publish(d.event(t, "host_connector", c.ID, c, old))
+				}
+			}}
+		}))
+	}
+	if d.wants("connector.") || d.wants("ipsec.") {
+		networks := uncached.NetworkConnectors.Informer(InformerOptions{})
+		sources = append(sources, watchSource(networks, func(src *webhookSource) ResourceEventHandler[NetworkConnector] {
+			return ResourceEventHandler[NetworkConnector]{OnUpdate: func(old, c NetworkConnector) {
+				if t, ok := connectorStatusEvent(old.ConnectionStatus, c.ConnectionStatus); ok {
+					publish(d.event(t, "network_connector", c.ID, c, old))
+				}
+				if t, ok := ipsecStateEvent(old.IPSecConfig, c.IPSecConfig); ok {
+					publish(d.event(t, "network_connector", c.ID, c, old))
+				}
+			}}
+		}))
+	}
+	if d.wants("user.") {
+		users := uncached.Users.Informer(InformerOptions{})
+		sources = append(sources, watchSource(users, func(src *webhookSource) ResourceEventHandler[User] {
+			return ResourceEventHandler[User]{
+				OnAdd: func(u User) {
+					if src.primed {
+						publish(d.event(WebhookUserCreated, "user", u.ID, u, nil))
+					}
+				},
+				OnUpdate: func(old, u User) {
+					switch {
+					case old.Status != UserStatusSuspended && u.Status == UserStatusSuspended:
+						publish(d.event(WebhookUserSuspended, "user", u.ID, u, old))
+					case old.Status == UserStatusSuspended && u.Status == UserStatusActive:
+						publish(d.event(WebhookUserActivated, "user", u.ID, u, old))
+					}
+				},
+				OnDelete: func(u User) { publish(d.event(WebhookUserDeleted, "user", u.ID, u, nil)) },
+			}
+		}))
+	}
+	if d.wants("device.") {
+		devices := NewInformer(uncached.Devices.ListAll, func(dev DeviceDetail) string { return dev.ID }, InformerOptions{})
+		sources = append(sources, watchSource(devices, func(src *webhookSource) ResourceEventHandler[DeviceDetail] {
+			return ResourceEventHandler[DeviceDetail]{
+				OnAdd: func(dev DeviceDetail) {
+					if src.primed {
+						publish(d.event(WebhookDeviceRegistered, "device", dev.ID, dev, nil))
+					}
+				},
+				OnDelete: func(dev DeviceDetail) { publish(d.event(WebhookDeviceRemoved, "device", dev.ID, dev, nil)) },
+			}
+		}))
+	}
+	return sources
+}
+
+// watchSource registers the handler built by handler on inf and returns a
+// source syncing inf.
+func watchSource[T any](inf *Informer[T], handler func(*webhookSource) ResourceEventHandler[T]) *webhookSource {
+	src := &webhookSource{sync: inf.Sync}
+	inf.AddEventHandler(handler(src))
+	return src
+}
+
+func connectorStatusEvent(oldStatus, newStatus string) (WebhookEventType, bool) {
+	wasOnline, isOnline := strings.EqualFold(oldStatus, "ONLINE"), strings.EqualFold(newStatus, "ONLINE")
+	switch {
+	case wasOnline && !isOnline:
+		return WebhookConnectorOffline, true
+	case !wasOnline && isOnline:
+		return WebhookConnectorOnline, true
+	}
+	return "", false
+}
+
+func ipsecStateEvent(oldConfig, newConfig *IPSecConfig) (WebhookEventType, bool) {
+	active := func(c *IPSecConfig) bool { return c != nil && strings.EqualFold(c.ConnectorState, "ACTIVE") }
+	switch {
+	case active(oldConfig) && !active(newConfig):
+		return WebhookIPsecTunnelDown, true
+	case !active(oldConfig) && active(newConfig):
+		return WebhookIPsecTunnelUp, true
+	}
+	return "", false
+}
+
+func (d *WebhookDispatcher) sessionEvent(s Session) WebhookEvent {
+	t := WebhookSessionStarted
+	if SessionStatus(s.ConnectionStatus) == SessionStatusFailed {
+		t = WebhookSessionFailed
+	}
+	return d.event(t, "session", s.SessionID, s, nil)
+}
+
+func (d *WebhookDispatcher) event(t WebhookEventType, resource, id string, data, previous any) WebhookEvent {
+	var raw [16]byte
+	_, _ = rand.Read(raw[:])
+	return WebhookEvent{ID: hex.EncodeToString(raw[:]), Type: t, Time: d.now().UTC(), Resource: resource, ResourceID: id, Data: data, Previous: previous}
+}
+
+func (d *WebhookDispatcher) reportErr(err error) {
+	if d.opts.OnError != nil {
+		d.opts.OnError(err)
+	}
+}
+
+// deliver posts event to endpoint, retrying with exponential backoff, and
+// dead-letters it once the attempts are exhausted or the failure is permanent.
+func (d *WebhookDispatcher) deliver(ctx context.Context, endpoint WebhookEndpoint, event WebhookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.deadLetter(endpoint, event, 0, err)
+		return
+	}
+	backoff := d.opts.MinBackoff
+	for attempt := 1; ; attempt++ {
+		if ctx.Err() != nil {
+			d.deadLetter(endpoint, event, attempt-1, ctx.Err())
+			return
+		}
+		retry, err := d.post(ctx, endpoint, event, body)
+		if err == nil {
+			return
+		}
+		d.reportErr(fmt.Errorf("webhook %s to %s: %w", event.ID, endpoint.URL, err))
+		if !retry || attempt >= d.opts.MaxAttempts {
+			d.deadLetter(endpoint, event, attempt, err)
+			return
+		}
+		if !sleepContext(ctx, backoff) {
+			d.deadLetter(endpoint, event, attempt, ctx.Err())
+			return
+		}
+		backoff = min(backoff*2, d.opts.MaxBackoff)
+	}
+}
+
+// post sends one delivery attempt and reports whether a failure is worth retrying.
+func (d *WebhookDispatcher) post(ctx context.Context, endpoint WebhookEndpoint, event WebhookEvent, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(WebhookIDHeader, event.ID)
+	req.Header.Set(WebhookEventHeader, string(event.Type))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(endpoint.Secret, timestamp, body))
+
+	res, err := d.opts.HTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	_ = res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	retry := res.StatusCode >= 500 || res.StatusCode == http.StatusRequestTimeout || res.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status %d", res.StatusCode)
+}
+
+func (d *WebhookDispatcher) deadLetter(endpoint WebhookEndpoint, event WebhookEvent, attempts int, cause error) {
+	if d.opts.DeadLetterPath == "" {
+		return
+	}
+	line, err := json.Marshal(WebhookDeadLetter{Time: d.now().UTC(), Endpoint: endpoint.URL, Attempts: attempts, Error: cause.Error(), Event: event})
+	if err != nil {
+		d.reportErr(err)
+		return
+	}
+	d.deadMu.Lock()
+	defer d.deadMu.Unlock()
+	f, err := os.OpenFile(d.opts.DeadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		d.reportErr(err)
+		return
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		d.reportErr(err)
+	}
+	if err := f.Close(); err != nil {
+		d.reportErr(err)
+	}
+}
+
+// SignWebhook returns the signature header value of a webhook body sent at
+// timestamp: "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>".
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the signature headers of a received webhook.
+// Webhooks whose timestamp differs from now by more than tolerance are
+// rejected to prevent replays; a zero tolerance disables that check.
+func VerifyWebhookSignature(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp", ErrInvalidWebhookSignature)
+	}
+	if tolerance > 0 {
+		if age := time.Since(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+			return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidWebhookSignature)
+		}
+	}
+	expected := SignWebhook(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(WebhookSignatureHeader))) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidWebhookSignature)
+	}
+	return nil
+}
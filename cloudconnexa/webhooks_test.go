@@ -0,0 +1,262 @@
+package cloudconnexa
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	now := time.Now().Unix()
+	header := http.Header{}
+	header.Set(WebhookTimestampHeader, strconv.FormatInt(now, 10))
+	header.Set(WebhookSignatureHeader, SignWebhook("secret", now, body))
+
+	if err := VerifyWebhookSignature("secret", header, body, time.Minute); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+	if err := VerifyWebhookSignature("other", header, body, time.Minute); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("Expected a mismatch, got %v", err)
+	}
+	if err := VerifyWebhookSignature("secret", header, []byte(`{"id":"2"}`), time.Minute); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("Expected a tampered body to be rejected, got %v", err)
+	}
+	old := now - 3600
+	header.Set(WebhookTimestampHeader, strconv.FormatInt(old, 10))
+	header.Set(WebhookSignatureHeader, SignWebhook("secret", old, body))
+	if err := VerifyWebhookSignature("secret", header, body, time.Minute); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("Expected a stale timestamp to be rejected, got %v", err)
+	}
+}
+
+// webhookAPI serves connectors, users and devices; the state changes after the first poll.
+type webhookAPI struct {
+	mu    sync.Mutex
+	polls map[string]int
+}
+
+func (a *webhookAPI) server() *httptest.Server {
+	a.polls = map[string]int{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		a.polls[r.URL.Path]++
+		changed := a.polls[r.URL.Path] > 1
+		a.mu.Unlock()
+
+		switch r.URL.Path {
+		case "/api/v1/hosts/connectors":
+			status := "ONLINE"
+			if changed {
+				status = "OFFLINE"
+			}
+			_ = json.NewEncoder(w).Encode(HostConnectorPageResponse{Content: []HostConnector{{ID: "hc-1", ConnectionStatus: status}}, TotalPages: 1})
+		case "/api/v1/users":
+			users := []User{{ID: "u-1", Username: "alice", Status: UserStatusActive}}
+			if changed {
+				users = []User{{ID: "u-1", Username: "alice", Status: UserStatusSuspended}, {ID: "u-2", Username: "bob", Status: UserStatusActive}}
+			}
+			_ = json.NewEncoder(w).Encode(UserPageResponse{Content: users, TotalPages: 1})
+		default:
+			_ = json.NewEncoder(w).Encode(HostConnectorPageResponse{TotalPages: 1})
+		}
+	}))
+}
+
+func TestWebhookDispatcher_Run(t *testing.T) {
+	api := &webhookAPI{}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+
+	var mu sync.Mutex
+	var received []string
+	attempts := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if err := VerifyWebhookSignature("s3cret", r.Header, body, time.Minute); err != nil {
+			t.Errorf("Unexpected signature error %v", err)
+		}
+		var event WebhookEvent
+		_ = json.Unmarshal(body, &event)
+		received = append(received, string(event.Type)+" "+event.ResourceID)
+	}))
+	defer receiver.Close()
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	deadLetters := filepath.Join(t.TempDir(), "dead.jsonl")
+	d := NewWebhookDispatcher(client, WebhookDispatcherOptions{
+		Endpoints: []WebhookEndpoint{
+			{URL: receiver.URL, Secret: "s3cret", Events: []WebhookEventType{WebhookConnectorOffline, WebhookUserSuspended}},
+			{URL: rejecting.URL, Events: []WebhookEventType{WebhookUserCreated}},
+		},
+		Interval:       5 * time.Millisecond,
+		MinBackoff:     time.Millisecond,
+		DeadLetterPath: deadLetters,
+		OnError:        func(error) {},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(received)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 || received[0] == received[1] {
+		t.Fatalf("Expected connector.offline and user.suspended once each, got %v", received)
+	}
+	for _, event := range received {
+		if event != "connector.offline hc-1" && event != "user.suspended u-1" {
+			t.Errorf("Unexpected event %s", event)
+		}
+	}
+
+	f, err := os.Open(deadLetters)
+	if err != nil {
+		t.Fatalf("Expected a dead-letter file, got %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		t.Fatal("Expected a dead letter")
+	}
+	var letter WebhookDeadLetter
+	if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if letter.Endpoint != rejecting.URL || letter.Event.Type != WebhookUserCreated || letter.Attempts != 1 {
+		t.Errorf("Expected a permanent failure after one attempt, got %+v", letter)
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.polls["/api/v1/devices"] != 0 {
+		t.Error("Expected devices not to be polled without subscribers")
+	}
+}
+
+func TestWebhookDispatcher_Run_StalledEndpoint(t *testing.T) {
+	api := &webhookAPI{}
+	server := api.server()
+	defer server.Close()
+	client := createTestClientWithServices(server)
+
+	release := make(chan struct{})
+	stalled := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer stalled.Close()
+	defer close(release)
+	var mu sync.Mutex
+	received := 0
+	healthy := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		mu.Lock()
+		received++
+		mu.Unlock()
+	}))
+	defer healthy.Close()
+
+	var errs []error
+	deadLetters := filepath.Join(t.TempDir(), "dead.jsonl")
+	d := NewWebhookDispatcher(client, WebhookDispatcherOptions{
+		Endpoints:      []WebhookEndpoint{{URL: stalled.URL}, {URL: healthy.URL}},
+		Interval:       5 * time.Millisecond,
+		QueueSize:      1,
+		DeadLetterPath: deadLetters,
+		OnError: func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		},
+	})
+
+	// The second poll yields three events: the stalled endpoint blocks on the
+	// first and queues at most one more, so at least one has to be dropped.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := received
+		mu.Unlock()
+		if n >= 3 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	if received != 3 {
+		t.Errorf("Expected the healthy endpoint to receive 3 events, got %d", received)
+	}
+	full := 0
+	for _, err := range errs {
+		if errors.Is(err, ErrWebhookQueueFull) {
+			full++
+		}
+	}
+	mu.Unlock()
+	if full == 0 {
+		t.Errorf("Expected ErrWebhookQueueFull, got %v", errs)
+	}
+	cancel()
+	<-done
+
+	data, err := os.ReadFile(deadLetters)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var letter WebhookDeadLetter
+	if err := json.Unmarshal(bytes.SplitN(data, []byte("\n"), 2)[0], &letter); err != nil {
+		t.Fatalf("Invalid dead letter: %v", err)
+	}
+	if letter.Endpoint != stalled.URL || letter.Attempts != 0 {
+		t.Errorf("Expected the dropped event to be dead-lettered first, got %+v", letter)
+	}
+}
+
+func TestNewWebhookDispatcher_Backoff(t *testing.T) {
+	d := NewWebhookDispatcher(nil, WebhookDispatcherOptions{MinBackoff: 10 * time.Second, MaxBackoff: 2 * time.Second})
+	if d.opts.MaxBackoff != 10*time.Second {
+		t.Errorf("Expected MaxBackoff to be raised to MinBackoff, got %v", d.opts.MaxBackoff)
+	}
+	d = NewWebhookDispatcher(nil, WebhookDispatcherOptions{})
+	if d.opts.MinBackoff != defaultWebhookMinBackoff || d.opts.MaxBackoff != defaultWebhookMaxBackoff {
+		t.Errorf("Expected default backoff, got %v/%v", d.opts.MinBackoff, d.opts.MaxBackoff)
+	}
+}
@@ -0,0 +1,60 @@
+package cloudconnexa
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultConcurrency is the number of workers used by bulk helpers when the
+// caller does not specify one. Requests are still serialized by the client's
+// rate limiters, so a small pool is enough to keep them saturated.
+const defaultConcurrency = 4
+
+// forEachConcurrent calls fn for every index in [0, n) using at most limit
+// goroutines. Indexes not yet started when ctx is cancelled are skipped.
+// It returns ctx.Err() if the context was cancelled before all work started.
+func forEachConcurrent(ctx context.Context, n int, limit int, fn func(i int)) error {
+	if limit <= 0 {
+		limit = defaultConcurrency
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < limit && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		case indexes <- i:
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return err
+}
+
+// sleepContext waits for d or until ctx is done, whichever happens first.
+// It reports whether the full duration elapsed.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
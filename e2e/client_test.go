@@ -305,7 +305,7 @@ func TestCreateNetwork(t *testing.T) {
 		Name:              testName,
 		InternetAccess:    cloudconnexa.InternetAccessSplitTunnelOn,
 		Connectors:        []cloudconnexa.NetworkConnector{connector},
-		TunnelingProtocol: "OPENVPN",
+		TunnelingProtocol: cloudconnexa.TunnelingProtocolOpenVPN,
 	}
 
 	// Create network with 429 retry/backoff
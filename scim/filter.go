@@ -0,0 +1,359 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// errInvalidFilter is returned for filters and paths the server cannot parse.
+var errInvalidFilter = errors.New("invalid filter")
+
+// filterExpr is a parsed SCIM filter evaluated against a resource decoded into generic JSON values.
+type filterExpr interface {
+	match(resource map[string]any) bool
+}
+
+type logicalExpr struct {
+	and         bool
+	left, right filterExpr
+}
+
+func (e logicalExpr) match(r map[string]any) bool {
+	if e.and {
+		return e.left.match(r) && e.right.match(r)
+	}
+	return e.left.match(r) || e.right.match(r)
+}
+
+type notExpr struct {
+	expr filterExpr
+}
+
+func (e notExpr) match(r map[string]any) bool {
+	return !e.expr.match(r)
+}
+
+// valuePathExpr matches if any element of a multi-valued attribute matches the inner filter.
+type valuePathExpr struct {
+	path   string
+	filter filterExpr
+}
+
+func (e valuePathExpr) match(r map[string]any) bool {
+	for _, v := range lookup(r, e.path) {
+		if m, ok := v.(map[string]any); ok && e.filter.match(m) {
+			return true
+		}
+	}
+	return false
+}
+
+type compareExpr struct {
+	path    string
+	op      string
+	operand any
+}
+
+func (e compareExpr) match(r map[string]any) bool {
+	values := lookup(r, e.path)
+	switch e.op {
+	case "pr":
+		for _, v := range values {
+			if v != nil && v != "" {
+				return true
+			}
+		}
+		return false
+	case "ne":
+		return !(compareExpr{path: e.path, op: "eq", operand: e.operand}).match(r)
+	}
+	if e.operand == nil {
+		return e.op == "eq" && len(values) == 0
+	}
+	for _, v := range values {
+		if compareValue(v, e.op, e.operand) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareValue(v any, op string, operand any) bool {
+	switch want := operand.(type) {
+	case string:
+		got, ok := v.(string)
+		if !ok {
+			return false
+		}
+		got, want = strings.ToLower(got), strings.ToLower(want)
+		switch op {
+		case "eq":
+			return got == want
+		case "co":
+			return strings.Contains(got, want)
+		case "sw":
+			return strings.HasPrefix(got, want)
+		case "ew":
+			return strings.HasSuffix(got, want)
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	case bool:
+		got, ok := v.(bool)
+		return ok && op == "eq" && got == want
+	case float64:
+		got, ok := v.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return got == want
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	}
+	return false
+}
+
+// lookup returns the values at a dotted attribute path, flattening multi-valued attributes.
+// Attribute names are case-insensitive and may be prefixed with a schema URN.
+func lookup(r map[string]any, path string) []any {
+	if strings.HasPrefix(strings.ToLower(path), "urn:") {
+		path = path[strings.LastIndex(path, ":")+1:]
+	}
+	values := []any{r}
+	for _, name := range strings.Split(path, ".") {
+		var next []any
+		for _, v := range values {
+			m, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			for key, child := range m {
+				if !strings.EqualFold(key, name) {
+					continue
+				}
+				if list, ok := child.([]any); ok {
+					next = append(next, list...)
+				} else if child != nil {
+					next = append(next, child)
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// parseFilter parses a filter expression as defined in RFC 7644 section 3.4.2.2.
+func parseFilter(filter string) (filterExpr, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", errInvalidFilter, p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+// parsePatchPath parses a PATCH path such as `members[value eq "id"]` or
+// `emails[type eq "work"].value` into its attribute, optional value filter and sub-attribute.
+func parsePatchPath(path string) (attr string, filter filterExpr, sub string, err error) {
+	open := strings.Index(path, "[")
+	if open < 0 {
+		attr, sub, _ = strings.Cut(path, ".")
+		return attr, nil, sub, nil
+	}
+	end := strings.LastIndex(path, "]")
+	if end < open {
+		return "", nil, "", fmt.Errorf("%w: unbalanced brackets in path %q", errInvalidFilter, path)
+	}
+	if filter, err = parseFilter(path[open+1 : end]); err != nil {
+		return "", nil, "", err
+	}
+	return path[:open], filter, strings.TrimPrefix(path[end+1:], "."), nil
+}
+
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+func tokenizeFilter(s string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			tokens = append(tokens, filterToken{text: string(c)})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("%w: unterminated string", errInvalidFilter)
+			}
+			var text string
+			if err := json.Unmarshal([]byte(s[i:end+1]), &text); err != nil {
+				return nil, fmt.Errorf("%w: %v", errInvalidFilter, err)
+			}
+			tokens = append(tokens, filterToken{text: text, quoted: true})
+			i = end + 1
+		default:
+			end := i
+			for end < len(s) && !strings.ContainsRune(" \t()[]\"", rune(s[end])) {
+				end++
+			}
+			tokens = append(tokens, filterToken{text: s[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+func (p *filterParser) expect(text string) error {
+	if !p.peekKeyword(text) {
+		return fmt.Errorf("%w: expected %q", errInvalidFilter, text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.peekKeyword("or") {
+		p.pos++
+		var right filterExpr
+		if right, err = p.parseAnd(); err == nil {
+			left = logicalExpr{left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.peekKeyword("and") {
+		p.pos++
+		var right filterExpr
+		if right, err = p.parseUnary(); err == nil {
+			left = logicalExpr{and: true, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		expr, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr: expr}, nil
+	}
+	if p.peekKeyword("(") {
+		return p.parseGroup()
+	}
+	return p.parseAttrExpr()
+}
+
+func (p *filterParser) parseGroup() (filterExpr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	return expr, p.expect(")")
+}
+
+func (p *filterParser) parseAttrExpr() (filterExpr, error) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].quoted {
+		return nil, fmt.Errorf("%w: expected attribute path", errInvalidFilter)
+	}
+	path := p.tokens[p.pos].text
+	p.pos++
+
+	if p.peekKeyword("[") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return valuePathExpr{path: path, filter: inner}, p.expect("]")
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("%w: expected operator after %q", errInvalidFilter, path)
+	}
+	op := strings.ToLower(p.tokens[p.pos].text)
+	p.pos++
+	switch op {
+	case "pr":
+		return compareExpr{path: path, op: op}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %q", errInvalidFilter, op)
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("%w: expected value after %q", errInvalidFilter, op)
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	if token.quoted {
+		return compareExpr{path: path, op: op, operand: token.text}, nil
+	}
+	switch strings.ToLower(token.text) {
+	case "true":
+		return compareExpr{path: path, op: op, operand: true}, nil
+	case "false":
+		return compareExpr{path: path, op: op, operand: false}, nil
+	case "null":
+		return compareExpr{path: path, op: op}, nil
+	}
+	number, err := strconv.ParseFloat(token.text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid value %q", errInvalidFilter, token.text)
+	}
+	return compareExpr{path: path, op: op, operand: number}, nil
+}
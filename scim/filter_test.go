@@ -0,0 +1,65 @@
+package scim
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	resource := map[string]any{
+		"userName": "alice@example.com",
+		"active":   true,
+		"name":     map[string]any{"givenName": "Alice"},
+		"emails": []any{
+			map[string]any{"type": "work", "value": "alice@example.com"},
+			map[string]any{"type": "home", "value": "alice@home.example"},
+		},
+	}
+	tests := []struct {
+		filter string
+		match  bool
+	}{
+		{`userName eq "Alice@Example.com"`, true},
+		{`userName sw "bob"`, false},
+		{`name.givenName co "lic"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName ew ".com"`, true},
+		{`active eq true and emails pr`, true},
+		{`active eq false or userName eq "alice@example.com"`, true},
+		{`not (active eq true)`, false},
+		{`emails[type eq "home" and value co "home"]`, true},
+		{`emails[type eq "other"]`, false},
+		{`emails.value eq "alice@home.example"`, true},
+		{`externalId pr`, false},
+		{`externalId eq null`, true},
+		{`userName ne "bob"`, true},
+	}
+	for _, tt := range tests {
+		expr, err := parseFilter(tt.filter)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.filter, err)
+			continue
+		}
+		if got := expr.match(resource); got != tt.match {
+			t.Errorf("%s: expected %v, got %v", tt.filter, tt.match, got)
+		}
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	for _, filter := range []string{`userName`, `userName eq`, `userName eq "a`, `(userName eq "a"`, `userName eq "a" extra`, `userName eq bare`} {
+		if _, err := parseFilter(filter); !errors.Is(err, errInvalidFilter) {
+			t.Errorf("%s: expected errInvalidFilter, got %v", filter, err)
+		}
+	}
+}
+
+func TestParsePatchPath(t *testing.T) {
+	attr, filter, sub, err := parsePatchPath(`emails[type eq "work"].value`)
+	if err != nil || attr != "emails" || filter == nil || sub != "value" {
+		t.Errorf("Unexpected result %q %v %q %v", attr, filter, sub, err)
+	}
+	attr, filter, sub, err = parsePatchPath("name.givenName")
+	if err != nil || attr != "name" || filter != nil || sub != "givenName" {
+		t.Errorf("Unexpected result %q %v %q %v", attr, filter, sub, err)
+	}
+}
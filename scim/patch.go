@@ -0,0 +1,192 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// applyPatch applies the operations of patch to resource and decodes the result into out.
+// Operations work on the JSON form of the resource, so every attribute can be
+// patched; attributes that do not map to CloudConnexa fields are ignored when saving.
+func applyPatch(resource any, patch PatchRequest, out any) error {
+	m, err := toMap(resource)
+	if err != nil {
+		return err
+	}
+	for _, op := range patch.Operations {
+		var value any
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return &apiError{status: http.StatusBadRequest, scimType: "invalidSyntax", detail: err.Error()}
+			}
+		}
+		if err := patchOperation(m, strings.ToLower(op.Op), op.Path, value); err != nil {
+			return err
+		}
+	}
+	normalizeBool(m, "active")
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return invalidValue("%v", err)
+	}
+	return nil
+}
+
+func patchOperation(m map[string]any, op, path string, value any) error {
+	switch op {
+	case "add", "replace", "remove":
+	default:
+		return invalidValue("unsupported patch operation %q", op)
+	}
+	if path == "" {
+		if op == "remove" {
+			return &apiError{status: http.StatusBadRequest, scimType: "noTarget", detail: "remove requires a path"}
+		}
+		values, ok := value.(map[string]any)
+		if !ok {
+			return invalidValue("patch operation without path requires an object value")
+		}
+		for key, v := range values {
+			if err := patchOperation(m, op, key, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(strings.ToLower(path), "urn:") {
+		path = path[strings.LastIndex(path, ":")+1:]
+	}
+	attr, filter, sub, err := parsePatchPath(path)
+	if err != nil {
+		return err
+	}
+	key := findKey(m, attr)
+
+	if filter == nil {
+		if sub != "" {
+			child, _ := m[key].(map[string]any)
+			if child == nil {
+				child = map[string]any{}
+			}
+			if err := patchOperation(child, op, sub, value); err != nil {
+				return err
+			}
+			m[key] = child
+			return nil
+		}
+		list, isList := m[key].([]any)
+		switch {
+		case op == "remove" && isList && value != nil:
+			m[key] = removeValues(list, value)
+		case op == "remove":
+			delete(m, key)
+		case op == "add" && isList:
+			m[key] = append(list, asList(value)...)
+		default:
+			m[key] = value
+		}
+		return nil
+	}
+
+	list, _ := m[key].([]any)
+	var kept []any
+	matched := false
+	for _, element := range list {
+		em, ok := element.(map[string]any)
+		if !ok || !filter.match(em) {
+			kept = append(kept, element)
+			continue
+		}
+		matched = true
+		switch {
+		case op == "remove" && sub == "":
+			continue
+		case op == "remove":
+			delete(em, findKey(em, sub))
+		case sub == "":
+			if values, ok := value.(map[string]any); ok {
+				for k, v := range values {
+					em[findKey(em, k)] = v
+				}
+			}
+		default:
+			em[findKey(em, sub)] = value
+		}
+		kept = append(kept, em)
+	}
+	if !matched && op != "remove" {
+		// Create the element selected by a simple equality filter, e.g. emails[type eq "work"].value.
+		eq, ok := filter.(compareExpr)
+		if !ok || eq.op != "eq" || strings.Contains(eq.path, ".") {
+			return &apiError{status: http.StatusBadRequest, scimType: "noTarget", detail: fmt.Sprintf("path %q matches no value", path)}
+		}
+		element := map[string]any{eq.path: eq.operand}
+		if sub != "" {
+			element[sub] = value
+		} else if values, ok := value.(map[string]any); ok {
+			for k, v := range values {
+				element[k] = v
+			}
+		}
+		kept = append(kept, element)
+	}
+	m[key] = kept
+	return nil
+}
+
+// findKey returns the key of m matching name case-insensitively, or name.
+func findKey(m map[string]any, name string) string {
+	for key := range m {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+func asList(value any) []any {
+	if list, ok := value.([]any); ok {
+		return list
+	}
+	return []any{value}
+}
+
+// removeValues removes the elements of list whose value attribute matches one of values.
+func removeValues(list []any, values any) []any {
+	remove := map[string]bool{}
+	for _, v := range asList(values) {
+		if vm, ok := v.(map[string]any); ok {
+			if s, ok := vm[findKey(vm, "value")].(string); ok {
+				remove[s] = true
+			}
+		}
+	}
+	var kept []any
+	for _, element := range list {
+		if em, ok := element.(map[string]any); ok {
+			if s, ok := em[findKey(em, "value")].(string); ok && remove[s] {
+				continue
+			}
+		}
+		kept = append(kept, element)
+	}
+	return kept
+}
+
+// normalizeBool converts string values such as "False", sent by some identity providers, to booleans.
+func normalizeBool(m map[string]any, name string) {
+	key := findKey(m, name)
+	if s, ok := m[key].(string); ok {
+		if b, err := strconv.ParseBool(s); err == nil {
+			m[key] = b
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package scim exposes CloudConnexa users and user groups through a SCIM 2.0
+// (RFC 7643, RFC 7644) HTTP server, so that identity providers can provision
+// them directly.
+package scim
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+)
+
+// SCIM schema URNs used by the server.
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// Meta holds resource metadata.
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+
+// Name is the components of a user's name.
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// MultiValue is an element of a multi-valued attribute such as emails, groups or members.
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// User is a SCIM user resource.
+type User struct {
+	Schemas    []string     `json:"schemas"`
+	ID         string       `json:"id,omitempty"`
+	ExternalID string       `json:"externalId,omitempty"`
+	UserName   string       `json:"userName"`
+	Name       *Name        `json:"name,omitempty"`
+	Emails     []MultiValue `json:"emails,omitempty"`
+	Active     *bool        `json:"active,omitempty"`
+	// Groups is read-only; membership is changed through Group resources.
+	Groups []MultiValue `json:"groups,omitempty"`
+	Meta   *Meta        `json:"meta,omitempty"`
+}
+
+// Group is a SCIM group resource.
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// ListResponse is the response to a query.
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+// PatchRequest is the body of a PATCH request.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single add, replace or remove operation.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Error is a SCIM error response.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// newUser converts a CloudConnexa user to a SCIM user. groupNames maps group IDs to names.
+func (s *Server) newUser(u cloudconnexa.User, groupNames map[string]string) User {
+	active := u.Status != cloudconnexa.UserStatusSuspended
+	user := User{
+		Schemas:  []string{SchemaUser},
+		ID:       u.ID,
+		UserName: u.Username,
+		Active:   &active,
+		Meta:     &Meta{ResourceType: "User", Location: s.location("Users", u.ID)},
+	}
+	if u.FirstName != "" || u.LastName != "" {
+		user.Name = &Name{
+			Formatted:  strings.TrimSpace(u.FirstName + " " + u.LastName),
+			GivenName:  u.FirstName,
+			FamilyName: u.LastName,
+		}
+	}
+	if u.Email != "" {
+		user.Emails = []MultiValue{{Value: u.Email, Type: "work", Primary: true}}
+	}
+	for _, id := range userGroupIDs(u) {
+		user.Groups = append(user.Groups, MultiValue{Value: id, Display: groupNames[id], Ref: s.location("Groups", id)})
+	}
+	return user
+}
+
+// newGroup converts a CloudConnexa user group and its members to a SCIM group.
+func (s *Server) newGroup(g cloudconnexa.UserGroup, users []cloudconnexa.User) Group {
+	group := Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          g.ID,
+		DisplayName: g.Name,
+		Meta:        &Meta{ResourceType: "Group", Location: s.location("Groups", g.ID)},
+	}
+	for _, u := range users {
+		if isMember(u, g.ID) {
+			group.Members = append(group.Members, MultiValue{Value: u.ID, Display: u.Username, Ref: s.location("Users", u.ID)})
+		}
+	}
+	return group
+}
+
+// primaryEmail returns the primary email of user, or its first email.
+func (u User) primaryEmail() string {
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+// userGroupIDs returns the primary and secondary group IDs of u.
+func userGroupIDs(u cloudconnexa.User) []string {
+	var ids []string
+	if u.GroupID != "" {
+		ids = append(ids, u.GroupID)
+	}
+	for _, id := range u.SecondaryGroupIDs {
+		if id != "" && id != u.GroupID {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func isMember(u cloudconnexa.User, groupID string) bool {
+	for _, id := range userGroupIDs(u) {
+		if id == groupID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,688 @@
+package scim
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+)
+
+// maxBodySize limits the size of request bodies.
+const maxBodySize = 1 << 20
+
+// UserStore manages CloudConnexa users. It is implemented by *cloudconnexa.UsersService.
+type UserStore interface {
+	List() ([]cloudconnexa.User, error)
+	Get(userID string) (*cloudconnexa.User, error)
+	Create(user cloudconnexa.User) (*cloudconnexa.User, error)
+	Update(user cloudconnexa.User) error
+	Delete(userID string) error
+	Suspend(userID string) error
+	Activate(userID string) error
+}
+
+// GroupStore manages CloudConnexa user groups. It is implemented by *cloudconnexa.UserGroupsService.
+type GroupStore interface {
+	List() ([]cloudconnexa.UserGroup, error)
+	GetByID(id string) (*cloudconnexa.UserGroup, error)
+	Create(userGroup *cloudconnexa.UserGroup) (*cloudconnexa.UserGroup, error)
+	Update(id string, userGroup *cloudconnexa.UserGroup) (*cloudconnexa.UserGroup, error)
+	Delete(id string) error
+}
+
+var (
+	_ UserStore  = (*cloudconnexa.UsersService)(nil)
+	_ GroupStore = (*cloudconnexa.UserGroupsService)(nil)
+)
+
+// Options configures a Server.
+type Options struct {
+	// Token is the bearer token identity providers must present. If empty,
+	// requests are not authenticated and the server must be protected otherwise.
+	Token string
+	// BaseURL is the public URL the server is mounted at, used for meta.location and $ref.
+	BaseURL string
+	// DefaultRole is the role of created users. Defaults to "MEMBER".
+	DefaultRole string
+	// AuthType is the authentication type of created users. If empty it is left to the API.
+	AuthType string
+	// DefaultGroupID is the user group of created users and of users removed
+	// from their primary group while not belonging to any other group.
+	DefaultGroupID string
+	// GroupTemplate holds the settings of created user groups; only the name
+	// is taken from the request.
+	GroupTemplate cloudconnexa.UserGroup
+	// MaxResults caps the number of resources returned by a query. Defaults to 200.
+	MaxResults int
+}
+
+// Server is a SCIM 2.0 service provider translating requests into user and
+// user group operations. Users are suspended and activated through the
+// active attribute, and group membership is mapped to GroupID and
+// SecondaryGroupIDs: the first group a user joins becomes its primary group.
+//
+// Server is an http.Handler serving /Users, /Groups and /ServiceProviderConfig
+// at its root; use http.StripPrefix to mount it below a path.
+type Server struct {
+	users  UserStore
+	groups GroupStore
+	opts   Options
+	mux    *http.ServeMux
+	// mu serializes writes, which read, modify and write users when memberships change.
+	mu sync.Mutex
+}
+
+// NewServer returns a Server backed by the given stores.
+func NewServer(users UserStore, groups GroupStore, opts Options) *Server {
+	if opts.DefaultRole == "" {
+		opts.DefaultRole = "MEMBER"
+	}
+	if opts.MaxResults <= 0 {
+		opts.MaxResults = 200
+	}
+	s := &Server{users: users, groups: groups, opts: opts, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /ServiceProviderConfig", s.handle(s.serviceProviderConfig))
+	s.mux.HandleFunc("GET /Users", s.handle(s.listUsers))
+	s.mux.HandleFunc("POST /Users", s.handle(s.createUser))
+	s.mux.HandleFunc("GET /Users/{id}", s.handle(s.getUser))
+	s.mux.HandleFunc("PUT /Users/{id}", s.handle(s.replaceUser))
+	s.mux.HandleFunc("PATCH /Users/{id}", s.handle(s.patchUser))
+	s.mux.HandleFunc("DELETE /Users/{id}", s.handle(s.deleteUser))
+	s.mux.HandleFunc("GET /Groups", s.handle(s.listGroups))
+	s.mux.HandleFunc("POST /Groups", s.handle(s.createGroup))
+	s.mux.HandleFunc("GET /Groups/{id}", s.handle(s.getGroup))
+	s.mux.HandleFunc("PUT /Groups/{id}", s.handle(s.replaceGroup))
+	s.mux.HandleFunc("PATCH /Groups/{id}", s.handle(s.patchGroup))
+	s.mux.HandleFunc("DELETE /Groups/{id}", s.handle(s.deleteGroup))
+	s.mux.HandleFunc("/", s.handle(func(*http.Request) (int, any, error) {
+		return 0, nil, &apiError{status: http.StatusNotFound, detail: "resource type not found"}
+	}))
+	return s
+}
+
+// NewClientServer returns a Server backed by the users and user groups of client.
+func NewClientServer(client *cloudconnexa.Client, opts Options) *Server {
+	return NewServer(client.Users, client.UserGroups, opts)
+}
+
+// ServeHTTP authenticates the request and dispatches it.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) != 1 {
+			writeError(w, &apiError{status: http.StatusUnauthorized, detail: "invalid bearer token"})
+			return
+		}
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	s.mux.ServeHTTP(w, r)
+}
+
+// apiError is an error reported to the client as a SCIM error response.
+type apiError struct {
+	status   int
+	scimType string
+	detail   string
+}
+
+func (e *apiError) Error() string {
+	return e.detail
+}
+
+func invalidValue(format string, args ...any) *apiError {
+	return &apiError{status: http.StatusBadRequest, scimType: "invalidValue", detail: fmt.Sprintf(format, args...)}
+}
+
+// toAPIError maps store errors to SCIM errors.
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+	var respErr *cloudconnexa.ErrClientResponse
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, cloudconnexa.ErrUserNotFound), errors.Is(err, cloudconnexa.ErrUserGroupNotFound):
+		return &apiError{status: http.StatusNotFound, detail: err.Error()}
+	case errors.Is(err, errInvalidFilter):
+		return &apiError{status: http.StatusBadRequest, scimType: "invalidFilter", detail: err.Error()}
+	case errors.As(err, &respErr):
+		switch status := respErr.StatusCode(); {
+		case status == http.StatusConflict:
+			return &apiError{status: status, scimType: "uniqueness", detail: respErr.Body()}
+		case status >= 400 && status < 500:
+			return &apiError{status: status, detail: respErr.Body()}
+		}
+		return &apiError{status: http.StatusBadGateway, detail: err.Error()}
+	default:
+		return &apiError{status: http.StatusInternalServerError, detail: err.Error()}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	if v != nil {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	writeJSON(w, err.status, Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(err.status),
+		ScimType: err.scimType,
+		Detail:   err.detail,
+	})
+}
+
+// handle adapts a handler returning a status, a response body and an error.
+func (s *Server) handle(fn func(r *http.Request) (int, any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, body, err := fn(r)
+		if err != nil {
+			writeError(w, toAPIError(err))
+			return
+		}
+		if status == http.StatusCreated {
+			if meta := resourceMeta(body); meta != nil && meta.Location != "" {
+				w.Header().Set("Location", meta.Location)
+			}
+		}
+		writeJSON(w, status, body)
+	}
+}
+
+func resourceMeta(v any) *Meta {
+	switch r := v.(type) {
+	case User:
+		return r.Meta
+	case Group:
+		return r.Meta
+	}
+	return nil
+}
+
+func (s *Server) location(resourceType, id string) string {
+	if s.opts.BaseURL == "" {
+		return ""
+	}
+	return strings.TrimRight(s.opts.BaseURL, "/") + "/" + resourceType + "/" + id
+}
+
+func decodeBody(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return &apiError{status: http.StatusBadRequest, scimType: "invalidSyntax", detail: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) serviceProviderConfig(*http.Request) (int, any, error) {
+	supported := func(v bool) map[string]bool { return map[string]bool{"supported": v} }
+	return http.StatusOK, map[string]any{
+		"schemas":        []string{SchemaServiceProviderConfig},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": s.opts.MaxResults},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with a static bearer token",
+		}},
+	}, nil
+}
+
+// query applies the filter, startIndex and count parameters of r to resources.
+func query[T any](r *http.Request, resources []T, maxResults int) (*ListResponse, error) {
+	params := r.URL.Query()
+	if f := params.Get("filter"); f != "" {
+		expr, err := parseFilter(f)
+		if err != nil {
+			return nil, err
+		}
+		var matched []T
+		for _, resource := range resources {
+			m, err := toMap(resource)
+			if err != nil {
+				return nil, err
+			}
+			if expr.match(m) {
+				matched = append(matched, resource)
+			}
+		}
+		resources = matched
+	}
+
+	startIndex, count := 1, maxResults
+	if v := params.Get("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, invalidValue("invalid startIndex %q", v)
+		}
+		startIndex = max(n, 1)
+	}
+	if v := params.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, invalidValue("invalid count %q", v)
+		}
+		count = min(max(n, 0), maxResults)
+	}
+
+	page := resources[min(startIndex-1, len(resources)):]
+	page = page[:min(count, len(page))]
+	if page == nil {
+		page = []T{}
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}, nil
+}
+
+func toMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	return m, json.Unmarshal(data, &m)
+}
+
+func (s *Server) groupNames() (map[string]string, error) {
+	groups, err := s.groups.List()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(groups))
+	for _, g := range groups {
+		names[g.ID] = g.Name
+	}
+	return names, nil
+}
+
+func (s *Server) listUsers(r *http.Request) (int, any, error) {
+	users, err := s.users.List()
+	if err != nil {
+		return 0, nil, err
+	}
+	names, err := s.groupNames()
+	if err != nil {
+		return 0, nil, err
+	}
+	resources := make([]User, 0, len(users))
+	for _, u := range users {
+		resources = append(resources, s.newUser(u, names))
+	}
+	response, err := query(r, resources, s.opts.MaxResults)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, response, nil
+}
+
+func (s *Server) getUser(r *http.Request) (int, any, error) {
+	user, err := s.users.Get(r.PathValue("id"))
+	if err != nil {
+		return 0, nil, err
+	}
+	names, err := s.groupNames()
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, s.newUser(*user, names), nil
+}
+
+func (s *Server) createUser(r *http.Request) (int, any, error) {
+	var in User
+	if err := decodeBody(r, &in); err != nil {
+		return 0, nil, err
+	}
+	if in.UserName == "" {
+		return 0, nil, invalidValue("userName is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users, err := s.users.List()
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Username, in.UserName) {
+			return 0, nil, &apiError{status: http.StatusConflict, scimType: "uniqueness", detail: fmt.Sprintf("user %q already exists", in.UserName)}
+		}
+	}
+
+	user := cloudconnexa.User{Role: s.opts.DefaultRole, AuthType: s.opts.AuthType, GroupID: s.opts.DefaultGroupID}
+	applyUser(&user, in)
+	created, err := s.users.Create(user)
+	if err != nil {
+		return 0, nil, err
+	}
+	if in.Active != nil && !*in.Active {
+		if err := s.users.Suspend(created.ID); err != nil {
+			return 0, nil, err
+		}
+		created.Status = cloudconnexa.UserStatusSuspended
+	}
+	names, err := s.groupNames()
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, s.newUser(*created, names), nil
+}
+
+func (s *Server) replaceUser(r *http.Request) (int, any, error) {
+	var in User
+	if err := decodeBody(r, &in); err != nil {
+		return 0, nil, err
+	}
+	if in.UserName == "" {
+		return 0, nil, invalidValue("userName is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, err := s.users.Get(r.PathValue("id"))
+	if err != nil {
+		return 0, nil, err
+	}
+	return s.saveUser(*current, in)
+}
+
+func (s *Server) patchUser(r *http.Request) (int, any, error) {
+	var patch PatchRequest
+	if err := decodeBody(r, &patch); err != nil {
+		return 0, nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, err := s.users.Get(r.PathValue("id"))
+	if err != nil {
+		return 0, nil, err
+	}
+	names, err := s.groupNames()
+	if err != nil {
+		return 0, nil, err
+	}
+	var patched User
+	if err := applyPatch(s.newUser(*current, names), patch, &patched); err != nil {
+		return 0, nil, err
+	}
+	if patched.UserName == "" {
+		return 0, nil, invalidValue("userName is required")
+	}
+	return s.saveUser(*current, patched)
+}
+
+// saveUser updates current to match desired and suspends or activates it as needed.
+func (s *Server) saveUser(current cloudconnexa.User, desired User) (int, any, error) {
+	updated := current
+	updated.Devices = nil
+	applyUser(&updated, desired)
+	if updated.Username != current.Username || updated.Email != current.Email ||
+		updated.FirstName != current.FirstName || updated.LastName != current.LastName {
+		if err := s.users.Update(updated); err != nil {
+			return 0, nil, err
+		}
+	}
+	if desired.Active != nil {
+		suspended := current.Status == cloudconnexa.UserStatusSuspended
+		switch {
+		case *desired.Active && suspended:
+			if err := s.users.Activate(current.ID); err != nil {
+				return 0, nil, err
+			}
+			updated.Status = cloudconnexa.UserStatusActive
+		case !*desired.Active && !suspended:
+			if err := s.users.Suspend(current.ID); err != nil {
+				return 0, nil, err
+			}
+			updated.Status = cloudconnexa.UserStatusSuspended
+		}
+	}
+	names, err := s.groupNames()
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, s.newUser(updated, names), nil
+}
+
+// applyUser copies the attributes of in that map to CloudConnexa user fields.
+func applyUser(u *cloudconnexa.User, in User) {
+	u.Username = in.UserName
+	u.Email = in.primaryEmail()
+	u.FirstName, u.LastName = "", ""
+	if in.Name != nil {
+		u.FirstName, u.LastName = in.Name.GivenName, in.Name.FamilyName
+	}
+}
+
+func (s *Server) deleteUser(r *http.Request) (int, any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.users.Delete(r.PathValue("id")); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) listGroups(r *http.Request) (int, any, error) {
+	groups, err := s.groups.List()
+	if err != nil {
+		return 0, nil, err
+	}
+	users, err := s.users.List()
+	if err != nil {
+		return 0, nil, err
+	}
+	resources := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		resources = append(resources, s.newGroup(g, users))
+	}
+	response, err := query(r, resources, s.opts.MaxResults)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, response, nil
+}
+
+func (s *Server) getGroup(r *http.Request) (int, any, error) {
+	group, err := s.groups.GetByID(r.PathValue("id"))
+	if err != nil {
+		return 0, nil, err
+	}
+	users, err := s.users.List()
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, s.newGroup(*group, users), nil
+}
+
+func (s *Server) createGroup(r *http.Request) (int, any, error) {
+	var in Group
+	if err := decodeBody(r, &in); err != nil {
+		return 0, nil, err
+	}
+	if in.DisplayName == "" {
+		return 0, nil, invalidValue("displayName is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups, err := s.groups.List()
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, g := range groups {
+		if strings.EqualFold(g.Name, in.DisplayName) {
+			return 0, nil, &apiError{status: http.StatusConflict, scimType: "uniqueness", detail: fmt.Sprintf("group %q already exists", in.DisplayName)}
+		}
+	}
+	users, err := s.users.List()
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := checkMembers(users, in.Members); err != nil {
+		return 0, nil, err
+	}
+
+	group := s.opts.GroupTemplate
+	group.ID = ""
+	group.Name = in.DisplayName
+	created, err := s.groups.Create(&group)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := s.setMembers(created.ID, users, in.Members); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, s.newGroup(*created, users), nil
+}
+
+func (s *Server) replaceGroup(r *http.Request) (int, any, error) {
+	var in Group
+	if err := decodeBody(r, &in); err != nil {
+		return 0, nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, err := s.groups.GetByID(r.PathValue("id"))
+	if err != nil {
+		return 0, nil, err
+	}
+	users, err := s.users.List()
+	if err != nil {
+		return 0, nil, err
+	}
+	return s.saveGroup(*current, users, in)
+}
+
+func (s *Server) patchGroup(r *http.Request) (int, any, error) {
+	var patch PatchRequest
+	if err := decodeBody(r, &patch); err != nil {
+		return 0, nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, err := s.groups.GetByID(r.PathValue("id"))
+	if err != nil {
+		return 0, nil, err
+	}
+	users, err := s.users.List()
+	if err != nil {
+		return 0, nil, err
+	}
+	var patched Group
+	if err := applyPatch(s.newGroup(*current, users), patch, &patched); err != nil {
+		return 0, nil, err
+	}
+	return s.saveGroup(*current, users, patched)
+}
+
+// saveGroup renames current and changes its members to match desired.
+func (s *Server) saveGroup(current cloudconnexa.UserGroup, users []cloudconnexa.User, desired Group) (int, any, error) {
+	if desired.DisplayName == "" {
+		return 0, nil, invalidValue("displayName is required")
+	}
+	if err := checkMembers(users, desired.Members); err != nil {
+		return 0, nil, err
+	}
+	if desired.DisplayName != current.Name {
+		renamed := current
+		renamed.Name = desired.DisplayName
+		updated, err := s.groups.Update(current.ID, &renamed)
+		if err != nil {
+			return 0, nil, err
+		}
+		current = *updated
+	}
+	if err := s.setMembers(current.ID, users, desired.Members); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, s.newGroup(current, users), nil
+}
+
+func (s *Server) deleteGroup(r *http.Request) (int, any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.groups.Delete(r.PathValue("id")); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+// checkMembers returns an error if a member does not reference a known user.
+func checkMembers(users []cloudconnexa.User, members []MultiValue) error {
+	for _, m := range members {
+		if !slices.ContainsFunc(users, func(u cloudconnexa.User) bool { return u.ID == m.Value }) {
+			return invalidValue("member %q is not a known user", m.Value)
+		}
+	}
+	return nil
+}
+
+// setMembers updates the users whose membership in groupID differs from members.
+// users is updated in place.
+func (s *Server) setMembers(groupID string, users []cloudconnexa.User, members []MultiValue) error {
+	want := make(map[string]bool, len(members))
+	for _, m := range members {
+		want[m.Value] = true
+	}
+	for i := range users {
+		u := &users[i]
+		if want[u.ID] == isMember(*u, groupID) {
+			continue
+		}
+		updated := *u
+		updated.Devices = nil
+		updated.SecondaryGroupIDs = slices.Clone(u.SecondaryGroupIDs)
+		if want[u.ID] {
+			s.addMembership(&updated, groupID)
+		} else {
+			s.removeMembership(&updated, groupID)
+		}
+		if err := s.users.Update(updated); err != nil {
+			return err
+		}
+		u.GroupID, u.SecondaryGroupIDs = updated.GroupID, updated.SecondaryGroupIDs
+	}
+	return nil
+}
+
+// addMembership makes groupID the primary group of u if it has none, or a secondary group otherwise.
+func (s *Server) addMembership(u *cloudconnexa.User, groupID string) {
+	if u.GroupID == "" {
+		u.GroupID = groupID
+		return
+	}
+	u.SecondaryGroupIDs = append(u.SecondaryGroupIDs, groupID)
+}
+
+// removeMembership removes groupID from u. A removed primary group is replaced
+// by the first secondary group, or by the default group.
+func (s *Server) removeMembership(u *cloudconnexa.User, groupID string) {
+	u.SecondaryGroupIDs = slices.DeleteFunc(u.SecondaryGroupIDs, func(id string) bool { return id == groupID })
+	if u.GroupID != groupID {
+		return
+	}
+	switch {
+	case len(u.SecondaryGroupIDs) > 0:
+		u.GroupID, u.SecondaryGroupIDs = u.SecondaryGroupIDs[0], u.SecondaryGroupIDs[1:]
+	case s.opts.DefaultGroupID != groupID:
+		u.GroupID = s.opts.DefaultGroupID
+	default:
+		u.GroupID = ""
+	}
+}
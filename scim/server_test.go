@@ -0,0 +1,389 @@
+package scim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openvpn/cloudconnexa-go-client/v2/cloudconnexa"
+	"golang.org/x/time/rate"
+)
+
+// fakeStore is an in-memory UserStore and GroupStore.
+type fakeStore struct {
+	mu     sync.Mutex
+	users  []cloudconnexa.User
+	groups []cloudconnexa.UserGroup
+	nextID int
+}
+
+type fakeGroups struct{ *fakeStore }
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		groups: []cloudconnexa.UserGroup{{ID: "ug-default", Name: "Default"}, {ID: "ug-eng", Name: "Engineering"}},
+		users: []cloudconnexa.User{
+			{ID: "u-alice", Username: "alice", Email: "alice@example.com", FirstName: "Alice", LastName: "Smith", GroupID: "ug-eng", Status: cloudconnexa.UserStatusActive},
+			{ID: "u-bob", Username: "bob", GroupID: "ug-default", Status: cloudconnexa.UserStatusActive},
+		},
+	}
+}
+
+func (f *fakeStore) List() ([]cloudconnexa.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.users), nil
+}
+
+func (f *fakeStore) Get(id string) (*cloudconnexa.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+	return nil, cloudconnexa.ErrUserNotFound
+}
+
+func (f *fakeStore) Create(u cloudconnexa.User) (*cloudconnexa.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	u.ID = fmt.Sprintf("u-%d", f.nextID)
+	u.Status = cloudconnexa.UserStatusActive
+	f.users = append(f.users, u)
+	return &u, nil
+}
+
+func (f *fakeStore) Update(u cloudconnexa.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.users {
+		if f.users[i].ID == u.ID {
+			u.Status = f.users[i].Status
+			f.users[i] = u
+			return nil
+		}
+	}
+	return cloudconnexa.ErrUserNotFound
+}
+
+func (f *fakeStore) setStatus(id, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.users {
+		if f.users[i].ID == id {
+			f.users[i].Status = status
+			return nil
+		}
+	}
+	return cloudconnexa.ErrUserNotFound
+}
+
+func (f *fakeStore) Suspend(id string) error {
+	return f.setStatus(id, cloudconnexa.UserStatusSuspended)
+}
+
+func (f *fakeStore) Activate(id string) error {
+	return f.setStatus(id, cloudconnexa.UserStatusActive)
+}
+
+func (f *fakeStore) Delete(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.users = slices.DeleteFunc(f.users, func(u cloudconnexa.User) bool { return u.ID == id })
+	return nil
+}
+
+func (g fakeGroups) List() ([]cloudconnexa.UserGroup, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.groups), nil
+}
+
+func (g fakeGroups) GetByID(id string) (*cloudconnexa.UserGroup, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, ug := range g.groups {
+		if ug.ID == id {
+			return &ug, nil
+		}
+	}
+	return nil, cloudconnexa.ErrUserGroupNotFound
+}
+
+func (g fakeGroups) Create(ug *cloudconnexa.UserGroup) (*cloudconnexa.UserGroup, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nextID++
+	created := *ug
+	created.ID = fmt.Sprintf("ug-%d", g.nextID)
+	g.groups = append(g.groups, created)
+	return &created, nil
+}
+
+func (g fakeGroups) Update(id string, ug *cloudconnexa.UserGroup) (*cloudconnexa.UserGroup, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i := range g.groups {
+		if g.groups[i].ID == id {
+			g.groups[i] = *ug
+			return ug, nil
+		}
+	}
+	return nil, cloudconnexa.ErrUserGroupNotFound
+}
+
+func (g fakeGroups) Delete(id string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.groups = slices.DeleteFunc(g.groups, func(ug cloudconnexa.UserGroup) bool { return ug.ID == id })
+	return nil
+}
+
+func (f *fakeStore) user(id string) cloudconnexa.User {
+	u, _ := f.Get(id)
+	if u == nil {
+		return cloudconnexa.User{}
+	}
+	return *u
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *fakeStore) {
+	t.Helper()
+	store := newFakeStore()
+	server := httptest.NewServer(NewServer(store, fakeGroups{store}, Options{
+		Token:          "secret",
+		BaseURL:        "https://scim.example.com/v2",
+		DefaultGroupID: "ug-default",
+	}))
+	t.Cleanup(server.Close)
+	return server, store
+}
+
+func do(t *testing.T, server *httptest.Server, method, path, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/scim+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: invalid response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServer_Authentication(t *testing.T) {
+	server, _ := newTestServer(t)
+	resp, err := http.Get(server.URL + "/Users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var scimErr Error
+	_ = json.NewDecoder(resp.Body).Decode(&scimErr)
+	if resp.StatusCode != http.StatusUnauthorized || scimErr.Status != "401" {
+		t.Errorf("Expected 401, got %d %+v", resp.StatusCode, scimErr)
+	}
+}
+
+func TestServer_ListUsers_Filter(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	var list struct {
+		TotalResults int    `json:"totalResults"`
+		Resources    []User `json:"Resources"`
+	}
+	status := do(t, server, http.MethodGet, "/Users?filter="+url.QueryEscape(`userName eq "ALICE"`), "", &list)
+	if status != http.StatusOK || list.TotalResults != 1 || list.Resources[0].ID != "u-alice" {
+		t.Fatalf("Unexpected response %d %+v", status, list)
+	}
+	alice := list.Resources[0]
+	if alice.Name.GivenName != "Alice" || alice.Emails[0].Value != "alice@example.com" || !*alice.Active ||
+		alice.Groups[0].Display != "Engineering" || alice.Meta.Location != "https://scim.example.com/v2/Users/u-alice" {
+		t.Errorf("Unexpected user %+v", alice)
+	}
+
+	do(t, server, http.MethodGet, "/Users?count=1&startIndex=2", "", &list)
+	if list.TotalResults != 2 || len(list.Resources) != 1 || list.Resources[0].ID != "u-bob" {
+		t.Errorf("Unexpected page %+v", list)
+	}
+
+	var scimErr Error
+	if status := do(t, server, http.MethodGet, "/Users?filter="+url.QueryEscape(`userName xx "a"`), "", &scimErr); status != http.StatusBadRequest || scimErr.ScimType != "invalidFilter" {
+		t.Errorf("Expected invalidFilter, got %d %+v", status, scimErr)
+	}
+}
+
+func TestServer_UserLifecycle(t *testing.T) {
+	server, store := newTestServer(t)
+
+	var created User
+	status := do(t, server, http.MethodPost, "/Users", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "carol@example.com",
+		"name": {"givenName": "Carol", "familyName": "Jones"},
+		"emails": [{"value": "carol@home.example"}, {"value": "carol@example.com", "primary": true}],
+		"active": true
+	}`, &created)
+	if status != http.StatusCreated || created.ID == "" {
+		t.Fatalf("Unexpected create response %d %+v", status, created)
+	}
+	user := store.user(created.ID)
+	if user.Email != "carol@example.com" || user.Role != "MEMBER" || user.GroupID != "ug-default" {
+		t.Errorf("Unexpected created user %+v", user)
+	}
+
+	var scimErr Error
+	if status := do(t, server, http.MethodPost, "/Users", `{"userName": "Carol@example.com"}`, &scimErr); status != http.StatusConflict || scimErr.ScimType != "uniqueness" {
+		t.Errorf("Expected uniqueness conflict, got %d %+v", status, scimErr)
+	}
+
+	// Azure AD style deactivation.
+	var patched User
+	status = do(t, server, http.MethodPatch, "/Users/"+created.ID, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "Replace", "path": "active", "value": "False"},
+			{"op": "replace", "path": "name.familyName", "value": "Brown"}
+		]
+	}`, &patched)
+	if status != http.StatusOK || *patched.Active || patched.Name.FamilyName != "Brown" {
+		t.Fatalf("Unexpected patch response %d %+v", status, patched)
+	}
+	if user := store.user(created.ID); user.Status != cloudconnexa.UserStatusSuspended || user.LastName != "Brown" {
+		t.Errorf("Expected suspended user Brown, got %+v", user)
+	}
+
+	// Okta style reactivation without a path.
+	do(t, server, http.MethodPatch, "/Users/"+created.ID, `{"Operations": [{"op": "replace", "value": {"active": true}}]}`, &patched)
+	if user := store.user(created.ID); user.Status != cloudconnexa.UserStatusActive {
+		t.Errorf("Expected active user, got %+v", user)
+	}
+
+	status = do(t, server, http.MethodPatch, "/Users/"+created.ID, `{"Operations": [
+		{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "carol@corp.example"}
+	]}`, &patched)
+	if status != http.StatusOK || store.user(created.ID).Email != "carol@corp.example" {
+		t.Errorf("Expected email replacement, got %d %+v", status, store.user(created.ID))
+	}
+
+	var replaced User
+	status = do(t, server, http.MethodPut, "/Users/"+created.ID, `{"userName": "carol@example.com", "active": false}`, &replaced)
+	if status != http.StatusOK || store.user(created.ID).FirstName != "" || store.user(created.ID).Status != cloudconnexa.UserStatusSuspended {
+		t.Errorf("Expected replaced user, got %d %+v", status, store.user(created.ID))
+	}
+
+	if status := do(t, server, http.MethodDelete, "/Users/"+created.ID, "", nil); status != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", status)
+	}
+	if status := do(t, server, http.MethodGet, "/Users/"+created.ID, "", &scimErr); status != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", status)
+	}
+}
+
+func TestServer_GroupMembership(t *testing.T) {
+	server, store := newTestServer(t)
+
+	var group Group
+	status := do(t, server, http.MethodPost, "/Groups", `{"displayName": "Ops", "members": [{"value": "u-alice"}]}`, &group)
+	if status != http.StatusCreated || len(group.Members) != 1 {
+		t.Fatalf("Unexpected create response %d %+v", status, group)
+	}
+	if alice := store.user("u-alice"); alice.GroupID != "ug-eng" || !slices.Equal(alice.SecondaryGroupIDs, []string{group.ID}) {
+		t.Errorf("Expected Ops as secondary group of alice, got %+v", alice)
+	}
+
+	status = do(t, server, http.MethodPatch, "/Groups/"+group.ID, `{"Operations": [
+		{"op": "add", "path": "members", "value": [{"value": "u-bob"}]},
+		{"op": "replace", "path": "displayName", "value": "Operations"}
+	]}`, &group)
+	if status != http.StatusOK || group.DisplayName != "Operations" || len(group.Members) != 2 {
+		t.Fatalf("Unexpected patch response %d %+v", status, group)
+	}
+
+	var eng Group
+	status = do(t, server, http.MethodPatch, "/Groups/ug-eng", `{"Operations": [
+		{"op": "remove", "path": "members[value eq \"u-alice\"]"}
+	]}`, &eng)
+	if status != http.StatusOK || len(eng.Members) != 0 {
+		t.Fatalf("Unexpected patch response %d %+v", status, eng)
+	}
+	if alice := store.user("u-alice"); alice.GroupID == "ug-eng" || len(alice.SecondaryGroupIDs) != 0 {
+		t.Errorf("Expected Ops to become the primary group of alice, got %+v", alice)
+	}
+
+	var list struct {
+		Resources []Group `json:"Resources"`
+	}
+	do(t, server, http.MethodGet, "/Groups?filter="+url.QueryEscape(`members.value eq "u-bob"`), "", &list)
+	if len(list.Resources) != 2 {
+		t.Errorf("Expected bob in two groups, got %+v", list.Resources)
+	}
+
+	var scimErr Error
+	status = do(t, server, http.MethodPut, "/Groups/"+group.ID, `{"displayName": "Engineering", "members": [{"value": "u-nobody"}]}`, &scimErr)
+	if status != http.StatusBadRequest || scimErr.ScimType != "invalidValue" {
+		t.Errorf("Expected invalidValue for unknown member, got %d %+v", status, scimErr)
+	}
+}
+
+func TestNewClientServer_FakeAPI(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/api/v1/oauth/token":
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/users":
+			_ = json.NewEncoder(w).Encode(cloudconnexa.UserPageResponse{TotalPages: 1})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/user-groups":
+			_ = json.NewEncoder(w).Encode(cloudconnexa.UserGroupPageResponse{TotalPages: 1})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/users":
+			var u cloudconnexa.User
+			_ = json.NewDecoder(r.Body).Decode(&u)
+			u.ID = "u-new"
+			_ = json.NewEncoder(w).Encode(u)
+		}
+	}))
+	defer api.Close()
+
+	client, err := cloudconnexa.NewClientWithOptions(api.URL, "id", "secret", &cloudconnexa.ClientOptions{AllowInsecureHTTP: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.ReadRateLimiter = rate.NewLimiter(rate.Every(1), 5)
+	client.UpdateRateLimiter = rate.NewLimiter(rate.Every(1), 5)
+	handler := NewClientServer(client, Options{})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/Users", bytes.NewBufferString(`{"userName": "dave", "active": false}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Contains(requests, "PUT /api/v1/users/u-new/suspend") {
+		t.Errorf("Expected the new user to be suspended, got %v", requests)
+	}
+}